@@ -169,11 +169,16 @@ func loadRecords(path string) ([]match.MoveRecord, error) {
 
 	records := make([]match.MoveRecord, 0)
 	scanner := bufio.NewScanner(file)
+	line := 0
 	for scanner.Scan() {
+		line++
 		var record match.MoveRecord
 		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
 			return nil, err
 		}
+		if err := validateRecordPositions(record); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
 		records = append(records, record)
 	}
 	if err := scanner.Err(); err != nil {
@@ -182,6 +187,18 @@ func loadRecords(path string) ([]match.MoveRecord, error) {
 	return records, nil
 }
 
+// validateRecordPositions rejects records whose FENs Stockfish would be fed
+// blindly, so a corrupt record fails loudly instead of producing a bogus swing.
+func validateRecordPositions(record match.MoveRecord) error {
+	if _, err := board.NewValidPositionFromFEN(record.FENBefore); err != nil {
+		return fmt.Errorf("fen_before: %w", err)
+	}
+	if _, err := board.NewValidPositionFromFEN(record.FENAfter); err != nil {
+		return fmt.Errorf("fen_after: %w", err)
+	}
+	return nil
+}
+
 func newStockfishClient(path string) (*stockfishClient, error) {
 	cmd := exec.Command(path)
 	stdout, err := cmd.StdoutPipe()
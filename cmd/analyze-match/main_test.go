@@ -1,6 +1,8 @@
 package main
 
 import (
+	board "chessV2/internal/board"
+	"chessV2/internal/match"
 	"strings"
 	"testing"
 
@@ -56,3 +58,16 @@ func TestNonKingMaterial(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 0, material)
 }
+
+func TestValidateRecordPositions(t *testing.T) {
+	record := match.MoveRecord{
+		FENBefore: board.FenStartPos,
+		FENAfter:  "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+	}
+	assert.NoError(t, validateRecordPositions(record))
+
+	record.FENAfter = "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e6 0 1"
+	err := validateRecordPositions(record)
+	assert.ErrorIs(t, err, board.ErrInvalidEnPassant)
+	assert.Contains(t, err.Error(), "fen_after")
+}
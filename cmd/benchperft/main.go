@@ -29,6 +29,7 @@ func main() {
 	noPerftTricks := flag.Bool("no-perft-tricks", false, "disable perft-only tricks such as bulk counting and transposition table use")
 	mode := flag.String("mode", "hot", "benchmark mode: hot excludes FEN/engine setup from the timed section, cold includes it")
 	warmup := flag.Int("warmup", 1, "number of warmup perft runs before timing in hot mode")
+	threads := flag.Int("threads", 1, "number of goroutines the perft tree is split across")
 	flag.Parse()
 
 	if *mode != "hot" && *mode != "cold" {
@@ -58,16 +59,17 @@ func main() {
 
 	switch *mode {
 	case "hot":
-		pos, err := board.NewPositionFromFEN(*fen)
+		pos, err := board.NewValidPositionFromFEN(*fen)
 		if err != nil {
 			log.Fatalf("parse fen: %v", err)
 		}
 
 		e := engine.NewEngine()
 		e.SetPerftTricks(!*noPerftTricks)
+		e.SetPerftThreads(*threads)
 
 		for i := 0; i < *warmup; i++ {
-			warmPos, err := board.NewPositionFromFEN(*fen)
+			warmPos, err := board.NewValidPositionFromFEN(*fen)
 			if err != nil {
 				log.Fatalf("parse fen for warmup: %v", err)
 			}
@@ -91,13 +93,14 @@ func main() {
 			}
 		}
 		start = time.Now()
-		pos, err := board.NewPositionFromFEN(*fen)
+		pos, err := board.NewValidPositionFromFEN(*fen)
 		if err != nil {
 			log.Fatalf("parse fen: %v", err)
 		}
 
 		e := engine.NewEngine()
 		e.SetPerftTricks(!*noPerftTricks)
+		e.SetPerftThreads(*threads)
 		nodes = runPerft(pos, e, *depth)
 		if profileFile != nil {
 			pprof.StopCPUProfile()
@@ -111,6 +114,7 @@ func main() {
 	fmt.Printf("Mode: %s\n", *mode)
 	fmt.Printf("Warmup: %d\n", *warmup)
 	fmt.Printf("Perft tricks: %t\n", !*noPerftTricks)
+	fmt.Printf("Threads: %d\n", *threads)
 	fmt.Printf("Nodes: %d\n", nodes)
 	fmt.Printf("Elapsed: %s\n", elapsed)
 	if *cpuProfile != "" {
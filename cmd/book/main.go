@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bufio"
+	board "chessV2/internal/board"
+	"chessV2/internal/book"
+	"chessV2/internal/engine"
+	"chessV2/internal/match"
+	"chessV2/internal/movegen"
+	"chessV2/internal/pgn"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const usage = `usage:
+  book build -out <book.bin> [-max-ply n] [-min-games n] [-min-score x] <games.pgn|records.jsonl>...
+  book query -book <book.bin> [-fen <fen>]`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "build":
+		err = runBuild(os.Args[2:], os.Stdout)
+	case "query":
+		err = runQuery(os.Args[2:], os.Stdout)
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// runBuild aggregates the games of every input file and writes the moves
+// that pass the filters as a Polyglot book.
+func runBuild(args []string, out io.Writer) error {
+	var (
+		outPath  string
+		maxPly   int
+		minGames int
+		minScore float64
+	)
+
+	flags := flag.NewFlagSet("build", flag.ContinueOnError)
+	flags.StringVar(&outPath, "out", "", "Path of the Polyglot book to write")
+	flags.IntVar(&maxPly, "max-ply", 20, "Number of plies recorded from the start of each game")
+	flags.IntVar(&minGames, "min-games", 1, "Minimum number of games a move must appear in")
+	flags.Float64Var(&minScore, "min-score", 0, "Minimum score of a move for the side playing it, from 0 to 1")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if outPath == "" {
+		return fmt.Errorf("missing required -out")
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("no input files")
+	}
+
+	e := engine.NewEngine()
+	builder := book.NewBuilder(maxPly)
+	games := 0
+	for _, path := range flags.Args() {
+		var (
+			count int
+			err   error
+		)
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".pgn":
+			count, err = addPGNFile(builder, e, path)
+		case ".jsonl", ".json":
+			count, err = addRecordFile(builder, e, path)
+		default:
+			err = fmt.Errorf("%s: unknown input type, expected .pgn or .jsonl", path)
+		}
+		if err != nil {
+			return err
+		}
+		games += count
+	}
+
+	entries := builder.Entries(minGames, minScore)
+	file, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	if err := book.Write(file, entries); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "games %d entries %d written to %s\n", games, len(entries), outPath)
+	return nil
+}
+
+func addPGNFile(builder *book.Builder, e *engine.Engine, path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	games, err := pgn.NewReader(file, e).ReadAll()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for _, game := range games {
+		start, err := board.NewValidPositionFromFEN(game.StartFEN())
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", path, err)
+		}
+		moves := make([]board.Move, 0, len(game.Moves))
+		for _, move := range game.Moves {
+			moves = append(moves, move.Move)
+		}
+		builder.AddGame(start, moves, pgnResult(game.Result))
+	}
+	return len(games), nil
+}
+
+func pgnResult(result string) book.GameResult {
+	switch result {
+	case pgn.ResultWhiteWins:
+		return book.ResultWhiteWins
+	case pgn.ResultBlackWins:
+		return book.ResultBlackWins
+	case pgn.ResultDraw:
+		return book.ResultDraw
+	}
+	return book.ResultUnknown
+}
+
+// addRecordFile replays the games of a match JSONL file. Records carry no
+// result, so it is read from the final position: checkmate wins, stalemate
+// and the automatic draws are draws, anything else is unknown.
+func addRecordFile(builder *book.Builder, e *engine.Engine, path string) (int, error) {
+	records, err := loadRecords(path)
+	if err != nil {
+		return 0, err
+	}
+
+	byGame := make(map[int][]match.MoveRecord)
+	order := make([]int, 0)
+	for _, record := range records {
+		if _, ok := byGame[record.GameIndex]; !ok {
+			order = append(order, record.GameIndex)
+		}
+		byGame[record.GameIndex] = append(byGame[record.GameIndex], record)
+	}
+
+	for _, gameIndex := range order {
+		gameRecords := byGame[gameIndex]
+		sort.SliceStable(gameRecords, func(i, j int) bool {
+			return gameRecords[i].Ply < gameRecords[j].Ply
+		})
+
+		start, moves, result, err := replayRecords(e, gameRecords)
+		if err != nil {
+			return 0, fmt.Errorf("%s: game %d: %w", path, gameIndex, err)
+		}
+		builder.AddGame(start, moves, result)
+	}
+	return len(order), nil
+}
+
+func loadRecords(path string) ([]match.MoveRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records := make([]match.MoveRecord, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var record match.MoveRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// replayRecords plays the moves of one game from the position before its
+// first record and checks that every record starts where the previous one
+// ended.
+func replayRecords(e *engine.Engine, records []match.MoveRecord) (*board.Position, []board.Move, book.GameResult, error) {
+	start, err := board.NewValidPositionFromFEN(records[0].FENBefore)
+	if err != nil {
+		return nil, nil, book.ResultUnknown, err
+	}
+
+	pos := start.Clone()
+	keys := []uint64{pos.ZobristKey()}
+	moves := make([]board.Move, 0, len(records))
+	for _, record := range records {
+		if record.FENBefore != pos.FEN() {
+			return nil, nil, book.ResultUnknown, fmt.Errorf("ply %d: fen_before does not follow the previous move", record.Ply)
+		}
+		move, err := e.FindMoveByUCI(pos, record.Move)
+		if err != nil {
+			return nil, nil, book.ResultUnknown, fmt.Errorf("ply %d: %w", record.Ply, err)
+		}
+		e.ApplyMove(pos, move)
+		moves = append(moves, move)
+		keys = append(keys, pos.ZobristKey())
+	}
+
+	return start, moves, finalResult(e, pos, keys), nil
+}
+
+func finalResult(e *engine.Engine, pos *board.Position, keys []uint64) book.GameResult {
+	if len(e.LegalMoves(pos)) == 0 {
+		if !movegen.IsKingInCheck(pos, pos.ActiveColor()) {
+			return book.ResultDraw
+		}
+		if pos.ActiveColor() == board.White {
+			return book.ResultBlackWins
+		}
+		return book.ResultWhiteWins
+	}
+	if pos.DrawReason(keys) != board.NoDraw {
+		return book.ResultDraw
+	}
+	return book.ResultUnknown
+}
+
+// runQuery prints the book moves of a position with their weights and their
+// share of the total weight.
+func runQuery(args []string, out io.Writer) error {
+	var (
+		bookPath string
+		fen      string
+	)
+
+	flags := flag.NewFlagSet("query", flag.ContinueOnError)
+	flags.StringVar(&bookPath, "book", "", "Path of the Polyglot book to read")
+	flags.StringVar(&fen, "fen", board.FenStartPos, "Position to look up")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if bookPath == "" {
+		return fmt.Errorf("missing required -book")
+	}
+
+	b, err := book.Load(bookPath)
+	if err != nil {
+		return err
+	}
+	pos, err := board.NewValidPositionFromFEN(fen)
+	if err != nil {
+		return err
+	}
+
+	e := engine.NewEngine()
+	candidates := b.Moves(pos, e.LegalMoves(pos))
+	if len(candidates) == 0 {
+		return errors.New("position not in book")
+	}
+
+	total := 0
+	for _, candidate := range candidates {
+		total += int(candidate.Weight)
+	}
+	fmt.Fprintf(out, "key %016x\n", pos.PolyglotKey())
+	for _, candidate := range candidates {
+		share := 0.0
+		if total > 0 {
+			share = 100 * float64(candidate.Weight) / float64(total)
+		}
+		fmt.Fprintf(out, "%-6s %-7s weight %5d %5.1f%%\n", candidate.Move.UCI(), e.MoveSAN(pos, candidate.Move), candidate.Weight, share)
+	}
+	return nil
+}
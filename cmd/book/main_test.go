@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	board "chessV2/internal/board"
+	"chessV2/internal/book"
+	"chessV2/internal/engine"
+	"chessV2/internal/match"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPGN = `[Event "a"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 Nc6 1-0
+
+[Event "b"]
+[Result "1/2-1/2"]
+
+1. e4 c5 1/2-1/2
+
+[Event "c"]
+[Result "0-1"]
+
+1. d4 d5 0-1
+`
+
+// writeRecords writes one match record per move of each game, numbered the
+// way the match runner numbers them.
+func writeRecords(t *testing.T, path string, games ...[]string) {
+	t.Helper()
+	e := engine.NewEngine()
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for gameIndex, moves := range games {
+		pos, err := board.NewPositionFromFEN(board.FenStartPos)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for ply, move := range moves {
+			fenBefore := pos.FEN()
+			if err := e.ApplyUCIMove(pos, move); err != nil {
+				t.Fatal(err)
+			}
+			assert.NoError(t, enc.Encode(match.MoveRecord{
+				GameIndex: gameIndex + 1,
+				Ply:       ply + 1,
+				Move:      move,
+				FENBefore: fenBefore,
+				FENAfter:  pos.FEN(),
+			}))
+		}
+	}
+	assert.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+}
+
+func TestRunBuildAndQuery(t *testing.T) {
+	dir := t.TempDir()
+	pgnPath := filepath.Join(dir, "games.pgn")
+	recordsPath := filepath.Join(dir, "records.jsonl")
+	bookPath := filepath.Join(dir, "book.bin")
+	assert.NoError(t, os.WriteFile(pgnPath, []byte(testPGN), 0o644))
+	writeRecords(t, recordsPath,
+		[]string{"f2f3", "e7e5", "g2g4", "d8h4"},
+		[]string{"e2e4", "e7e5"},
+	)
+
+	var out bytes.Buffer
+	err := runBuild([]string{"-out", bookPath, "-max-ply", "2", pgnPath, recordsPath}, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, "games 5 entries 7 written to "+bookPath+"\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, runQuery([]string{"-book", bookPath}, &out))
+	assert.Equal(t, strings.Join([]string{
+		"key 463b96181691fc9c",
+		"e2e4   e4      weight     4 100.0%",
+		"d2d4   d4      weight     0   0.0%",
+		"f2f3   f3      weight     0   0.0%",
+		"",
+	}, "\n"), out.String())
+
+	out.Reset()
+	err = runQuery([]string{"-book", bookPath, "-fen", "rnbqkbnr/pppppppp/8/8/8/5P2/PPPPP1PP/RNBQKBNR b KQkq - 0 1"}, &out)
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "e7e5   e5      weight     2 100.0%")
+
+	outOfBook := "rnbqkbnr/pppppppp/8/8/5P2/8/PPPPP1PP/RNBQKBNR b KQkq - 0 1"
+	err = runQuery([]string{"-book", bookPath, "-fen", outOfBook}, &out)
+	assert.EqualError(t, err, "position not in book")
+}
+
+func TestRunBuildFilters(t *testing.T) {
+	dir := t.TempDir()
+	pgnPath := filepath.Join(dir, "games.pgn")
+	bookPath := filepath.Join(dir, "book.bin")
+	assert.NoError(t, os.WriteFile(pgnPath, []byte(testPGN), 0o644))
+
+	var out bytes.Buffer
+	err := runBuild([]string{"-out", bookPath, "-max-ply", "4", "-min-games", "2", "-min-score", "0.6", pgnPath}, &out)
+	assert.NoError(t, err)
+
+	b, err := book.Load(bookPath)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, b.Len())
+
+	pos, err := board.NewPositionFromFEN(board.FenStartPos)
+	assert.NoError(t, err)
+	candidates := b.Moves(pos, engine.NewEngine().LegalMoves(pos))
+	assert.Len(t, candidates, 1)
+	assert.Equal(t, "e2e4", candidates[0].Move.UCI())
+}
+
+func TestRunBuildRejectsBrokenRecords(t *testing.T) {
+	dir := t.TempDir()
+	recordsPath := filepath.Join(dir, "records.jsonl")
+	record, err := json.Marshal(match.MoveRecord{GameIndex: 1, Ply: 1, Move: "e2e5", FENBefore: board.FenStartPos})
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(recordsPath, append(record, '\n'), 0o644))
+
+	err = runBuild([]string{"-out", filepath.Join(dir, "book.bin"), recordsPath}, &bytes.Buffer{})
+	assert.ErrorContains(t, err, "game 1: ply 1: illegal move: e2e5")
+
+	err = runBuild([]string{"-out", filepath.Join(dir, "book.bin"), filepath.Join(dir, "games.txt")}, &bytes.Buffer{})
+	assert.ErrorContains(t, err, "unknown input type")
+}
+
+func TestFinalResult(t *testing.T) {
+	data := map[string]struct {
+		fen      string
+		expected book.GameResult
+	}{
+		"white checkmated": {
+			fen:      "rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3",
+			expected: book.ResultBlackWins,
+		},
+		"stalemate": {
+			fen:      "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1",
+			expected: book.ResultDraw,
+		},
+		"insufficient material": {
+			fen:      "7k/8/6K1/8/8/8/8/8 b - - 0 1",
+			expected: book.ResultDraw,
+		},
+		"game still running": {
+			fen:      board.FenStartPos,
+			expected: book.ResultUnknown,
+		},
+	}
+
+	e := engine.NewEngine()
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			pos, err := board.NewPositionFromFEN(d.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, d.expected, finalResult(e, pos, []uint64{pos.ZobristKey()}))
+		})
+	}
+}
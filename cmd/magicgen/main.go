@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	board "chessV2/internal/board"
+	"chessV2/internal/movegen"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+)
+
+func main() {
+	var (
+		layout movegen.MagicLayout
+		seed   int64
+		out    string
+	)
+
+	flag.BoolVar(&layout.FixedShift, "fixed-shift", false, "Use one shift for every square: 12 index bits for rooks, 9 for bishops")
+	flag.BoolVar(&layout.Fancy, "fancy", false, "Let square tables overlap in the shared table where their entries agree")
+	flag.Int64Var(&seed, "seed", time.Now().UnixNano(), "Random seed for the magic search")
+	flag.StringVar(&out, "out", "", "Go file to write, for example internal/movegen/magic_numbers.go; standard output when empty")
+	flag.Parse()
+
+	rng := rand.New(rand.NewSource(seed))
+	sets := make([]movegen.MagicSet, 0, 2)
+	for _, pieceType := range []int8{board.Rook, board.Bishop} {
+		start := time.Now()
+		set, err := movegen.FindMagics(pieceType, layout, rng)
+		if err == nil {
+			err = movegen.VerifyMagics(set)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", pieceName(pieceType), err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "%s, %s layout: %d entries (%d KiB) in %s\n", pieceName(pieceType), layout, set.TableSize, set.TableSize*8/1024, time.Since(start).Round(time.Millisecond))
+		sets = append(sets, set)
+	}
+
+	source, err := magicSource(generatorCommand(layout, seed), sets[0], sets[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if out == "" {
+		os.Stdout.Write(source)
+		return
+	}
+	if err := os.WriteFile(out, source, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// generatorCommand is the magicgen invocation that reproduces the file,
+// recorded in its header.
+func generatorCommand(layout movegen.MagicLayout, seed int64) string {
+	command := fmt.Sprintf("magicgen -seed %d", seed)
+	if layout.FixedShift {
+		command += " -fixed-shift"
+	}
+	if layout.Fancy {
+		command += " -fancy"
+	}
+	return command
+}
+
+func pieceName(pieceType int8) string {
+	if pieceType == board.Bishop {
+		return "bishop"
+	}
+	return "rook"
+}
+
+// magicSource renders the magic_numbers.go file the movegen package loads
+// its slider tables from, gofmt'ed.
+func magicSource(command string, rook movegen.MagicSet, bishop movegen.MagicSet) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by %q; DO NOT EDIT.\n\n", command)
+	b.WriteString("package movegen\n\n")
+	b.WriteString("const (\n")
+	fmt.Fprintf(&b, "\trookMagicTableSize = %d\n", rook.TableSize)
+	fmt.Fprintf(&b, "\tbishopMagicTableSize = %d\n", bishop.TableSize)
+	b.WriteString(")\n")
+
+	for _, set := range []movegen.MagicSet{rook, bishop} {
+		name := pieceName(set.PieceType)
+		b.WriteString("\n")
+		writeArray(&b, name+"MagicNumbers", "uint64", 4, func(sq int) string { return fmt.Sprintf("0x%016x", set.Magics[sq]) })
+		b.WriteString("\n")
+		writeArray(&b, name+"MagicShifts", "uint8", 16, func(sq int) string { return fmt.Sprint(set.Shifts[sq]) })
+		b.WriteString("\n")
+		writeArray(&b, name+"MagicOffsets", "uint32", 8, func(sq int) string { return fmt.Sprint(set.Offsets[sq]) })
+	}
+
+	return format.Source(b.Bytes())
+}
+
+func writeArray(w io.Writer, name string, elem string, perLine int, value func(sq int) string) {
+	fmt.Fprintf(w, "var %s = [64]%s{\n", name, elem)
+	for sq := 0; sq < 64; sq += perLine {
+		values := make([]string, 0, perLine)
+		for i := sq; i < sq+perLine; i++ {
+			values = append(values, value(i))
+		}
+		fmt.Fprintf(w, "\t%s,\n", strings.Join(values, ", "))
+	}
+	io.WriteString(w, "}\n")
+}
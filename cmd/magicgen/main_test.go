@@ -0,0 +1,45 @@
+package main
+
+import (
+	board "chessV2/internal/board"
+	"chessV2/internal/movegen"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMagicSourceReproducesEmbeddedFile(t *testing.T) {
+	embedded, err := os.ReadFile("../../internal/movegen/magic_numbers.go")
+	assert.NoError(t, err)
+
+	header, _, _ := strings.Cut(string(embedded), "\n")
+	command := strings.TrimSuffix(strings.TrimPrefix(header, "// Code generated by \""), "\"; DO NOT EDIT.")
+
+	source, err := magicSource(command, movegen.EmbeddedMagics(board.Rook), movegen.EmbeddedMagics(board.Bishop))
+	assert.NoError(t, err)
+	assert.Equal(t, string(embedded), string(source))
+}
+
+func TestGeneratorCommand(t *testing.T) {
+	data := map[string]struct {
+		layout   movegen.MagicLayout
+		expected string
+	}{
+		"plain": {
+			layout:   movegen.MagicLayout{},
+			expected: "magicgen -seed 7",
+		},
+		"fixed shift fancy": {
+			layout:   movegen.MagicLayout{FixedShift: true, Fancy: true},
+			expected: "magicgen -seed 7 -fixed-shift -fancy",
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, d.expected, generatorCommand(d.layout, 7))
+		})
+	}
+}
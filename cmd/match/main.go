@@ -20,6 +20,7 @@ func main() {
 	var notes string
 	var plain bool
 	var recordPath string
+	var pgnPath string
 
 	flag.StringVar(&opponentTag, "opponent-tag", "", "Git tag to build and use as the opponent")
 	flag.IntVar(&games, "games", 2, "Number of games to play")
@@ -29,6 +30,7 @@ func main() {
 	flag.StringVar(&notes, "notes", "", "Optional notes to include in the printed markdown row")
 	flag.BoolVar(&plain, "plain", false, "Use plain line-based progress instead of the live terminal dashboard")
 	flag.StringVar(&recordPath, "record-path", "", "Optional JSONL path for per-move FEN/move records")
+	flag.StringVar(&pgnPath, "pgn-path", "", "Optional PGN path for finished games")
 	flag.Parse()
 
 	repoRoot, err := os.Getwd()
@@ -66,6 +68,7 @@ func main() {
 		MoveOverhead: time.Duration(moveOverheadMs) * time.Millisecond,
 		Notes:        notes,
 		RecordPath:   recordPath,
+		PGNPath:      pgnPath,
 		Progress:     progress,
 	})
 	if err != nil {
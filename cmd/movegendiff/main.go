@@ -0,0 +1,47 @@
+package main
+
+import (
+	board "chessV2/internal/board"
+	"chessV2/internal/movegen"
+	"chessV2/internal/movegen/reference"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+)
+
+func main() {
+	var (
+		positions int
+		seed      int64
+		report    int
+	)
+
+	flag.IntVar(&positions, "positions", 1000000, "Number of random positions to check")
+	flag.Int64Var(&seed, "seed", time.Now().UnixNano(), "Random seed; rerun with the printed seed to reproduce a failure")
+	flag.IntVar(&report, "report", 100000, "Print progress every this many positions, 0 to disable")
+	flag.Parse()
+
+	fmt.Printf("Seed: %d\n", seed)
+	rng := rand.New(rand.NewSource(seed))
+	generator := movegen.NewPseudoLegalMoveGenerator()
+	updater := board.NewPositionUpdater()
+	failing := func(pos *board.Position) bool {
+		return reference.Check(pos, generator, updater) != nil
+	}
+
+	start := time.Now()
+	for i := 1; i <= positions; i++ {
+		pos := reference.RandomPosition(rng)
+		if err := reference.Check(pos, generator, updater); err != nil {
+			fmt.Printf("Position %d: %v\n", i, err)
+			fmt.Printf("Shrunk: %s\n", reference.Shrink(pos, failing))
+			os.Exit(1)
+		}
+		if report > 0 && i%report == 0 {
+			fmt.Printf("%d positions checked in %s\n", i, time.Since(start).Round(time.Millisecond))
+		}
+	}
+	fmt.Printf("No difference in %d positions (%s).\n", positions, time.Since(start).Round(time.Millisecond))
+}
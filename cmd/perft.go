@@ -3,26 +3,42 @@ package main
 import (
 	board "chessV2/internal/board"
 	"chessV2/internal/engine"
+	"flag"
 	"fmt"
 	"os"
 	"sort"
 	"strconv"
+	"text/tabwriter"
 )
 
 func main() {
+	stats := flag.Bool("stats", false, "print per-depth move statistics instead of the divide output")
+	threads := flag.Int("threads", 1, "number of goroutines the divide run is split across")
+	flag.Parse()
+
 	fenPos := board.FenStartPos
-	if len(os.Args) != 3 {
+	if flag.NArg() != 2 {
 		panic("Invalid number of arguments")
 	}
 
-	fenPos = os.Args[1]
-	depth, err := strconv.Atoi(os.Args[2])
+	fenPos = flag.Arg(0)
+	depth, err := strconv.Atoi(flag.Arg(1))
 	if err != nil {
 		panic(err)
 	}
 
 	e := engine.NewEngine()
-	pos, _ := board.NewPositionFromFEN(fenPos)
+	e.SetPerftThreads(*threads)
+	pos, err := board.NewValidPositionFromFEN(fenPos)
+	if err != nil {
+		panic(err)
+	}
+
+	if *stats {
+		printStatistics(e.PerftStatistics(pos, depth))
+		return
+	}
+
 	res, nodesCount := e.PerftDivide(pos, depth)
 
 	keys := make([]string, 0, len(res))
@@ -39,3 +55,15 @@ func main() {
 
 	fmt.Println("Nodes searched:", nodesCount)
 }
+
+// printStatistics uses the column layout of the chessprogramming perft
+// results tables so the numbers can be compared line by line.
+func printStatistics(stats []engine.PerftStats) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Depth\tNodes\tCaptures\tE.p.\tCastles\tPromotions\tChecks\tDiscovery Checks\tDouble Checks\tCheckmates\t")
+	for i, s := range stats {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t\n",
+			i+1, s.Nodes, s.Captures, s.EnPassants, s.Castles, s.Promotions, s.Checks, s.DiscoveredChecks, s.DoubleChecks, s.Checkmates)
+	}
+	w.Flush()
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	board "chessV2/internal/board"
+	"chessV2/internal/engine"
+	"chessV2/internal/match"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// divideFunc returns the perft divide at depth of the position reached by
+// playing moves from the bisected FEN.
+type divideFunc func(moves []string, depth int) (map[string]uint64, error)
+
+// mismatch is where the two generators disagree: the position reached by
+// path, and the moves only one of them generates there.
+type mismatch struct {
+	path    []string
+	fen     string
+	missing []string
+	extra   []string
+}
+
+func main() {
+	var (
+		enginePath string
+		fen        string
+		depth      int
+		timeout    time.Duration
+	)
+
+	flag.StringVar(&enginePath, "engine", "stockfish", "Path to the reference UCI engine; it must support go perft")
+	flag.StringVar(&fen, "fen", board.FenStartPos, "Position to bisect from")
+	flag.IntVar(&depth, "depth", 0, "Perft depth to start from")
+	flag.DurationVar(&timeout, "timeout", 10*time.Minute, "Time allowed for each reference perft run")
+	flag.Parse()
+
+	if depth <= 0 {
+		fmt.Fprintln(os.Stderr, "missing required -depth")
+		os.Exit(2)
+	}
+
+	pos, err := board.NewValidPositionFromFEN(fen)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	client, err := match.NewUCIClient(enginePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer client.Close()
+
+	if pos.Chess960() {
+		if err := client.SetOption("UCI_Chess960", "true"); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	e := engine.NewEngine()
+	reference := func(moves []string, depth int) (map[string]uint64, error) {
+		divide, _, err := client.Perft(fen, moves, depth, timeout)
+		return divide, err
+	}
+
+	found, err := bisect(os.Stdout, e, fen, depth, engineDivide(e, fen), reference)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if found == nil {
+		fmt.Println("No difference found.")
+		return
+	}
+
+	writeMismatch(os.Stdout, found)
+	os.Exit(1)
+}
+
+// engineDivide runs PerftDivide on our own engine.
+func engineDivide(e *engine.Engine, fen string) divideFunc {
+	return func(moves []string, depth int) (map[string]uint64, error) {
+		pos, err := board.NewValidPositionFromFEN(fen)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.ApplyUCIMoves(pos, moves); err != nil {
+			return nil, err
+		}
+		divide, _ := e.PerftDivide(pos, depth)
+		return divide, nil
+	}
+}
+
+// bisect compares both divides and, while the move lists agree, descends
+// into the first move whose counts differ, one depth shallower each time.
+// At the latest at depth 1 every count is 1, so a remaining difference is a
+// move only one side generates. It returns nil when the divides match.
+func bisect(w io.Writer, e *engine.Engine, fen string, depth int, ours divideFunc, reference divideFunc) (*mismatch, error) {
+	path := make([]string, 0, depth)
+	for ; depth > 0; depth-- {
+		oursDivide, err := ours(path, depth)
+		if err != nil {
+			return nil, err
+		}
+		referenceDivide, err := reference(path, depth)
+		if err != nil {
+			return nil, err
+		}
+
+		missing, extra := diffMoves(oursDivide, referenceDivide)
+		if len(missing) > 0 || len(extra) > 0 {
+			pos, err := board.NewValidPositionFromFEN(fen)
+			if err != nil {
+				return nil, err
+			}
+			if err := e.ApplyUCIMoves(pos, path); err != nil {
+				return nil, err
+			}
+			return &mismatch{path: path, fen: pos.FEN(), missing: missing, extra: extra}, nil
+		}
+
+		move, ok := firstCountMismatch(oursDivide, referenceDivide)
+		if !ok {
+			return nil, nil
+		}
+		fmt.Fprintf(w, "depth %d: %s ours %d, reference %d\n", depth, move, oursDivide[move], referenceDivide[move])
+		path = append(path, move)
+	}
+	return nil, nil
+}
+
+// diffMoves returns the moves only the reference has and those only we
+// have, both sorted.
+func diffMoves(ours map[string]uint64, reference map[string]uint64) ([]string, []string) {
+	missing := make([]string, 0)
+	for move := range reference {
+		if _, ok := ours[move]; !ok {
+			missing = append(missing, move)
+		}
+	}
+	extra := make([]string, 0)
+	for move := range ours {
+		if _, ok := reference[move]; !ok {
+			extra = append(extra, move)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(extra)
+	return missing, extra
+}
+
+func firstCountMismatch(ours map[string]uint64, reference map[string]uint64) (string, bool) {
+	moves := make([]string, 0, len(ours))
+	for move := range ours {
+		moves = append(moves, move)
+	}
+	sort.Strings(moves)
+
+	for _, move := range moves {
+		if ours[move] != reference[move] {
+			return move, true
+		}
+	}
+	return "", false
+}
+
+func writeMismatch(w io.Writer, found *mismatch) {
+	path := strings.Join(found.path, " ")
+	if path == "" {
+		path = "(none)"
+	}
+	fmt.Fprintf(w, "Moves: %s\n", path)
+	fmt.Fprintf(w, "FEN: %s\n", found.fen)
+	if len(found.missing) > 0 {
+		fmt.Fprintf(w, "Missing moves (reference only): %s\n", strings.Join(found.missing, " "))
+	}
+	if len(found.extra) > 0 {
+		fmt.Fprintf(w, "Extra moves (ours only): %s\n", strings.Join(found.extra, " "))
+	}
+}
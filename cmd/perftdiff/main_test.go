@@ -0,0 +1,95 @@
+package main
+
+import (
+	board "chessV2/internal/board"
+	"chessV2/internal/engine"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// tamperedDivide is a reference whose generator drops skipMove, or adds
+// nothing when skipMove is empty, in the position with skipFEN.
+func tamperedDivide(e *engine.Engine, fen string, skipFEN string, skipMove string) divideFunc {
+	var perft func(pos *board.Position, depth int) uint64
+	legalMoves := func(pos *board.Position) []board.Move {
+		moves := make([]board.Move, 0)
+		for _, move := range e.LegalMoves(pos) {
+			if pos.FEN() == skipFEN && move.UCI() == skipMove {
+				continue
+			}
+			moves = append(moves, move)
+		}
+		return moves
+	}
+	perft = func(pos *board.Position, depth int) uint64 {
+		if depth == 0 {
+			return 1
+		}
+		nodes := uint64(0)
+		for _, move := range legalMoves(pos) {
+			child := pos.Clone()
+			e.ApplyMove(child, move)
+			nodes += perft(child, depth-1)
+		}
+		return nodes
+	}
+
+	return func(moves []string, depth int) (map[string]uint64, error) {
+		pos, err := board.NewValidPositionFromFEN(fen)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.ApplyUCIMoves(pos, moves); err != nil {
+			return nil, err
+		}
+		divide := make(map[string]uint64)
+		for _, move := range legalMoves(pos) {
+			child := pos.Clone()
+			e.ApplyMove(child, move)
+			divide[move.UCI()] = perft(child, depth-1)
+		}
+		return divide, nil
+	}
+}
+
+func TestBisectFindsTheDisagreeingPosition(t *testing.T) {
+	e := engine.NewEngine()
+	skipFEN := "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e6 0 2"
+
+	var log strings.Builder
+	found, err := bisect(&log, e, board.FenStartPos, 4, engineDivide(e, board.FenStartPos), tamperedDivide(e, board.FenStartPos, skipFEN, "g1f3"))
+	assert.NoError(t, err)
+	if assert.NotNil(t, found) {
+		assert.Equal(t, []string{"e2e4", "e7e5"}, found.path)
+		assert.Equal(t, skipFEN, found.fen)
+		assert.Empty(t, found.missing)
+		assert.Equal(t, []string{"g1f3"}, found.extra)
+	}
+	assert.Contains(t, log.String(), "depth 4: e2e4")
+	assert.Contains(t, log.String(), "depth 3: e7e5")
+
+	var out strings.Builder
+	writeMismatch(&out, found)
+	assert.Contains(t, out.String(), "FEN: "+skipFEN)
+	assert.Contains(t, out.String(), "Extra moves (ours only): g1f3")
+}
+
+func TestBisectReportsNoDifference(t *testing.T) {
+	e := engine.NewEngine()
+	fen := "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"
+
+	found, err := bisect(&strings.Builder{}, e, fen, 2, engineDivide(e, fen), tamperedDivide(e, fen, "", ""))
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+func TestDiffMoves(t *testing.T) {
+	missing, extra := diffMoves(
+		map[string]uint64{"e2e4": 1, "d2d4": 1, "a2a3": 1},
+		map[string]uint64{"e2e4": 1, "d2d4": 1, "h2h3": 1, "g2g3": 1},
+	)
+	assert.Equal(t, []string{"g2g3", "h2h3"}, missing)
+	assert.Equal(t, []string{"a2a3"}, extra)
+}
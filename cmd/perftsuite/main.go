@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bufio"
+	board "chessV2/internal/board"
+	"chessV2/internal/engine"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// epdEntry is one line of a perft EPD file: a FEN followed by the expected
+// node count of each listed depth, as in "fen ;D1 20 ;D2 400".
+type epdEntry struct {
+	line     int
+	fen      string
+	expected []depthCount
+}
+
+type depthCount struct {
+	depth int
+	nodes uint64
+}
+
+func main() {
+	var (
+		epdPath       string
+		maxDepth      int
+		threads       int
+		noPerftTricks bool
+	)
+
+	flag.StringVar(&epdPath, "epd", "", "Path to the perft EPD file")
+	flag.IntVar(&maxDepth, "max-depth", 5, "Deepest depth to check for each position")
+	flag.IntVar(&threads, "threads", 1, "Number of goroutines each perft run is split across")
+	flag.BoolVar(&noPerftTricks, "no-perft-tricks", false, "Disable bulk counting and the perft transposition table")
+	flag.Parse()
+
+	if epdPath == "" {
+		fmt.Fprintln(os.Stderr, "missing required -epd")
+		os.Exit(2)
+	}
+
+	entries, err := loadEPD(epdPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	e := engine.NewEngine()
+	e.SetPerftTricks(!noPerftTricks)
+	e.SetPerftThreads(threads)
+
+	failures, err := runSuite(os.Stdout, e, entries, maxDepth)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if failures > 0 {
+		os.Exit(1)
+	}
+}
+
+func loadEPD(path string) ([]epdEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries, err := parseEPD(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", path, err)
+	}
+	return entries, nil
+}
+
+// parseEPD skips blank lines and lines starting with '#'.
+func parseEPD(r io.Reader) ([]epdEntry, error) {
+	entries := make([]epdEntry, 0)
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		entry, err := parseEPDLine(text)
+		if err != nil {
+			return nil, fmt.Errorf("%d: %w", line, err)
+		}
+		entry.line = line
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func parseEPDLine(text string) (epdEntry, error) {
+	fields := strings.Split(text, ";")
+	entry := epdEntry{fen: strings.TrimSpace(fields[0])}
+	if _, err := board.NewValidPositionFromFEN(entry.fen); err != nil {
+		return epdEntry{}, err
+	}
+
+	for _, field := range fields[1:] {
+		parts := strings.Fields(field)
+		if len(parts) == 0 {
+			continue
+		}
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], "D") {
+			return epdEntry{}, fmt.Errorf("invalid depth field %q", strings.TrimSpace(field))
+		}
+
+		depth, err := strconv.Atoi(parts[0][1:])
+		if err != nil || depth <= 0 {
+			return epdEntry{}, fmt.Errorf("invalid depth %q", parts[0])
+		}
+		nodes, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			return epdEntry{}, fmt.Errorf("invalid node count %q for %s", parts[1], parts[0])
+		}
+		entry.expected = append(entry.expected, depthCount{depth: depth, nodes: nodes})
+	}
+	if len(entry.expected) == 0 {
+		return epdEntry{}, fmt.Errorf("no depth fields")
+	}
+
+	sort.Slice(entry.expected, func(i, j int) bool {
+		return entry.expected[i].depth < entry.expected[j].depth
+	})
+	return entry, nil
+}
+
+// runSuite checks every entry up to maxDepth, shallowest depth first. The
+// first wrong depth of a position is reported with its divide, which is the
+// starting point for bisecting, and the position's deeper depths are skipped.
+func runSuite(w io.Writer, e *engine.Engine, entries []epdEntry, maxDepth int) (int, error) {
+	start := time.Now()
+	failures := 0
+	totalNodes := uint64(0)
+
+	for _, entry := range entries {
+		pos, err := board.NewValidPositionFromFEN(entry.fen)
+		if err != nil {
+			return failures, fmt.Errorf("line %d: %w", entry.line, err)
+		}
+
+		passed := true
+		for _, expected := range entry.expected {
+			if expected.depth > maxDepth {
+				break
+			}
+
+			divide, nodes := e.PerftDivide(pos, expected.depth)
+			totalNodes += nodes
+			if nodes == expected.nodes {
+				continue
+			}
+
+			passed = false
+			fmt.Fprintf(w, "FAIL line %d depth %d: expected %d, got %d\n", entry.line, expected.depth, expected.nodes, nodes)
+			fmt.Fprintf(w, "  fen: %s\n", entry.fen)
+			writeDivide(w, divide)
+			break
+		}
+
+		if passed {
+			fmt.Fprintf(w, "ok   line %d: %s\n", entry.line, entry.fen)
+		} else {
+			failures++
+		}
+	}
+
+	fmt.Fprintf(w, "\nPositions: %d, failed: %d, nodes: %d, elapsed: %s\n", len(entries), failures, totalNodes, time.Since(start))
+	return failures, nil
+}
+
+func writeDivide(w io.Writer, divide map[string]uint64) {
+	moves := make([]string, 0, len(divide))
+	for move := range divide {
+		moves = append(moves, move)
+	}
+	sort.Strings(moves)
+
+	for _, move := range moves {
+		fmt.Fprintf(w, "  %s: %d\n", move, divide[move])
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	board "chessV2/internal/board"
+	"chessV2/internal/engine"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEPDLine(t *testing.T) {
+	data := map[string]struct {
+		line     string
+		expected epdEntry
+		err      bool
+	}{
+		"six field fen": {
+			line: "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1 ;D2 400 ;D1 20",
+			expected: epdEntry{
+				fen:      board.FenStartPos,
+				expected: []depthCount{{depth: 1, nodes: 20}, {depth: 2, nodes: 400}},
+			},
+		},
+		"four field fen": {
+			line: "4k3/8/8/8/8/8/8/4K3 w - - ;D1 5",
+			expected: epdEntry{
+				fen:      "4k3/8/8/8/8/8/8/4K3 w - -",
+				expected: []depthCount{{depth: 1, nodes: 5}},
+			},
+		},
+		"missing depths": {
+			line: board.FenStartPos,
+			err:  true,
+		},
+		"malformed depth field": {
+			line: board.FenStartPos + " ;X1 20",
+			err:  true,
+		},
+		"malformed node count": {
+			line: board.FenStartPos + " ;D1 twenty",
+			err:  true,
+		},
+		"invalid fen": {
+			line: "8/8/8/8/8/8/8/8 w - - 0 1 ;D1 0",
+			err:  true,
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			entry, err := parseEPDLine(d.line)
+			if d.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, d.expected, entry)
+		})
+	}
+}
+
+func TestParseEPDSkipsCommentsAndReportsLines(t *testing.T) {
+	input := "# comment\n\n4k3/8/8/8/8/8/8/4K3 w - - 0 1 ;D1 5\n4k3/8/8/8/8/8/8/4K3 w - - 0 1 ;D1\n"
+
+	_, err := parseEPD(strings.NewReader(input))
+	assert.ErrorContains(t, err, "4: ")
+
+	entries, err := parseEPD(strings.NewReader(input[:strings.LastIndex(input, "4k3")]))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, 3, entries[0].line)
+}
+
+func TestRunSuite(t *testing.T) {
+	entries, err := loadEPD("testdata/standard.epd")
+	assert.NoError(t, err)
+	assert.Len(t, entries, 6)
+
+	var out strings.Builder
+	failures, err := runSuite(&out, engine.NewEngine(), entries, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, failures, out.String())
+	assert.Contains(t, out.String(), "Positions: 6, failed: 0")
+}
+
+func TestRunSuiteReportsFirstBadDepthWithDivide(t *testing.T) {
+	entries, err := parseEPD(strings.NewReader(board.FenStartPos + " ;D1 20 ;D2 401 ;D3 8902\n"))
+	assert.NoError(t, err)
+
+	var out strings.Builder
+	failures, err := runSuite(&out, engine.NewEngine(), entries, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, failures)
+	assert.Contains(t, out.String(), "FAIL line 1 depth 2: expected 401, got 400")
+	assert.Contains(t, out.String(), "  e2e4: 20\n")
+	assert.NotContains(t, out.String(), "depth 3")
+}
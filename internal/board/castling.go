@@ -0,0 +1,216 @@
+package board
+
+import "strings"
+
+// castleRookIdx layout: [color][side], color 0 is white and 1 is black,
+// side 0 is king side and 1 is queen side.
+var standardCastleRookIdx = [2][2]int8{{H1, A1}, {H8, A8}}
+
+func castleColorIndex(color int8) int {
+	if color == White {
+		return 0
+	}
+	return 1
+}
+
+func castleSideIndex(side int8) int {
+	if side == KingSideCastle {
+		return 0
+	}
+	return 1
+}
+
+func backRank(color int8) int8 {
+	if color == White {
+		return 0
+	}
+	return 7
+}
+
+// CastleDestinations returns the final king and rook squares of a castle,
+// which are the same in standard chess and Chess960.
+func CastleDestinations(color int8, side int8) (int8, int8) {
+	rankOffset := backRank(color) * 8
+	if side == KingSideCastle {
+		return rankOffset + 6, rankOffset + 5
+	}
+	return rankOffset + 2, rankOffset + 3
+}
+
+// CastleRookIdx returns the start square of the rook used to castle on side.
+func (p *Position) CastleRookIdx(color int8, side int8) int8 {
+	return p.castleRookIdx[castleColorIndex(color)][castleSideIndex(side)]
+}
+
+// Chess960 reports whether castle moves are encoded as king-captures-rook.
+func (p *Position) Chess960() bool {
+	return p.chess960
+}
+
+// SetChess960 switches castle move encoding. In Chess960 mode a castle move
+// goes from the king square to the castling rook square, which is also what
+// Move.UCI prints, and FEN writes Shredder-FEN castle fields.
+func (p *Position) SetChess960(enabled bool) {
+	p.chess960 = enabled
+}
+
+// castleSide returns the castling side of a castle move: the rook, or the
+// king destination in standard encoding, lies on the king side of the king.
+func castleSide(move Move) int8 {
+	if FileFromIdx(move.EndIdx()) > FileFromIdx(move.StartIdx()) {
+		return KingSideCastle
+	}
+	return QueenSideCastle
+}
+
+// CastleMoveSquares returns the king destination and the rook start and
+// destination squares of a castle move in either encoding.
+func (p *Position) CastleMoveSquares(move Move) (int8, int8, int8) {
+	return castleMoveSquares(p, move)
+}
+
+// castleMoveSquares resolves a castle move to its king destination and rook
+// start and destination squares.
+func castleMoveSquares(pos *Position, move Move) (int8, int8, int8) {
+	color := move.Piece().Color()
+	side := castleSide(move)
+	kingEndIdx, rookEndIdx := CastleDestinations(color, side)
+
+	rookStartIdx := pos.CastleRookIdx(color, side)
+	if pos.chess960 {
+		rookStartIdx = move.EndIdx()
+	}
+
+	return kingEndIdx, rookStartIdx, rookEndIdx
+}
+
+// parseCastleRight reads one character of a FEN castle field. KQkq follow
+// X-FEN and select the outermost rook on that side, file letters follow
+// Shredder-FEN and name the rook file.
+func (p *Position) parseCastleRight(char rune) error {
+	color := White
+	upper := char
+	if char >= 'a' && char <= 'z' {
+		color = Black
+		upper = char - 'a' + 'A'
+	}
+
+	colorIdx := castleColorIndex(color)
+	rankOffset := backRank(color) * 8
+	kingIdx := p.whiteKingIdx
+	if color == Black {
+		kingIdx = p.blackKingIdx
+	}
+	hasKing := p.board[kingIdx] == Piece(color|King) && RankFromIdx(kingIdx) == backRank(color)
+	rook := Piece(color | Rook)
+
+	var (
+		side    int8
+		rookIdx int8
+	)
+	switch {
+	case upper == 'K' || upper == 'Q':
+		side = QueenSideCastle
+		if upper == 'K' {
+			side = KingSideCastle
+		}
+		rookIdx = standardCastleRookIdx[colorIdx][castleSideIndex(side)]
+		if hasKing {
+			if outer, ok := p.outermostRook(rook, kingIdx, side); ok {
+				rookIdx = outer
+			}
+		}
+	case upper >= 'A' && upper <= 'H':
+		if !hasKing {
+			return &FENError{Field: FieldCastling, Square: NoSquare, Value: string(char), Err: ErrCastleWithoutKing}
+		}
+		rookIdx = rankOffset + int8(upper-'A')
+		if rookIdx == kingIdx {
+			return malformedFEN(FieldCastling, string(char))
+		}
+		side = QueenSideCastle
+		if rookIdx > kingIdx {
+			side = KingSideCastle
+		}
+		p.chess960 = true
+	default:
+		return malformedFEN(FieldCastling, string(char))
+	}
+
+	if color == White {
+		p.whiteCastleRights |= side
+	} else {
+		p.blackCastleRights |= side
+	}
+	p.castleRookIdx[colorIdx][castleSideIndex(side)] = rookIdx
+
+	if rookIdx != standardCastleRookIdx[colorIdx][castleSideIndex(side)] || (hasKing && FileFromIdx(kingIdx) != 4) {
+		p.chess960 = true
+	}
+
+	return nil
+}
+
+func (p *Position) outermostRook(rook Piece, kingIdx int8, side int8) (int8, bool) {
+	rankOffset := RankFromIdx(kingIdx) * 8
+	if side == KingSideCastle {
+		for file := int8(7); file > FileFromIdx(kingIdx); file-- {
+			if p.board[rankOffset+file] == rook {
+				return rankOffset + file, true
+			}
+		}
+		return 0, false
+	}
+
+	for file := int8(0); file < FileFromIdx(kingIdx); file++ {
+		if p.board[rankOffset+file] == rook {
+			return rankOffset + file, true
+		}
+	}
+	return 0, false
+}
+
+// castleRightsFEN writes KQkq, or Shredder-FEN rook files in Chess960 mode.
+func (p *Position) castleRightsFEN() string {
+	var b strings.Builder
+	for _, color := range [2]int8{White, Black} {
+		rights := p.whiteCastleRights
+		if color == Black {
+			rights = p.blackCastleRights
+		}
+
+		for _, side := range [2]int8{KingSideCastle, QueenSideCastle} {
+			if rights&side == 0 {
+				continue
+			}
+
+			var char byte
+			if p.chess960 {
+				char = byte('A' + FileFromIdx(p.CastleRookIdx(color, side)))
+			} else if side == KingSideCastle {
+				char = 'K'
+			} else {
+				char = 'Q'
+			}
+			if color == Black {
+				char += 'a' - 'A'
+			}
+			b.WriteByte(char)
+		}
+	}
+	return b.String()
+}
+
+// clearCastleRightsForRookSquare drops the right whose castling rook starts on rookSquare.
+func clearCastleRightsForRookSquare(pos *Position, rookSquare int8) {
+	switch rookSquare {
+	case pos.castleRookIdx[0][0]:
+		pos.whiteCastleRights &^= KingSideCastle
+	case pos.castleRookIdx[0][1]:
+		pos.whiteCastleRights &^= QueenSideCastle
+	case pos.castleRookIdx[1][0]:
+		pos.blackCastleRights &^= KingSideCastle
+	case pos.castleRookIdx[1][1]:
+		pos.blackCastleRights &^= QueenSideCastle
+	}
+}
@@ -0,0 +1,112 @@
+package board
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCastleRightsFEN(t *testing.T) {
+	data := map[string]struct {
+		fen      string
+		expected string
+		chess960 bool
+	}{
+		"standard rights stay KQkq": {
+			fen:      FenStartPos,
+			expected: FenStartPos,
+		},
+		"Shredder-FEN round trip": {
+			fen:      "bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9",
+			expected: "bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9",
+			chess960: true,
+		},
+		"X-FEN rights resolve to the outermost rook": {
+			fen:      "rk4rr/8/8/8/8/8/8/RK4RR w KQkq - 0 1",
+			expected: "rk4rr/8/8/8/8/8/8/RK4RR w HAha - 0 1",
+			chess960: true,
+		},
+		"file letters on the standard setup": {
+			fen:      "r3k2r/8/8/8/8/8/8/R3K2R w HAha - 0 1",
+			expected: "r3k2r/8/8/8/8/8/8/R3K2R w HAha - 0 1",
+			chess960: true,
+		},
+		"inner rook named by file": {
+			fen:      "4k3/8/8/8/8/8/8/1R2K1RR w G - 0 1",
+			expected: "4k3/8/8/8/8/8/8/1R2K1RR w G - 0 1",
+			chess960: true,
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			pos, err := NewPositionFromFEN(d.fen)
+			assert.NoError(t, err)
+			assert.Equal(t, d.chess960, pos.Chess960())
+			assert.Equal(t, d.expected, pos.FEN())
+		})
+	}
+}
+
+func TestCastleRightsFENRejectsInvalidRights(t *testing.T) {
+	data := map[string]string{
+		"letter without king on the back rank": "8/8/8/8/8/8/4K3/R6R w H - 0 1",
+		"letter on the king file":              "4k3/8/8/8/8/8/8/R3K2R w E - 0 1",
+		"unknown character":                    "4k3/8/8/8/8/8/8/R3K2R w X - 0 1",
+	}
+
+	for name, fen := range data {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewPositionFromFEN(fen)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestChess960CastleMakeUnmake(t *testing.T) {
+	data := map[string]struct {
+		fen      string
+		move     Move
+		expected string
+	}{
+		"king already on g1": {
+			fen:      "4k3/8/8/8/8/8/8/6KR w H - 0 1",
+			move:     NewMove(Piece(White|King), G1, H1, Castle),
+			expected: "4k3/8/8/8/8/8/8/5RK1 b - - 1 1",
+		},
+		"king and rook swap squares": {
+			fen:      "4k3/8/8/8/8/8/8/5KR1 w G - 0 1",
+			move:     NewMove(Piece(White|King), F1, G1, Castle),
+			expected: "4k3/8/8/8/8/8/8/5RK1 b - - 1 1",
+		},
+		"queen side from b1": {
+			fen:      "4k3/8/8/8/8/8/8/RK6 w A - 0 1",
+			move:     NewMove(Piece(White|King), B1, A1, Castle),
+			expected: "4k3/8/8/8/8/8/8/2KR4 b - - 1 1",
+		},
+		"black queen side keeps no rights": {
+			fen:      "r3k1r1/8/8/8/8/8/8/4K3 b ga - 0 1",
+			move:     NewMove(Piece(Black|King), E8, A8, Castle),
+			expected: "2kr2r1/8/8/8/8/8/8/4K3 w - - 1 2",
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			pos, err := NewPositionFromFEN(d.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			initialKey := pos.zobristKey
+
+			updater := NewPositionUpdater()
+			history := updater.MakeMove(pos, d.move)
+			assert.Equal(t, d.expected, pos.FEN())
+			assert.Equal(t, computeZobristKey(pos), pos.zobristKey)
+
+			updater.UnMakeMove(pos, history)
+			assert.Equal(t, d.fen, pos.FEN())
+			assert.Equal(t, initialKey, pos.zobristKey)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package board
+
+import "math/bits"
+
+const darkSquares uint64 = 0xAA55AA55AA55AA55
+
+// DrawReason names the rule that makes a position a draw.
+type DrawReason int8
+
+const (
+	NoDraw DrawReason = iota
+	DrawByRepetition
+	DrawByInsufficientMaterial
+	DrawByFiftyMoveRule
+)
+
+func (r DrawReason) String() string {
+	switch r {
+	case DrawByRepetition:
+		return "draw by repetition"
+	case DrawByInsufficientMaterial:
+		return "insufficient material"
+	case DrawByFiftyMoveRule:
+		return "fifty-move rule"
+	default:
+		return "no draw"
+	}
+}
+
+// DrawReason reports the first draw rule that applies to the position, see
+// IsThreefoldRepetition, IsInsufficientMaterial and IsFiftyMoveDraw. It does
+// not look for checkmate or stalemate: a mate delivered on the move that
+// reaches the fifty-move limit still wins, so callers check for legal moves
+// before trusting DrawByFiftyMoveRule.
+func (p *Position) DrawReason(history []uint64) DrawReason {
+	switch {
+	case p.IsThreefoldRepetition(history):
+		return DrawByRepetition
+	case p.IsInsufficientMaterial():
+		return DrawByInsufficientMaterial
+	case p.IsFiftyMoveDraw():
+		return DrawByFiftyMoveRule
+	}
+	return NoDraw
+}
+
+// IsInsufficientMaterial reports positions where no sequence of legal moves
+// can mate: bare kings, a single minor piece, or bishops only with all of
+// them on squares of the same color.
+func (p *Position) IsInsufficientMaterial() bool {
+	if p.pawnBoard|p.rookBoard|p.queenBoard != 0 {
+		return false
+	}
+
+	minors := bits.OnesCount64(p.knightBoard | p.bishopBoard)
+	if minors <= 1 {
+		return true
+	}
+	if p.knightBoard != 0 {
+		return false
+	}
+	return p.bishopBoard&darkSquares == 0 || p.bishopBoard&^darkSquares == 0
+}
+
+// IsFiftyMoveDraw reports a halfmove clock at or past FiftyMoveRulePlies.
+func (p *Position) IsFiftyMoveDraw() bool {
+	return p.halfMoveClock >= FiftyMoveRulePlies
+}
+
+// RepetitionCount returns how often the position occurs in history, the
+// Zobrist keys of the game in order. The position itself counts once whether
+// or not history ends with its key. Only the last HalfMoveClock plies are
+// scanned: positions before a capture or pawn move cannot come back.
+func (p *Position) RepetitionCount(history []uint64) int {
+	key := p.zobristKey
+	end := len(history)
+	if end > 0 && history[end-1] == key {
+		end--
+	}
+	start := end - int(p.halfMoveClock)
+	if start < 0 {
+		start = 0
+	}
+
+	count := 1
+	for i := start; i < end; i++ {
+		if history[i] == key {
+			count++
+		}
+	}
+	return count
+}
+
+// IsThreefoldRepetition reports a third occurrence of the position in history.
+func (p *Position) IsThreefoldRepetition(history []uint64) bool {
+	return p.RepetitionCount(history) >= 3
+}
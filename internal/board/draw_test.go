@@ -0,0 +1,88 @@
+package board
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsInsufficientMaterial(t *testing.T) {
+	data := map[string]struct {
+		fen      string
+		expected bool
+	}{
+		"bare kings":                   {fen: "4k3/8/8/8/8/8/8/4K3 w - - 0 1", expected: true},
+		"king and bishop":              {fen: "4k3/8/8/8/8/8/8/2B1K3 w - - 0 1", expected: true},
+		"king and knight":              {fen: "4k3/8/8/8/8/8/8/1n2K3 b - - 0 1", expected: true},
+		"bishops all on light squares": {fen: "2b1k3/8/8/8/8/8/8/3BKB2 w - - 0 1", expected: true},
+		"opposite colored bishops":     {fen: "4kb2/8/8/8/8/8/8/3BK3 w - - 0 1", expected: false},
+		"bishops on both colors":       {fen: "4k3/8/8/8/8/8/8/2B1KB2 w - - 0 1", expected: false},
+		"two knights":                  {fen: "4k3/8/8/8/8/8/8/1N2K1N1 w - - 0 1", expected: false},
+		"knight against bishop":        {fen: "4kb2/8/8/8/8/8/8/1N2K3 w - - 0 1", expected: false},
+		"single pawn":                  {fen: "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1", expected: false},
+		"single rook":                  {fen: "4k3/8/8/8/8/8/8/R3K3 w - - 0 1", expected: false},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			pos, err := NewPositionFromFEN(d.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, d.expected, pos.IsInsufficientMaterial())
+		})
+	}
+}
+
+func TestRepetitionCount(t *testing.T) {
+	pos, err := NewPositionFromFEN(FenStartPos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updater := NewPositionUpdater()
+	history := []uint64{pos.ZobristKey()}
+
+	shuffle := []Move{
+		NewMove(Piece(White|Knight), G1, F3, NormalMove),
+		NewMove(Piece(Black|Knight), G8, F6, NormalMove),
+		NewMove(Piece(White|Knight), F3, G1, NormalMove),
+		NewMove(Piece(Black|Knight), F6, G8, NormalMove),
+	}
+	for round := 1; round <= 2; round++ {
+		for _, move := range shuffle {
+			updater.MakeMove(pos, move)
+			history = append(history, pos.ZobristKey())
+		}
+		assert.Equal(t, round+1, pos.RepetitionCount(history))
+		assert.Equal(t, round+1, pos.RepetitionCount(history[:len(history)-1]))
+	}
+	assert.True(t, pos.IsThreefoldRepetition(history))
+	assert.Equal(t, DrawByRepetition, pos.DrawReason(history))
+
+	// A position reached again after a pawn move cannot repeat anything older.
+	pos.halfMoveClock = 0
+	assert.Equal(t, 1, pos.RepetitionCount(history))
+	assert.Equal(t, NoDraw, pos.DrawReason(history))
+}
+
+func TestDrawReason(t *testing.T) {
+	data := map[string]struct {
+		fen      string
+		expected DrawReason
+	}{
+		"playable":                       {fen: FenStartPos, expected: NoDraw},
+		"fifty-move rule":                {fen: "4k3/8/8/8/8/8/4Q3/4K3 w - - 100 80", expected: DrawByFiftyMoveRule},
+		"one ply before the limit":       {fen: "4k3/8/8/8/8/8/4Q3/4K3 w - - 99 80", expected: NoDraw},
+		"dead position before the clock": {fen: "4k3/8/8/8/8/8/4B3/4K3 w - - 100 80", expected: DrawByInsufficientMaterial},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			pos, err := NewPositionFromFEN(d.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, d.expected, pos.DrawReason(nil))
+		})
+	}
+}
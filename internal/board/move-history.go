@@ -2,9 +2,12 @@ package board
 
 type MoveHistory struct {
 	zobristKey          uint64
+	pawnKey             uint64
+	materialKey         uint64
 	whiteKingAffectMask uint64
 	blackKingAffectMask uint64
 	move                Move
+	halfMoveClock       int16
 	capturedPiece       Piece
 	captureIdx          int8
 	// packedState layout:
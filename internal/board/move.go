@@ -2,22 +2,25 @@ package board
 
 var promotionFlags = [4]int8{QueenPromotion, KnightPromotion, BishopPromotion, RookPromotion}
 
-// Move
-// for later
-// [ 8bits chunks]
-// [flag][piece][endIdx][startIdx]
-type Move struct {
-	piece    Piece
-	startIdx int8
-	endIdx   int8
-	flag     int8
-}
+// Move packs a move into one integer so that copies, comparisons and the
+// TT, killer and history tables work on a single word:
+//
+//	bits  0.. 5: start square
+//	bits  6..11: end square
+//	bits 12..15: flag
+//	bits 16..23: moving piece
+//
+// The zero value is NoMove: no real move starts and ends on A1.
+type Move uint32
+
+const NoMove Move = 0
 
-//type Move struct {
-//	piece    Piece
-//	startIdx int8
-//	endIdx   int8
-//}
+const (
+	moveEndShift   = 6
+	moveFlagShift  = 12
+	movePieceShift = 16
+	moveSquareMask = 0x3F
+)
 
 const (
 	NormalMove      = 0
@@ -32,39 +35,45 @@ const (
 )
 
 func NewMove(piece Piece, startIdx int8, endIdx int8, flag int8) Move {
-	return Move{piece, startIdx, endIdx, flag}
+	return Move(uint32(startIdx) | uint32(endIdx)<<moveEndShift | uint32(flag)<<moveFlagShift | uint32(uint8(piece))<<movePieceShift)
 }
 
 func isCastleMove(move Move) bool {
-	return move.flag == Castle || (move.piece.Type() == King && absInt8(move.EndIdx()-move.StartIdx()) == 2)
+	return move.Flag() == Castle || (move.Piece().Type() == King && absInt8(move.EndIdx()-move.StartIdx()) == 2)
 }
 
 func (m Move) StartIdx() int8 {
-	return m.startIdx
+	return int8(m & moveSquareMask)
 }
 
 func (m Move) EndIdx() int8 {
-	return m.endIdx
+	return int8((m >> moveEndShift) & moveSquareMask)
 }
 
 func (m Move) Piece() Piece {
-	return m.piece
+	return Piece(int8(m >> movePieceShift))
 }
 
 func (m Move) Flag() int8 {
-	return m.flag
+	return int8((m >> moveFlagShift) & 0xF)
+}
+
+// StartEndIdx returns the start and end squares as one index below 4096,
+// for tables keyed by both squares.
+func (m Move) StartEndIdx() int {
+	return int(m & (moveSquareMask | moveSquareMask<<moveEndShift))
 }
 
 func (m Move) UCI() string {
-	startRank, startFile := RankAndFile(m.startIdx)
-	endRank, endFile := RankAndFile(m.endIdx)
+	startRank, startFile := RankAndFile(m.StartIdx())
+	endRank, endFile := RankAndFile(m.EndIdx())
 	var buf [5]byte
 	buf[0] = byte('a' + startFile)
 	buf[1] = byte('1' + startRank)
 	buf[2] = byte('a' + endFile)
 	buf[3] = byte('1' + endRank)
 	n := 4
-	switch m.flag {
+	switch m.Flag() {
 	case QueenPromotion:
 		buf[4] = 'q'
 		n = 5
@@ -85,11 +85,57 @@ func TestMoveHelpers_IsCastleAndEnPassant(t *testing.T) {
 	assert.False(t, isCastleMove(NewMove(Piece(White|King), E1, F1, NormalMove)))
 
 	move := NewMove(Piece(White|Pawn), E5, D6, EnPassant)
-	assert.True(t, move.flag == EnPassant || (move.piece.Type() == Pawn && pos.enPassantIdx != NoEnPassant && move.EndIdx() == pos.enPassantIdx && pos.PieceAt(move.EndIdx()) == NoPiece && absInt8(FileFromIdx(move.EndIdx())-FileFromIdx(move.StartIdx())) == 1))
+	assert.True(t, move.Flag() == EnPassant || (move.Piece().Type() == Pawn && pos.enPassantIdx != NoEnPassant && move.EndIdx() == pos.enPassantIdx && pos.PieceAt(move.EndIdx()) == NoPiece && absInt8(FileFromIdx(move.EndIdx())-FileFromIdx(move.StartIdx())) == 1))
 
 	move = NewMove(Piece(White|Pawn), E5, D6, NormalMove)
-	assert.True(t, move.flag == EnPassant || (move.piece.Type() == Pawn && pos.enPassantIdx != NoEnPassant && move.EndIdx() == pos.enPassantIdx && pos.PieceAt(move.EndIdx()) == NoPiece && absInt8(FileFromIdx(move.EndIdx())-FileFromIdx(move.StartIdx())) == 1))
+	assert.True(t, move.Flag() == EnPassant || (move.Piece().Type() == Pawn && pos.enPassantIdx != NoEnPassant && move.EndIdx() == pos.enPassantIdx && pos.PieceAt(move.EndIdx()) == NoPiece && absInt8(FileFromIdx(move.EndIdx())-FileFromIdx(move.StartIdx())) == 1))
 
 	move = NewMove(Piece(White|Pawn), E5, E6, NormalMove)
-	assert.False(t, move.flag == EnPassant || (move.piece.Type() == Pawn && pos.enPassantIdx != NoEnPassant && move.EndIdx() == pos.enPassantIdx && pos.PieceAt(move.EndIdx()) == NoPiece && absInt8(FileFromIdx(move.EndIdx())-FileFromIdx(move.StartIdx())) == 1))
+	assert.False(t, move.Flag() == EnPassant || (move.Piece().Type() == Pawn && pos.enPassantIdx != NoEnPassant && move.EndIdx() == pos.enPassantIdx && pos.PieceAt(move.EndIdx()) == NoPiece && absInt8(FileFromIdx(move.EndIdx())-FileFromIdx(move.StartIdx())) == 1))
+}
+
+func TestMove_PackedFieldsRoundTrip(t *testing.T) {
+	data := map[string]struct {
+		piece    Piece
+		startIdx int8
+		endIdx   int8
+		flag     int8
+	}{
+		"white knight quiet": {
+			piece:    Piece(White | Knight),
+			startIdx: G1,
+			endIdx:   F3,
+			flag:     NormalMove,
+		},
+		"black rook capture from h8": {
+			piece:    Piece(Black | Rook),
+			startIdx: H8,
+			endIdx:   A8,
+			flag:     Capture,
+		},
+		"white pawn rook promotion": {
+			piece:    Piece(White | Pawn),
+			startIdx: G7,
+			endIdx:   G8,
+			flag:     RookPromotion,
+		},
+		"black king castle onto a1": {
+			piece:    Piece(Black | King),
+			startIdx: B1,
+			endIdx:   A1,
+			flag:     Castle,
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			move := NewMove(d.piece, d.startIdx, d.endIdx, d.flag)
+			assert.Equal(t, d.piece, move.Piece())
+			assert.Equal(t, d.startIdx, move.StartIdx())
+			assert.Equal(t, d.endIdx, move.EndIdx())
+			assert.Equal(t, d.flag, move.Flag())
+			assert.Equal(t, int(d.startIdx)+int(d.endIdx)*64, move.StartEndIdx())
+			assert.NotEqual(t, NoMove, move)
+		})
+	}
 }
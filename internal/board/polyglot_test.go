@@ -0,0 +1,141 @@
+package board
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The reference keys are the examples published with the Polyglot book format.
+func TestPolyglotKey_ReferencePositions(t *testing.T) {
+	data := map[string]struct {
+		fen      string
+		expected uint64
+	}{
+		"starting position": {
+			fen:      FenStartPos,
+			expected: 0x463b96181691fc9c,
+		},
+		"e2e4": {
+			fen:      "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+			expected: 0x823c9b50fd114196,
+		},
+		"e2e4 d7d5": {
+			fen:      "rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 2",
+			expected: 0x0756b94461c50fb0,
+		},
+		"e2e4 d7d5 e4e5": {
+			fen:      "rnbqkbnr/ppp1pppp/8/3pP3/8/8/PPPP1PPP/RNBQKBNR b KQkq - 0 2",
+			expected: 0x662fafb965db29d4,
+		},
+		"e2e4 d7d5 e4e5 f7f5": {
+			fen:      "rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+			expected: 0x22a48b5a8e47ff78,
+		},
+		"e2e4 d7d5 e4e5 f7f5 e1e2": {
+			fen:      "rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPPKPPP/RNBQ1BNR b kq - 0 3",
+			expected: 0x652a607ca3f242c1,
+		},
+		"e2e4 d7d5 e4e5 f7f5 e1e2 e8f7": {
+			fen:      "rnbq1bnr/ppp1pkpp/8/3pPp2/8/8/PPPPKPPP/RNBQ1BNR w - - 0 4",
+			expected: 0x00fdd303c946bdd9,
+		},
+		"a2a4 b7b5 h2h4 b5b4 c2c4": {
+			fen:      "rnbqkbnr/p1pppppp/8/8/PpP4P/8/1P1PPPP1/RNBQKBNR b KQkq c3 0 3",
+			expected: 0x3c8123ea7b067637,
+		},
+		"a2a4 b7b5 h2h4 b5b4 c2c4 b4c3 a1a3": {
+			fen:      "rnbqkbnr/p1pppppp/8/8/P6P/R1p5/1P1PPPP1/1NBQKBNR b Kkq - 0 4",
+			expected: 0x5c3f9b829b279560,
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			pos, err := NewPositionFromFEN(d.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, d.expected, pos.PolyglotKey())
+		})
+	}
+}
+
+func TestPolyglotKey_EnPassantOnlyWhenCapturable(t *testing.T) {
+	data := map[string]struct {
+		withEnPassant    string
+		withoutEnPassant string
+		counted          bool
+	}{
+		"no pawn next to the pushed pawn": {
+			withEnPassant:    "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+			withoutEnPassant: "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1",
+			counted:          false,
+		},
+		"white pawn beside a black double push": {
+			withEnPassant:    "rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+			withoutEnPassant: "rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq - 0 3",
+			counted:          true,
+		},
+		"black pawn beside a white double push on the h file": {
+			withEnPassant:    "4k3/8/8/8/6pP/8/8/4K3 b - h3 0 1",
+			withoutEnPassant: "4k3/8/8/8/6pP/8/8/4K3 b - - 0 1",
+			counted:          true,
+		},
+		"capturing pawn pinned to its king": {
+			withEnPassant:    "8/8/8/K2pP2r/8/8/8/7k w - d6 0 1",
+			withoutEnPassant: "8/8/8/K2pP2r/8/8/8/7k w - - 0 1",
+			counted:          true,
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			withEnPassant, err := NewPositionFromFEN(d.withEnPassant)
+			if err != nil {
+				t.Fatal(err)
+			}
+			withoutEnPassant, err := NewPositionFromFEN(d.withoutEnPassant)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, d.counted, withEnPassant.PolyglotKey() != withoutEnPassant.PolyglotKey())
+		})
+	}
+}
+
+func TestPolyglotKey_FollowsMadeMoves(t *testing.T) {
+	pos, err := NewPositionFromFEN(FenStartPos)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updater := NewPositionUpdater()
+	moves := []struct {
+		move     Move
+		expected uint64
+	}{
+		{NewMove(Piece(White|Pawn), A2, A4, PawnDoubleMove), 0},
+		{NewMove(Piece(Black|Pawn), B7, B5, PawnDoubleMove), 0},
+		{NewMove(Piece(White|Pawn), H2, H4, PawnDoubleMove), 0},
+		{NewMove(Piece(Black|Pawn), B5, B4, NormalMove), 0},
+		{NewMove(Piece(White|Pawn), C2, C4, PawnDoubleMove), 0x3c8123ea7b067637},
+		{NewMove(Piece(Black|Pawn), B4, C3, EnPassant), 0},
+		{NewMove(Piece(White|Rook), A1, A3, NormalMove), 0x5c3f9b829b279560},
+	}
+
+	histories := make([]MoveHistory, 0, len(moves))
+	for _, m := range moves {
+		histories = append(histories, updater.MakeMove(pos, m.move))
+		if m.expected != 0 {
+			assert.Equal(t, m.expected, pos.PolyglotKey())
+		}
+	}
+
+	for i := len(histories) - 1; i >= 0; i-- {
+		updater.UnMakeMove(pos, histories[i])
+	}
+	assert.Equal(t, uint64(0x463b96181691fc9c), pos.PolyglotKey())
+}
@@ -12,7 +12,7 @@ func (updater *ZobristPositionUpdater) MakeMove(pos *Position, move Move) MoveHi
 	history := updater.inner.MakeMove(pos, move)
 	history.zobristKey = pos.zobristKey
 
-	startPiece := move.piece
+	startPiece := move.Piece()
 	startPieceIdx := move.StartIdx()
 	endPieceIdx := move.EndIdx()
 	capturedPiece := history.capturedPiece
@@ -25,7 +25,7 @@ func (updater *ZobristPositionUpdater) MakeMove(pos *Position, move Move) MoveHi
 	}
 
 	finalPiece := startPiece
-	switch move.flag {
+	switch move.Flag() {
 	case QueenPromotion:
 		finalPiece = Piece(startPiece.Color() | Queen)
 	case KnightPromotion:
@@ -35,14 +35,14 @@ func (updater *ZobristPositionUpdater) MakeMove(pos *Position, move Move) MoveHi
 	case RookPromotion:
 		finalPiece = Piece(startPiece.Color() | Rook)
 	}
-	key ^= zobristPieceKey(finalPiece, endPieceIdx)
-
 	if isCastleMove(move) {
-		rookStartIdx, rookEndIdx := castleRookSquares(startPiece.Color(), endPieceIdx)
+		kingEndIdx, rookStartIdx, rookEndIdx := castleMoveSquares(pos, move)
+		endPieceIdx = kingEndIdx
 		rook := Piece(startPiece.Color() | Rook)
 		key ^= zobristPieceKey(rook, rookStartIdx)
 		key ^= zobristPieceKey(rook, rookEndIdx)
 	}
+	key ^= zobristPieceKey(finalPiece, endPieceIdx)
 
 	key ^= zobristCastleKey(history.whiteCastleRights(), history.blackCastleRights())
 	key ^= zobristCastleKey(pos.whiteCastleRights, pos.blackCastleRights)
@@ -58,3 +58,18 @@ func (updater *ZobristPositionUpdater) UnMakeMove(pos *Position, history MoveHis
 	updater.inner.UnMakeMove(pos, history)
 	pos.zobristKey = history.zobristKey
 }
+
+// MakeNullMove also updates the key: the side to move flips and the en
+// passant square is cleared. The pawn and material keys do not change.
+func (updater *ZobristPositionUpdater) MakeNullMove(pos *Position) MoveHistory {
+	history := updater.inner.MakeNullMove(pos)
+	history.zobristKey = pos.zobristKey
+
+	pos.zobristKey ^= zobristEPKey(history.enPassantIdx()) ^ zobristEPKey(NoEnPassant) ^ zobristSideToMove
+	return history
+}
+
+func (updater *ZobristPositionUpdater) UnMakeNullMove(pos *Position, history MoveHistory) {
+	updater.inner.UnMakeNullMove(pos, history)
+	pos.zobristKey = history.zobristKey
+}
@@ -41,3 +41,107 @@ func TestZobristPositionUpdater_RestoresKeyOnUnmake(t *testing.T) {
 	updater.UnMakeMove(pos, history)
 	assert.Equal(t, initialKey, pos.zobristKey)
 }
+
+func TestPositionUpdaters_PawnAndMaterialKeys(t *testing.T) {
+	data := map[string]struct {
+		fen             string
+		move            Move
+		pawnKeyChanges  bool
+		materialChanges bool
+	}{
+		"knight move": {
+			fen:  FenStartPos,
+			move: NewMove(Piece(White|Knight), G1, F3, NormalMove),
+		},
+		"pawn double push": {
+			fen:            FenStartPos,
+			move:           NewMove(Piece(White|Pawn), E2, E4, PawnDoubleMove),
+			pawnKeyChanges: true,
+		},
+		"piece takes pawn": {
+			fen:             "4k3/8/8/3p4/8/8/8/3RK3 w - - 0 1",
+			move:            NewMove(Piece(White|Rook), D1, D5, Capture),
+			pawnKeyChanges:  true,
+			materialChanges: true,
+		},
+		"pawn takes piece": {
+			fen:             "4k3/8/8/3n4/4P3/8/8/4K3 w - - 0 1",
+			move:            NewMove(Piece(White|Pawn), E4, D5, Capture),
+			pawnKeyChanges:  true,
+			materialChanges: true,
+		},
+		"en passant": {
+			fen:             "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1",
+			move:            NewMove(Piece(White|Pawn), E5, D6, EnPassant),
+			pawnKeyChanges:  true,
+			materialChanges: true,
+		},
+		"promotion with capture": {
+			fen:             "2r1k3/3P4/8/8/8/8/8/4K3 w - - 0 1",
+			move:            NewMove(Piece(White|Pawn), D7, C8, KnightPromotion),
+			pawnKeyChanges:  true,
+			materialChanges: true,
+		},
+		"black promotion next to a queen": {
+			fen:             "4k3/8/8/8/8/8/1p6/q3K3 b - - 0 1",
+			move:            NewMove(Piece(Black|Pawn), B2, B1, QueenPromotion),
+			pawnKeyChanges:  true,
+			materialChanges: true,
+		},
+		"castle": {
+			fen:  "4k3/8/8/8/8/8/8/R3K2R w KQ - 0 1",
+			move: NewMove(Piece(White|King), E1, G1, Castle),
+		},
+	}
+
+	// The plain updater maintains the pawn and material keys too, so pawn
+	// caches stay valid when perft tricks are off.
+	updaters := map[string]MoveApplier{
+		"plain":   NewPlainPositionUpdater(),
+		"zobrist": NewPositionUpdater(),
+	}
+
+	for name, d := range data {
+		for updaterName, updater := range updaters {
+			t.Run(name+" "+updaterName, func(t *testing.T) {
+				pos, err := NewPositionFromFEN(d.fen)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, computePawnKey(pos), pos.PawnKey())
+				assert.Equal(t, computeMaterialKey(pos), pos.MaterialKey())
+				before := *pos
+
+				history := updater.MakeMove(pos, d.move)
+
+				assert.Equal(t, computePawnKey(pos), pos.PawnKey())
+				assert.Equal(t, computeMaterialKey(pos), pos.MaterialKey())
+				assert.Equal(t, d.pawnKeyChanges, pos.PawnKey() != before.pawnKey)
+				assert.Equal(t, d.materialChanges, pos.MaterialKey() != before.materialKey)
+
+				updater.UnMakeMove(pos, history)
+				assert.Equal(t, before, *pos)
+			})
+		}
+	}
+}
+
+func TestMaterialKey_IgnoresPlacement(t *testing.T) {
+	first, err := NewPositionFromFEN("4k3/pp6/8/8/8/8/6PP/R3K2R w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewPositionFromFEN("R3k3/8/2p5/1p6/8/3P4/P7/4K2R b - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	third, err := NewPositionFromFEN("4k3/pp6/8/8/8/8/6PP/R3K2N w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, first.MaterialKey(), second.MaterialKey())
+	assert.NotEqual(t, first.PawnKey(), second.PawnKey())
+	assert.NotEqual(t, first.MaterialKey(), third.MaterialKey())
+	assert.Equal(t, first.PawnKey(), third.PawnKey())
+}
@@ -33,6 +33,8 @@ func init() {
 type MoveApplier interface {
 	MakeMove(pos *Position, move Move) MoveHistory
 	UnMakeMove(pos *Position, history MoveHistory)
+	MakeNullMove(pos *Position) MoveHistory
+	UnMakeNullMove(pos *Position, history MoveHistory)
 }
 
 type PlainPositionUpdater struct{}
@@ -49,21 +51,6 @@ func NewPlainPositionUpdater() *PlainPositionUpdater {
 	return &PlainPositionUpdater{}
 }
 
-func castleRookSquares(activeColor int8, kingEndIdx int8) (int8, int8) {
-	if activeColor == White {
-		if kingEndIdx == G1 {
-			return H1, F1
-		}
-		return A1, D1
-	}
-
-	if kingEndIdx == G8 {
-		return H8, F8
-	}
-
-	return A8, D8
-}
-
 func (updater *PlainPositionUpdater) invalidateKingSafetyCaches(pos *Position) {
 	pos.whiteKingSafety = NotCalculated
 	pos.blackKingSafety = NotCalculated
@@ -133,33 +120,22 @@ func promotionPieceType(flag int8) int8 {
 	}
 }
 
-func clearCastleRightsForRookSquare(pos *Position, rookSquare int8) {
-	switch rookSquare {
-	case A1:
-		pos.whiteCastleRights &^= QueenSideCastle
-	case H1:
-		pos.whiteCastleRights &^= KingSideCastle
-	case A8:
-		pos.blackCastleRights &^= QueenSideCastle
-	case H8:
-		pos.blackCastleRights &^= KingSideCastle
-	}
-}
-
 func (updater *PlainPositionUpdater) MakeMove(pos *Position, move Move) MoveHistory {
-	startPieceIdx := move.startIdx
-	endPieceIdx := move.endIdx
-	startPiece := move.piece
+	startPieceIdx := move.StartIdx()
+	endPieceIdx := move.EndIdx()
+	startPiece := move.Piece()
 	startColor := pos.activeColor
 	startPieceType := startPiece.Type()
-	flag := move.flag
+	flag := move.Flag()
 	isEnPassant := flag == EnPassant
 	isPromotion := flag >= QueenPromotion && flag <= RookPromotion
 	isCastle := flag == Castle
 
 	captureIdx := endPieceIdx
 	capturedPiece := pos.board[endPieceIdx]
-	if isEnPassant {
+	if isCastle {
+		capturedPiece = NoPiece
+	} else if isEnPassant {
 		if startColor == White {
 			captureIdx = endPieceIdx - 8
 		} else {
@@ -180,8 +156,11 @@ func (updater *PlainPositionUpdater) MakeMove(pos *Position, move Move) MoveHist
 			uint32(pos.blackCastleRights)<<metaBlackCastleShift |
 			(uint32(pos.whiteKingSafety)>>3)<<metaWhiteSafetyShift |
 			(uint32(pos.blackKingSafety)>>3)<<metaBlackSafetyShift,
+		pawnKey:             pos.pawnKey,
+		materialKey:         pos.materialKey,
 		whiteKingAffectMask: pos.whiteKingAffectMask,
 		blackKingAffectMask: pos.blackKingAffectMask,
+		halfMoveClock:       pos.halfMoveClock,
 	}
 
 	if (flag == NormalMove || flag == PawnDoubleMove) && !isCapture {
@@ -296,29 +275,29 @@ func (updater *PlainPositionUpdater) MakeMove(pos *Position, move Move) MoveHist
 		pos.board[startPieceIdx] = NoPiece
 		pos.board[endPieceIdx] = Piece(startColor | promotedPieceType)
 	} else if isCastle {
-		fromMask := uint64(1 << startPieceIdx)
-		toMask := uint64(1 << endPieceIdx)
-		rookStartIdx, rookEndIdx := castleRookSquares(startColor, endPieceIdx)
-		rookFromMask := uint64(1 << rookStartIdx)
-		rookToMask := uint64(1 << rookEndIdx)
-		kingMoveMask := fromMask | toMask
-		rookMoveMask := rookFromMask | rookToMask
+		// Chess960 king and rook squares may overlap, so clear both pieces before placing them.
+		kingEndIdx, rookStartIdx, rookEndIdx := castleMoveSquares(pos, move)
+		fromMask := uint64(1<<startPieceIdx) | uint64(1<<rookStartIdx)
+		toMask := uint64(1<<kingEndIdx) | uint64(1<<rookEndIdx)
 
-		pos.occupied ^= kingMoveMask | rookMoveMask
+		pos.occupied = pos.occupied&^fromMask | toMask
 		if startColor == White {
-			pos.whiteOccupied ^= kingMoveMask | rookMoveMask
+			pos.whiteOccupied = pos.whiteOccupied&^fromMask | toMask
 		} else {
-			pos.blackOccupied ^= kingMoveMask | rookMoveMask
+			pos.blackOccupied = pos.blackOccupied&^fromMask | toMask
 		}
-		xorPieceBoard(pos, King, kingMoveMask)
-		xorPieceBoard(pos, Rook, rookMoveMask)
+		pos.kingBoard = pos.kingBoard&^(1<<startPieceIdx) | 1<<kingEndIdx
+		pos.rookBoard = pos.rookBoard&^(1<<rookStartIdx) | 1<<rookEndIdx
 
 		pos.board[startPieceIdx] = NoPiece
-		pos.board[endPieceIdx] = startPiece
 		pos.board[rookStartIdx] = NoPiece
+		pos.board[kingEndIdx] = startPiece
 		pos.board[rookEndIdx] = Piece(startColor | Rook)
+		endPieceIdx = kingEndIdx
 	}
 
+	updatePawnAndMaterialKeys(pos, startPiece, pos.board[endPieceIdx], startPieceIdx, endPieceIdx, capturedPiece, captureIdx)
+
 	// King move -> update king pos and castleRights
 	if startPieceType == King {
 		if startColor == White {
@@ -357,6 +336,16 @@ func (updater *PlainPositionUpdater) MakeMove(pos *Position, move Move) MoveHist
 		clearCastleRightsForRookSquare(pos, captureIdx)
 	}
 
+	// move counters
+	if startPieceType == Pawn || isCapture {
+		pos.halfMoveClock = 0
+	} else {
+		pos.halfMoveClock++
+	}
+	if startColor == Black {
+		pos.fullMoveNumber++
+	}
+
 	updater.invalidateKingSafetyCaches(pos)
 
 	// change side ( important to do it last for previous updates )
@@ -369,14 +358,37 @@ func (updater *PlainPositionUpdater) MakeMove(pos *Position, move Move) MoveHist
 	return history
 }
 
+// updatePawnAndMaterialKeys applies a move to the pawn and material keys.
+// pos is the position after the move, so the piece counts are the new ones:
+// a removed piece drops the key of its count after removal, an added one
+// the key of its count before.
+func updatePawnAndMaterialKeys(pos *Position, startPiece, finalPiece Piece, startIdx, endIdx int8, capturedPiece Piece, captureIdx int8) {
+	if startPiece.Type() == Pawn {
+		pos.pawnKey ^= zobristPieceKey(startPiece, startIdx)
+		if finalPiece == startPiece {
+			pos.pawnKey ^= zobristPieceKey(startPiece, endIdx)
+		} else {
+			pos.materialKey ^= zobristMaterialKey(startPiece, pieceCount(pos, startPiece))
+			pos.materialKey ^= zobristMaterialKey(finalPiece, pieceCount(pos, finalPiece)-1)
+		}
+	}
+
+	if capturedPiece != NoPiece {
+		if capturedPiece.Type() == Pawn {
+			pos.pawnKey ^= zobristPieceKey(capturedPiece, captureIdx)
+		}
+		pos.materialKey ^= zobristMaterialKey(capturedPiece, pieceCount(pos, capturedPiece))
+	}
+}
+
 func (updater *PlainPositionUpdater) UnMakeMove(pos *Position, history MoveHistory) {
 	move := history.move
-	startPieceIdx := move.startIdx
-	endPieceIdx := move.endIdx
+	startPieceIdx := move.StartIdx()
+	endPieceIdx := move.EndIdx()
 	packedState := history.packedState
-	movePiece := move.piece
+	movePiece := move.Piece()
 	movePieceType := movePiece.Type()
-	flag := move.flag
+	flag := move.Flag()
 	isPromotion := flag >= QueenPromotion && flag <= RookPromotion
 	isCastle := flag == Castle
 
@@ -390,6 +402,12 @@ func (updater *PlainPositionUpdater) UnMakeMove(pos *Position, history MoveHisto
 	pos.whiteKingSafety = int8((packedState>>metaWhiteSafetyShift)&0x3) << 3
 	pos.blackKingSafety = int8((packedState>>metaBlackSafetyShift)&0x3) << 3
 	pos.enPassantIdx = int8((packedState>>metaEnPassantShift)&0x7F) - 1
+	pos.halfMoveClock = history.halfMoveClock
+	pos.pawnKey = history.pawnKey
+	pos.materialKey = history.materialKey
+	if pos.activeColor == Black {
+		pos.fullMoveNumber--
+	}
 
 	if (flag == NormalMove || flag == PawnDoubleMove) && history.capturedPiece == NoPiece {
 		fromMask := uint64(1 << startPieceIdx)
@@ -505,26 +523,62 @@ func (updater *PlainPositionUpdater) UnMakeMove(pos *Position, history MoveHisto
 		pos.board[startPieceIdx] = movePiece
 		pos.board[endPieceIdx] = history.capturedPiece
 	} else if isCastle {
-		fromMask := uint64(1 << startPieceIdx)
-		toMask := uint64(1 << endPieceIdx)
-		rookStartIdx, rookEndIdx := castleRookSquares(pos.activeColor, endPieceIdx)
-		rookFromMask := uint64(1 << rookStartIdx)
-		rookToMask := uint64(1 << rookEndIdx)
-		kingMoveMask := fromMask | toMask
-		rookMoveMask := rookFromMask | rookToMask
+		kingEndIdx, rookStartIdx, rookEndIdx := castleMoveSquares(pos, move)
+		fromMask := uint64(1<<startPieceIdx) | uint64(1<<rookStartIdx)
+		toMask := uint64(1<<kingEndIdx) | uint64(1<<rookEndIdx)
 
-		pos.occupied ^= kingMoveMask | rookMoveMask
+		pos.occupied = pos.occupied&^toMask | fromMask
 		if pos.activeColor == White {
-			pos.whiteOccupied ^= kingMoveMask | rookMoveMask
+			pos.whiteOccupied = pos.whiteOccupied&^toMask | fromMask
 		} else {
-			pos.blackOccupied ^= kingMoveMask | rookMoveMask
+			pos.blackOccupied = pos.blackOccupied&^toMask | fromMask
 		}
-		xorPieceBoard(pos, King, kingMoveMask)
-		xorPieceBoard(pos, Rook, rookMoveMask)
+		pos.kingBoard = pos.kingBoard&^(1<<kingEndIdx) | 1<<startPieceIdx
+		pos.rookBoard = pos.rookBoard&^(1<<rookEndIdx) | 1<<rookStartIdx
 
+		pos.board[kingEndIdx] = NoPiece
+		pos.board[rookEndIdx] = NoPiece
 		pos.board[startPieceIdx] = movePiece
-		pos.board[endPieceIdx] = NoPiece
 		pos.board[rookStartIdx] = Piece(pos.activeColor | Rook)
-		pos.board[rookEndIdx] = NoPiece
 	}
 }
+
+// MakeNullMove passes the turn without moving a piece. The side to move
+// flips, the en passant square is cleared and the move counters advance as
+// for a quiet move. The board does not change, so the king safety caches
+// stay valid and are kept; the history still records them for the unmake.
+func (updater *PlainPositionUpdater) MakeNullMove(pos *Position) MoveHistory {
+	history := MoveHistory{
+		move:                NoMove,
+		packedState:         packMoveHistoryMeta(pos),
+		whiteKingAffectMask: pos.whiteKingAffectMask,
+		blackKingAffectMask: pos.blackKingAffectMask,
+		halfMoveClock:       pos.halfMoveClock,
+	}
+
+	pos.enPassantIdx = NoEnPassant
+	pos.halfMoveClock++
+	if pos.activeColor == Black {
+		pos.fullMoveNumber++
+		pos.activeColor = White
+	} else {
+		pos.activeColor = Black
+	}
+
+	return history
+}
+
+// UnMakeNullMove undoes MakeNullMove with the history it returned.
+func (updater *PlainPositionUpdater) UnMakeNullMove(pos *Position, history MoveHistory) {
+	if pos.activeColor == White {
+		pos.activeColor = Black
+		pos.fullMoveNumber--
+	} else {
+		pos.activeColor = White
+	}
+
+	pos.enPassantIdx = history.enPassantIdx()
+	pos.halfMoveClock = history.halfMoveClock
+	pos.whiteKingSafety = history.whiteKingSafety()
+	pos.blackKingSafety = history.blackKingSafety()
+}
@@ -310,7 +310,64 @@ func TestPositionFENClearsCastleRightsAfterStartRookCapture(t *testing.T) {
 	move := NewMove(Piece(Knight|Black), F2, H1, Capture)
 	updater.MakeMove(pos, move)
 
-	assert.Equal(t, "3qk2r/1p3ppp/3Rp3/8/r7/8/1PP1P1PP/4KB1n w k - 0 1", pos.FEN())
+	assert.Equal(t, "3qk2r/1p3ppp/3Rp3/8/r7/8/1PP1P1PP/4KB1n w k - 0 2", pos.FEN())
+}
+
+func Test_PositionAfterMoveCounters(t *testing.T) {
+	data := map[string]struct {
+		fenPos           string
+		move             Move
+		expectedHalfMove int
+		expectedFullMove int
+	}{
+		"white quiet piece move increments halfmove": {
+			fenPos:           "4k3/8/8/8/8/8/8/4K1N1 w - - 7 20",
+			move:             NewMove(Piece(Knight|White), G1, F3, NormalMove),
+			expectedHalfMove: 8,
+			expectedFullMove: 20,
+		},
+		"black quiet piece move increments fullmove": {
+			fenPos:           "4k1n1/8/8/8/8/8/8/4K3 b - - 7 20",
+			move:             NewMove(Piece(Knight|Black), G8, F6, NormalMove),
+			expectedHalfMove: 8,
+			expectedFullMove: 21,
+		},
+		"pawn move resets halfmove": {
+			fenPos:           "4k3/8/8/8/8/8/4P3/4K3 w - - 12 30",
+			move:             NewMove(Piece(Pawn|White), E2, E4, PawnDoubleMove),
+			expectedHalfMove: 0,
+			expectedFullMove: 30,
+		},
+		"capture resets halfmove": {
+			fenPos:           "3rk3/8/8/8/8/8/8/3RK3 b - - 45 70",
+			move:             NewMove(Piece(Rook|Black), D8, D1, Capture),
+			expectedHalfMove: 0,
+			expectedFullMove: 71,
+		},
+		"castle increments halfmove": {
+			fenPos:           "4k3/8/8/8/8/8/8/4K2R w K - 3 9",
+			move:             NewMove(Piece(King|White), E1, G1, Castle),
+			expectedHalfMove: 4,
+			expectedFullMove: 9,
+		},
+	}
+
+	updater := NewPositionUpdater()
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			pos, err := NewPositionFromFEN(d.fenPos)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			history := updater.MakeMove(pos, d.move)
+			assert.Equal(t, d.expectedHalfMove, pos.HalfMoveClock())
+			assert.Equal(t, d.expectedFullMove, pos.FullMoveNumber())
+
+			updater.UnMakeMove(pos, history)
+			assert.Equal(t, d.fenPos, pos.FEN())
+		})
+	}
 }
 
 func Test_PositionAfterEnPassantUpdate(t *testing.T) {
@@ -470,3 +527,93 @@ func TestPositionUpdater_UnMakeMove(t *testing.T) {
 		assert.Equal(t, beforePawnBoard, pos.pawnBoard)
 	})
 }
+
+func TestPositionUpdater_NullMove(t *testing.T) {
+	data := map[string]struct {
+		fen             string
+		whiteKingSafety int8
+		blackKingSafety int8
+		expectedColor   int8
+		expectedFull    int16
+	}{
+		"white to move": {
+			fen:           FenStartPos,
+			expectedColor: Black,
+			expectedFull:  1,
+		},
+		"black to move advances the full move number": {
+			fen:           "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1",
+			expectedColor: White,
+			expectedFull:  2,
+		},
+		"en passant square is cleared": {
+			fen:           "rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+			expectedColor: Black,
+			expectedFull:  3,
+		},
+		"king safety caches are kept": {
+			fen:             "4k3/8/8/8/8/8/4r3/4K3 w - - 7 40",
+			whiteKingSafety: KingIsCheck,
+			blackKingSafety: KingIsSafe,
+			expectedColor:   Black,
+			expectedFull:    40,
+		},
+	}
+
+	updaters := map[string]MoveApplier{
+		"plain":   NewPlainPositionUpdater(),
+		"zobrist": NewPositionUpdater(),
+	}
+
+	for name, d := range data {
+		for updaterName, updater := range updaters {
+			t.Run(name+"/"+updaterName, func(t *testing.T) {
+				pos, err := NewPositionFromFEN(d.fen)
+				if err != nil {
+					t.Fatal(err)
+				}
+				pos.SetKingSafety(White, d.whiteKingSafety)
+				pos.SetKingSafety(Black, d.blackKingSafety)
+				before := *pos
+
+				history := updater.MakeNullMove(pos)
+
+				assert.Equal(t, d.expectedColor, pos.activeColor)
+				assert.Equal(t, NoEnPassant, pos.enPassantIdx)
+				assert.Equal(t, before.halfMoveClock+1, pos.halfMoveClock)
+				assert.Equal(t, d.expectedFull, pos.fullMoveNumber)
+				assert.Equal(t, before.board, pos.board)
+				assert.Equal(t, d.whiteKingSafety, pos.whiteKingSafety)
+				assert.Equal(t, d.blackKingSafety, pos.blackKingSafety)
+				if updaterName == "zobrist" {
+					assert.Equal(t, computeZobristKey(pos), pos.zobristKey)
+				} else {
+					assert.Equal(t, before.zobristKey, pos.zobristKey)
+				}
+
+				updater.UnMakeNullMove(pos, history)
+				assert.Equal(t, before, *pos)
+			})
+		}
+	}
+}
+
+func TestPositionUpdater_NullMoveBetweenMovesRestoresState(t *testing.T) {
+	pos, err := NewPositionFromFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	updater := NewPositionUpdater()
+	before := *pos
+
+	first := updater.MakeMove(pos, NewMove(Piece(White|Pawn), A2, A4, PawnDoubleMove))
+	null := updater.MakeNullMove(pos)
+	assert.Equal(t, White, pos.activeColor)
+	second := updater.MakeMove(pos, NewMove(Piece(White|King), E1, G1, Castle))
+	assert.Equal(t, computeZobristKey(pos), pos.zobristKey)
+
+	updater.UnMakeMove(pos, second)
+	updater.UnMakeNullMove(pos, null)
+	updater.UnMakeMove(pos, first)
+	assert.Equal(t, before, *pos)
+}
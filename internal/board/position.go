@@ -1,8 +1,6 @@
 package board
 
 import (
-	"errors"
-	"fmt"
 	"strconv"
 	"strings"
 )
@@ -21,6 +19,9 @@ const (
 const FenStartPos = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
 const EmptyBoard = "8/8/8/8/8/8/8/8 w - - 0 1"
 
+// FiftyMoveRulePlies is the halfmove clock value at which either side may claim a draw.
+const FiftyMoveRulePlies = 100
+
 type Position struct {
 	activeColor         int8
 	whiteCastleRights   int8
@@ -36,6 +37,12 @@ type Position struct {
 	whiteKingAffectMask uint64
 	blackKingAffectMask uint64
 	zobristKey          uint64
+	pawnKey             uint64
+	materialKey         uint64
+	halfMoveClock       int16
+	fullMoveNumber      int16
+	castleRookIdx       [2][2]int8
+	chess960            bool
 
 	queenBoard  uint64
 	kingBoard   uint64
@@ -62,6 +69,8 @@ func NewPosition() *Position {
 		blackOccupied:     uint64(0),
 		whiteKingSafety:   NotCalculated,
 		blackKingSafety:   NotCalculated,
+		fullMoveNumber:    1,
+		castleRookIdx:     standardCastleRookIdx,
 		isInit:            false,
 	}
 }
@@ -86,9 +95,9 @@ func NewPositionFromFEN(fen string) (*Position, error) {
 	hasWhiteKing := false
 	hasBlackKing := false
 
-	parts := strings.Split(fen, " ")
-	if len(parts) < 4 {
-		return nil, errors.New("invalid FEN")
+	parts := strings.Fields(fen)
+	if len(parts) < 4 || len(parts) > 6 {
+		return nil, &FENError{Square: NoSquare, Value: fen, Err: ErrFieldCount}
 	}
 
 	// Board init
@@ -97,24 +106,29 @@ func NewPositionFromFEN(fen string) (*Position, error) {
 		file = 0
 	)
 
-	for i, char := range parts[0] {
+	for _, char := range parts[0] {
 		if char == '/' {
+			if file != 8 || rank == 0 {
+				return nil, malformedFEN(FieldPiecePlacement, parts[0])
+			}
 			rank--
 			file = 0
 			continue
 		}
 
-		idx := int8(rank*8 + file)
-
 		if char >= '1' && char <= '8' {
 			file += int(char - '0')
+			if file > 8 {
+				return nil, malformedFEN(FieldPiecePlacement, parts[0])
+			}
 			continue
 		}
 
 		piece, ok := FENCharToPiece[char]
-		if !ok {
-			return nil, errors.New(fmt.Sprintf("invalid FEN: invalid char '%c' at position %d", char, i))
+		if !ok || file > 7 {
+			return nil, malformedFEN(FieldPiecePlacement, parts[0])
 		}
+		idx := int8(rank*8 + file)
 		pos.setPieceAt(idx, piece)
 		if piece.Type() == King {
 			if piece.Color() == White {
@@ -127,15 +141,18 @@ func NewPositionFromFEN(fen string) (*Position, error) {
 		}
 		file++
 	}
+	if rank != 0 || file != 8 {
+		return nil, malformedFEN(FieldPiecePlacement, parts[0])
+	}
 
 	// Next player turn
-	switch parts[1][0] {
-	case 'w':
+	switch parts[1] {
+	case "w":
 		pos.activeColor = White
-	case 'b':
+	case "b":
 		pos.activeColor = Black
 	default:
-		return nil, errors.New(fmt.Sprintf("invalid FEN: invalid color %s", string(parts[1][0])))
+		return nil, malformedFEN(FieldActiveColor, parts[1])
 	}
 
 	// Castle rights
@@ -143,17 +160,8 @@ func NewPositionFromFEN(fen string) (*Position, error) {
 	pos.whiteCastleRights = NoCastle
 	if parts[2] != "-" {
 		for _, char := range parts[2] {
-			switch char {
-			case 'k':
-				pos.blackCastleRights |= KingSideCastle
-			case 'q':
-				pos.blackCastleRights |= QueenSideCastle
-			case 'K':
-				pos.whiteCastleRights |= KingSideCastle
-			case 'Q':
-				pos.whiteCastleRights |= QueenSideCastle
-			default:
-				return nil, errors.New(fmt.Sprintf("invalid FEN: castle rights %s", string(char)))
+			if err := pos.parseCastleRight(char); err != nil {
+				return nil, err
 			}
 		}
 	}
@@ -161,6 +169,9 @@ func NewPositionFromFEN(fen string) (*Position, error) {
 	// En passant
 	pos.enPassantIdx = NoEnPassant
 	if parts[3] != "-" {
+		if len(parts[3]) != 2 || parts[3][0] < 'a' || parts[3][0] > 'h' || parts[3][1] < '1' || parts[3][1] > '8' {
+			return nil, malformedFEN(FieldEnPassant, parts[3])
+		}
 		pos.enPassantIdx = SquareToIdx(parts[3])
 	}
 
@@ -176,10 +187,28 @@ func NewPositionFromFEN(fen string) (*Position, error) {
 	}
 
 	// Half move clock
+	pos.halfMoveClock = 0
+	if len(parts) > 4 {
+		halfMoveClock, err := strconv.ParseInt(parts[4], 10, 16)
+		if err != nil || halfMoveClock < 0 {
+			return nil, malformedFEN(FieldHalfMoveClock, parts[4])
+		}
+		pos.halfMoveClock = int16(halfMoveClock)
+	}
 
 	// full move number
+	pos.fullMoveNumber = 1
+	if len(parts) > 5 {
+		fullMoveNumber, err := strconv.ParseInt(parts[5], 10, 16)
+		if err != nil || fullMoveNumber < 1 {
+			return nil, malformedFEN(FieldFullMoveNumber, parts[5])
+		}
+		pos.fullMoveNumber = int16(fullMoveNumber)
+	}
 
 	pos.zobristKey = computeZobristKey(pos)
+	pos.pawnKey = computePawnKey(pos)
+	pos.materialKey = computeMaterialKey(pos)
 	pos.isInit = true
 
 	return pos, nil
@@ -471,6 +500,26 @@ func (p *Position) ZobristKey() uint64 {
 	return p.zobristKey
 }
 
+// PawnKey hashes the pawn placement alone, for pawn-structure caches.
+func (p *Position) PawnKey() uint64 {
+	return p.pawnKey
+}
+
+// MaterialKey hashes the piece counts of both sides, for material tables.
+func (p *Position) MaterialKey() uint64 {
+	return p.materialKey
+}
+
+// HalfMoveClock returns the number of plies since the last capture or pawn move.
+func (p *Position) HalfMoveClock() int {
+	return int(p.halfMoveClock)
+}
+
+// FullMoveNumber returns the FEN fullmove number, starting at 1 and incremented after each black move.
+func (p *Position) FullMoveNumber() int {
+	return int(p.fullMoveNumber)
+}
+
 func (p *Position) KingSafety(color int8) int8 {
 	if color == White {
 		return p.whiteKingSafety
@@ -534,7 +583,7 @@ func (p *Position) FEN() string {
 	}
 
 	b.WriteByte(' ')
-	castle := castleRightsToFEN(p.whiteCastleRights, p.blackCastleRights)
+	castle := p.castleRightsFEN()
 	if castle == "" {
 		b.WriteByte('-')
 	} else {
@@ -548,9 +597,10 @@ func (p *Position) FEN() string {
 		b.WriteString(IdxToSquare(p.enPassantIdx))
 	}
 
-	// Halfmove/fullmove are not tracked today. `0 1` is sufficient to
-	// reproduce legal move generation and illegal-move diagnostics.
-	b.WriteString(" 0 1")
+	b.WriteByte(' ')
+	b.WriteString(strconv.Itoa(int(p.halfMoveClock)))
+	b.WriteByte(' ')
+	b.WriteString(strconv.Itoa(int(p.fullMoveNumber)))
 	return b.String()
 }
 
@@ -578,20 +628,3 @@ func pieceToFENChar(piece Piece) byte {
 	}
 	return ch
 }
-
-func castleRightsToFEN(whiteRights, blackRights int8) string {
-	var b strings.Builder
-	if whiteRights&KingSideCastle != 0 {
-		b.WriteByte('K')
-	}
-	if whiteRights&QueenSideCastle != 0 {
-		b.WriteByte('Q')
-	}
-	if blackRights&KingSideCastle != 0 {
-		b.WriteByte('k')
-	}
-	if blackRights&QueenSideCastle != 0 {
-		b.WriteByte('q')
-	}
-	return b.String()
-}
@@ -90,6 +90,8 @@ func TestPositionFEN(t *testing.T) {
 		FenStartPos,
 		"r3k2r/pppq1ppp/2npbn2/3Np3/2B1P3/2N5/PPP2PPP/R1BQ1RK1 w kq - 0 1",
 		"8/8/8/3pP3/8/8/8/4K2k b - e6 0 1",
+		"r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 3",
+		"8/8/8/8/8/8/8/4K2k w - - 99 142",
 	}
 
 	for _, fen := range data {
@@ -98,3 +100,21 @@ func TestPositionFEN(t *testing.T) {
 		assert.Equal(t, fen, pos.FEN())
 	}
 }
+
+func TestPositionMoveCounters(t *testing.T) {
+	pos, err := NewPositionFromFEN("4k3/8/8/8/8/8/8/4K3 b - - 37 61")
+	assert.NoError(t, err)
+	assert.Equal(t, 37, pos.HalfMoveClock())
+	assert.Equal(t, 61, pos.FullMoveNumber())
+
+	pos, err = NewPositionFromFEN("4k3/8/8/8/8/8/8/4K3 w - -")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, pos.HalfMoveClock())
+	assert.Equal(t, 1, pos.FullMoveNumber())
+	assert.Equal(t, "4k3/8/8/8/8/8/8/4K3 w - - 0 1", pos.FEN())
+
+	_, err = NewPositionFromFEN("4k3/8/8/8/8/8/8/4K3 w - - x 1")
+	assert.Error(t, err)
+	_, err = NewPositionFromFEN("4k3/8/8/8/8/8/8/4K3 w - - 0 0")
+	assert.Error(t, err)
+}
@@ -48,3 +48,49 @@ func BenchmarkPositionUpdaterMakeUnmakeCastle(b *testing.B) {
 func BenchmarkPositionUpdaterMakeUnmakePromotion(b *testing.B) {
 	benchmarkMakeUnmakeMove(b, "4k3/3P4/8/8/8/8/8/4K3 w - - 0 1", NewMove(Piece(White|Pawn), D7, D8, QueenPromotion))
 }
+
+// moveBenchSink keeps the compiler from discarding the move benchmarks.
+var moveBenchSink int
+
+func BenchmarkMoveAccessors(b *testing.B) {
+	moves := [4]Move{
+		NewMove(Piece(White|Knight), G1, F3, NormalMove),
+		NewMove(Piece(Black|Pawn), E7, E5, PawnDoubleMove),
+		NewMove(Piece(White|Pawn), D7, D8, QueenPromotion),
+		NewMove(Piece(White|King), E1, G1, Castle),
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		move := moves[i&3]
+		moveBenchSink += int(move.StartIdx()) + int(move.EndIdx()) + int(move.Flag()) + int(move.Piece())
+	}
+}
+
+// BenchmarkMoveListContains scans a move list for a move, as the move picker
+// does when it skips the TT move and killers.
+func BenchmarkMoveListContains(b *testing.B) {
+	pos, err := NewPositionFromFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+	if err != nil {
+		b.Fatal(err)
+	}
+	moves := make([]Move, 0, 64)
+	for from := int8(0); from < 64; from++ {
+		if piece := pos.PieceAt(from); piece != NoPiece && piece.Color() == White {
+			for to := int8(0); to < 64 && len(moves) < cap(moves); to += 7 {
+				moves = append(moves, NewMove(piece, from, to, NormalMove))
+			}
+		}
+	}
+	target := moves[len(moves)-1]
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for idx, move := range moves {
+			if move == target {
+				moveBenchSink += idx
+				break
+			}
+		}
+	}
+}
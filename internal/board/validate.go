@@ -0,0 +1,255 @@
+package board
+
+import (
+	"errors"
+	"fmt"
+)
+
+// NoSquare marks a FENError that is not tied to a single square.
+const NoSquare int8 = -1
+
+// FENField names the FEN field an error refers to.
+type FENField string
+
+const (
+	FieldPiecePlacement FENField = "piece placement"
+	FieldActiveColor    FENField = "active color"
+	FieldCastling       FENField = "castling rights"
+	FieldEnPassant      FENField = "en passant"
+	FieldHalfMoveClock  FENField = "halfmove clock"
+	FieldFullMoveNumber FENField = "fullmove number"
+)
+
+var (
+	// ErrInvalidFEN matches every FENError through errors.Is.
+	ErrInvalidFEN = errors.New("invalid FEN")
+
+	ErrFieldCount        = errors.New("expected 4 to 6 space separated fields")
+	ErrMalformedFEN      = errors.New("malformed field")
+	ErrKingCount         = errors.New("each side needs exactly one king")
+	ErrPawnOnBackRank    = errors.New("pawn on the first or last rank")
+	ErrOpponentInCheck   = errors.New("side not to move is in check")
+	ErrCastleWithoutKing = errors.New("castle rights without the king on its home square")
+	ErrCastleWithoutRook = errors.New("castle rights without the rook on its home square")
+	ErrInvalidEnPassant  = errors.New("impossible en passant square")
+)
+
+// FENError reports which field, and square when there is one, makes a FEN
+// unusable. Err is one of the sentinel errors above and Field is empty when
+// the FEN does not split into fields at all.
+type FENError struct {
+	Field  FENField
+	Square int8
+	Value  string
+	Err    error
+}
+
+func (e *FENError) Error() string {
+	msg := "invalid FEN: "
+	if e.Field != "" {
+		msg += string(e.Field) + ": "
+	}
+	msg += e.Err.Error()
+	if e.Square != NoSquare {
+		msg += " on " + IdxToSquare(e.Square)
+	}
+	if e.Value != "" {
+		msg += fmt.Sprintf(" (%q)", e.Value)
+	}
+	return msg
+}
+
+func (e *FENError) Unwrap() error {
+	return e.Err
+}
+
+func (e *FENError) Is(target error) bool {
+	return target == ErrInvalidFEN
+}
+
+func malformedFEN(field FENField, value string) *FENError {
+	return &FENError{Field: field, Square: NoSquare, Value: value, Err: ErrMalformedFEN}
+}
+
+// NewValidPositionFromFEN parses fen and rejects positions that cannot occur
+// in a game, see Validate.
+func NewValidPositionFromFEN(fen string) (*Position, error) {
+	pos, err := NewPositionFromFEN(fen)
+	if err != nil {
+		return nil, err
+	}
+	if err := pos.Validate(); err != nil {
+		return nil, err
+	}
+	return pos, nil
+}
+
+// Validate checks the position for setups NewPositionFromFEN accepts but no
+// game can reach: a king count other than one per side, pawns on the back
+// ranks, the side not to move in check, castle rights whose king or rook has
+// left its home square, and en passant squares no double push can explain.
+// The first problem found is returned as a *FENError.
+func (p *Position) Validate() error {
+	if err := p.validateKings(); err != nil {
+		return err
+	}
+	if err := p.validatePawns(); err != nil {
+		return err
+	}
+	if err := p.validateCastleRights(); err != nil {
+		return err
+	}
+	if err := p.validateEnPassant(); err != nil {
+		return err
+	}
+
+	opponentKingIdx := p.blackKingIdx
+	if p.activeColor == Black {
+		opponentKingIdx = p.whiteKingIdx
+	}
+	if p.isSquareAttackedBy(opponentKingIdx, p.activeColor) {
+		return &FENError{Field: FieldActiveColor, Square: opponentKingIdx, Err: ErrOpponentInCheck}
+	}
+
+	return nil
+}
+
+func (p *Position) validateKings() error {
+	for _, color := range [2]int8{White, Black} {
+		count := 0
+		square := NoSquare
+		for idx := int8(0); idx < 64; idx++ {
+			if p.board[idx] == Piece(color|King) {
+				count++
+				if count == 2 {
+					square = idx
+				}
+			}
+		}
+		if count != 1 {
+			return &FENError{Field: FieldPiecePlacement, Square: square, Err: ErrKingCount}
+		}
+	}
+	return nil
+}
+
+func (p *Position) validatePawns() error {
+	for idx := int8(0); idx < 64; idx++ {
+		rank := RankFromIdx(idx)
+		if p.board[idx].Type() == Pawn && (rank == 0 || rank == 7) {
+			return &FENError{Field: FieldPiecePlacement, Square: idx, Err: ErrPawnOnBackRank}
+		}
+	}
+	return nil
+}
+
+func (p *Position) validateCastleRights() error {
+	for _, color := range [2]int8{White, Black} {
+		rights := p.whiteCastleRights
+		kingIdx := p.whiteKingIdx
+		if color == Black {
+			rights = p.blackCastleRights
+			kingIdx = p.blackKingIdx
+		}
+		if rights == NoCastle {
+			continue
+		}
+
+		homeKingIdx := backRank(color)*8 + 4
+		if RankFromIdx(kingIdx) != backRank(color) || (!p.chess960 && kingIdx != homeKingIdx) {
+			square := kingIdx
+			if !p.chess960 {
+				square = homeKingIdx
+			}
+			return &FENError{Field: FieldCastling, Square: square, Err: ErrCastleWithoutKing}
+		}
+
+		for _, side := range [2]int8{KingSideCastle, QueenSideCastle} {
+			if rights&side == 0 {
+				continue
+			}
+			rookIdx := p.CastleRookIdx(color, side)
+			onKingSide := rookIdx > kingIdx
+			if p.board[rookIdx] != Piece(color|Rook) || onKingSide != (side == KingSideCastle) {
+				return &FENError{Field: FieldCastling, Square: rookIdx, Err: ErrCastleWithoutRook}
+			}
+		}
+	}
+	return nil
+}
+
+// validateEnPassant requires the pawn that just made a double push in front
+// of the en passant square, with the square and the pawn's origin empty.
+func (p *Position) validateEnPassant() error {
+	if p.enPassantIdx == NoEnPassant {
+		return nil
+	}
+
+	epRank, pushedIdx, originIdx := int8(5), p.enPassantIdx-8, p.enPassantIdx+8
+	pushedPawn := Piece(Black | Pawn)
+	if p.activeColor == Black {
+		epRank, pushedIdx, originIdx = 2, p.enPassantIdx+8, p.enPassantIdx-8
+		pushedPawn = Piece(White | Pawn)
+	}
+
+	if RankFromIdx(p.enPassantIdx) != epRank ||
+		p.board[p.enPassantIdx] != NoPiece ||
+		p.board[originIdx] != NoPiece ||
+		p.board[pushedIdx] != pushedPawn {
+		return &FENError{Field: FieldEnPassant, Square: p.enPassantIdx, Err: ErrInvalidEnPassant}
+	}
+	return nil
+}
+
+// isSquareAttackedBy walks the mailbox board. It is meant for validation,
+// move generation has its own bitboard attack tables.
+func (p *Position) isSquareAttackedBy(idx int8, color int8) bool {
+	file, rank := FileFromIdx(idx), RankFromIdx(idx)
+
+	pawnRank := rank - 1
+	if color == Black {
+		pawnRank = rank + 1
+	}
+	for _, df := range [2]int8{-1, 1} {
+		if isOnBoard(file+df, pawnRank) && p.board[pawnRank*8+file+df] == Piece(color|Pawn) {
+			return true
+		}
+	}
+
+	for _, d := range [8][2]int8{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}} {
+		if isOnBoard(file+d[0], rank+d[1]) && p.board[(rank+d[1])*8+file+d[0]] == Piece(color|Knight) {
+			return true
+		}
+	}
+
+	for _, d := range [8][2]int8{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {1, -1}, {-1, 1}, {-1, -1}} {
+		diagonal := d[0] != 0 && d[1] != 0
+		for step := int8(1); isOnBoard(file+d[0]*step, rank+d[1]*step); step++ {
+			piece := p.board[(rank+d[1]*step)*8+file+d[0]*step]
+			if piece == NoPiece {
+				continue
+			}
+			if piece.Color() == color {
+				switch piece.Type() {
+				case Queen:
+					return true
+				case Bishop:
+					if diagonal {
+						return true
+					}
+				case Rook:
+					if !diagonal {
+						return true
+					}
+				case King:
+					if step == 1 {
+						return true
+					}
+				}
+			}
+			break
+		}
+	}
+
+	return false
+}
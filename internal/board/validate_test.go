@@ -0,0 +1,169 @@
+package board
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAcceptsLegalPositions(t *testing.T) {
+	data := []string{
+		FenStartPos,
+		"r3k2r/p1ppqpb1/bn2pnp1/2PN4/1p2P3/2N2Q1p/PPPB2PP/R3K2R w KQkq - 0 1",
+		"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+		"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+		"bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9",
+		"4k3/8/8/8/8/8/8/4K2R b K - 0 1",
+	}
+
+	for _, fen := range data {
+		pos, err := NewValidPositionFromFEN(fen)
+		assert.NoError(t, err, fen)
+		if pos != nil {
+			assert.Equal(t, fen, pos.FEN())
+		}
+	}
+}
+
+func TestValidateRejectsIllegalPositions(t *testing.T) {
+	data := map[string]struct {
+		fen    string
+		field  FENField
+		square int8
+		err    error
+	}{
+		"missing black king": {
+			fen:    "8/8/8/8/8/8/8/4K3 w - - 0 1",
+			field:  FieldPiecePlacement,
+			square: NoSquare,
+			err:    ErrKingCount,
+		},
+		"two white kings": {
+			fen:    "4k3/8/8/8/8/8/8/K3K3 w - - 0 1",
+			field:  FieldPiecePlacement,
+			square: E1,
+			err:    ErrKingCount,
+		},
+		"white pawn on the last rank": {
+			fen:    "3Pk3/8/8/8/8/8/8/4K3 w - - 0 1",
+			field:  FieldPiecePlacement,
+			square: D8,
+			err:    ErrPawnOnBackRank,
+		},
+		"black pawn on the first rank": {
+			fen:    "4k3/8/8/8/8/8/8/p3K3 b - - 0 1",
+			field:  FieldPiecePlacement,
+			square: A1,
+			err:    ErrPawnOnBackRank,
+		},
+		"black king attacked by a rook": {
+			fen:    "4k3/8/8/8/8/8/8/3KR3 w - - 0 1",
+			field:  FieldActiveColor,
+			square: E8,
+			err:    ErrOpponentInCheck,
+		},
+		"black king attacked by a pawn": {
+			fen:    "4k3/3P4/8/8/8/8/8/4K3 w - - 0 1",
+			field:  FieldActiveColor,
+			square: E8,
+			err:    ErrOpponentInCheck,
+		},
+		"white king attacked by a knight": {
+			fen:    "4k3/8/8/8/8/8/2n5/4K3 b - - 0 1",
+			field:  FieldActiveColor,
+			square: E1,
+			err:    ErrOpponentInCheck,
+		},
+		"castle rights with the king moved": {
+			fen:    "4k3/8/8/8/8/8/4K3/7R w K - 0 1",
+			field:  FieldCastling,
+			square: E1,
+			err:    ErrCastleWithoutKing,
+		},
+		"castle rights with the rook missing": {
+			fen:    "4k3/8/8/8/8/8/8/4K3 w q - 0 1",
+			field:  FieldCastling,
+			square: A8,
+			err:    ErrCastleWithoutRook,
+		},
+		"castle rights with the rook captured": {
+			fen:    "r3k2r/8/8/8/8/8/8/R3K2n w KQkq - 0 1",
+			field:  FieldCastling,
+			square: H1,
+			err:    ErrCastleWithoutRook,
+		},
+		"en passant on the wrong rank": {
+			fen:    "4k3/8/8/8/4P3/8/8/4K3 w - e4 0 1",
+			field:  FieldEnPassant,
+			square: E4,
+			err:    ErrInvalidEnPassant,
+		},
+		"en passant without a pushed pawn": {
+			fen:    "4k3/8/8/8/8/8/8/4K3 w - d6 0 1",
+			field:  FieldEnPassant,
+			square: D6,
+			err:    ErrInvalidEnPassant,
+		},
+		"en passant with the origin occupied": {
+			fen:    "4k3/3p4/8/3p4/8/8/8/4K3 w - d6 0 1",
+			field:  FieldEnPassant,
+			square: D6,
+			err:    ErrInvalidEnPassant,
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewValidPositionFromFEN(d.fen)
+
+			var fenErr *FENError
+			if assert.True(t, errors.As(err, &fenErr), "unexpected error: %v", err) {
+				assert.Equal(t, d.field, fenErr.Field)
+				assert.Equal(t, d.square, fenErr.Square)
+			}
+			assert.True(t, errors.Is(err, d.err), "unexpected error: %v", err)
+			assert.True(t, errors.Is(err, ErrInvalidFEN))
+		})
+	}
+}
+
+func TestNewPositionFromFENRejectsMalformedFields(t *testing.T) {
+	data := map[string]struct {
+		fen   string
+		field FENField
+		err   error
+	}{
+		"too few fields":       {fen: "8/8/8/8/8/8/8/8 w -", err: ErrFieldCount},
+		"too many files":       {fen: "9/8/8/8/8/8/8/8 w - - 0 1", field: FieldPiecePlacement, err: ErrMalformedFEN},
+		"rank overflow":        {fen: "4k4/8/8/8/8/8/8/4K3 w - - 0 1", field: FieldPiecePlacement, err: ErrMalformedFEN},
+		"too few ranks":        {fen: "4k3/8/8/8/8/8/4K3 w - - 0 1", field: FieldPiecePlacement, err: ErrMalformedFEN},
+		"too many ranks":       {fen: "4k3/8/8/8/8/8/8/8/4K3 w - - 0 1", field: FieldPiecePlacement, err: ErrMalformedFEN},
+		"unknown piece":        {fen: "4k3/8/8/8/8/8/8/4X3 w - - 0 1", field: FieldPiecePlacement, err: ErrMalformedFEN},
+		"unknown color":        {fen: "4k3/8/8/8/8/8/8/4K3 x - - 0 1", field: FieldActiveColor, err: ErrMalformedFEN},
+		"unknown castle right": {fen: "4k3/8/8/8/8/8/8/4K3 w Z - 0 1", field: FieldCastling, err: ErrMalformedFEN},
+		"bad en passant":       {fen: "4k3/8/8/8/8/8/8/4K3 w - z9 0 1", field: FieldEnPassant, err: ErrMalformedFEN},
+		"negative halfmove":    {fen: "4k3/8/8/8/8/8/8/4K3 w - - -1 1", field: FieldHalfMoveClock, err: ErrMalformedFEN},
+		"zero fullmove":        {fen: "4k3/8/8/8/8/8/8/4K3 w - - 0 0", field: FieldFullMoveNumber, err: ErrMalformedFEN},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewPositionFromFEN(d.fen)
+
+			var fenErr *FENError
+			if assert.True(t, errors.As(err, &fenErr), "unexpected error: %v", err) {
+				assert.Equal(t, d.field, fenErr.Field)
+			}
+			assert.True(t, errors.Is(err, d.err), "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestFENErrorMessage(t *testing.T) {
+	_, err := NewValidPositionFromFEN("4k3/8/8/8/8/8/8/4K3 w q - 0 1")
+	assert.EqualError(t, err, "invalid FEN: castling rights: castle rights without the rook on its home square on a8")
+
+	_, err = NewPositionFromFEN("4k3/8/8/8/8/8/8/4K3 x - - 0 1")
+	assert.EqualError(t, err, `invalid FEN: active color: malformed field ("x")`)
+}
@@ -1,10 +1,15 @@
 package board
 
+import "math/bits"
+
 var (
 	zobristPieceTable [32][64]uint64
 	zobristCastle     [16]uint64
 	zobristEnPassant  [65]uint64
 	zobristSideToMove uint64
+	// zobristMaterial[piece][n] is in the material key while at least n+1
+	// such pieces are on the board, so the key identifies the piece counts.
+	zobristMaterial [32][16]uint64
 )
 
 func init() {
@@ -29,6 +34,11 @@ func init() {
 		zobristEnPassant[i] = next()
 	}
 	zobristSideToMove = next()
+	for p := range zobristMaterial {
+		for n := range zobristMaterial[p] {
+			zobristMaterial[p][n] = next()
+		}
+	}
 }
 
 func zobristPieceKey(piece Piece, idx int8) uint64 {
@@ -67,3 +77,59 @@ func computeZobristKey(pos *Position) uint64 {
 
 	return key
 }
+
+// computePawnKey hashes the pawns alone, with the same piece-square keys as
+// the position key.
+func computePawnKey(pos *Position) uint64 {
+	var key uint64
+	for idx, piece := range pos.board {
+		if piece != NoPiece && piece.Type() == Pawn {
+			key ^= zobristPieceKey(piece, int8(idx))
+		}
+	}
+	return key
+}
+
+// computeMaterialKey hashes how many pieces of each kind are on the board,
+// wherever they stand.
+func computeMaterialKey(pos *Position) uint64 {
+	var key uint64
+	for _, color := range [2]int8{White, Black} {
+		for _, pieceType := range [6]int8{King, Queen, Pawn, Knight, Bishop, Rook} {
+			piece := Piece(color | pieceType)
+			for n := 0; n < pieceCount(pos, piece); n++ {
+				key ^= zobristMaterialKey(piece, n)
+			}
+		}
+	}
+	return key
+}
+
+func zobristMaterialKey(piece Piece, n int) uint64 {
+	return zobristMaterial[int(piece)][n&15]
+}
+
+// pieceCount counts the pieces of one kind from the bitboards.
+func pieceCount(pos *Position, piece Piece) int {
+	colorMask := pos.whiteOccupied
+	if piece.Color() == Black {
+		colorMask = pos.blackOccupied
+	}
+
+	var pieceMask uint64
+	switch piece.Type() {
+	case King:
+		pieceMask = pos.kingBoard
+	case Queen:
+		pieceMask = pos.queenBoard
+	case Rook:
+		pieceMask = pos.rookBoard
+	case Bishop:
+		pieceMask = pos.bishopBoard
+	case Knight:
+		pieceMask = pos.knightBoard
+	case Pawn:
+		pieceMask = pos.pawnBoard
+	}
+	return bits.OnesCount64(pieceMask & colorMask)
+}
@@ -0,0 +1,231 @@
+package book
+
+import (
+	board "chessV2/internal/board"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+)
+
+// entrySize is the size of one Polyglot book entry: an 8-byte key, a 2-byte
+// move, a 2-byte weight and 4 learn bytes, all big-endian.
+const entrySize = 16
+
+// Entry is one Polyglot book entry. Move uses the Polyglot encoding, see
+// EncodeMove.
+type Entry struct {
+	Key    uint64
+	Move   uint16
+	Weight uint16
+	Learn  uint32
+}
+
+// Book holds the entries of a Polyglot book sorted by key.
+type Book struct {
+	entries []Entry
+}
+
+// Candidate is a book move resolved against the legal moves of a position.
+type Candidate struct {
+	Move   board.Move
+	Weight uint16
+}
+
+// Selection chooses how Pick draws a move from the book candidates.
+type Selection int
+
+const (
+	// SelectWeighted draws a move with probability proportional to its weight.
+	SelectWeighted Selection = iota
+	// SelectBest plays the move with the highest weight.
+	SelectBest
+	// SelectUniform draws any book move with equal probability.
+	SelectUniform
+)
+
+func (s Selection) String() string {
+	switch s {
+	case SelectBest:
+		return "best"
+	case SelectUniform:
+		return "uniform"
+	}
+	return "weighted"
+}
+
+// ParseSelection reads a selection mode by the name String returns.
+func ParseSelection(name string) (Selection, error) {
+	for _, s := range [3]Selection{SelectWeighted, SelectBest, SelectUniform} {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+	return SelectWeighted, fmt.Errorf("unknown book selection: %s", name)
+}
+
+// Load reads a Polyglot .bin book from path.
+func Load(path string) (*Book, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Read(file)
+}
+
+// Read parses a Polyglot book. Entries are sorted by key if the file is not.
+func Read(r io.Reader) (*Book, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%entrySize != 0 {
+		return nil, fmt.Errorf("book size %d is not a multiple of %d bytes", len(data), entrySize)
+	}
+
+	entries := make([]Entry, len(data)/entrySize)
+	for i := range entries {
+		raw := data[i*entrySize:]
+		entries[i] = Entry{
+			Key:    binary.BigEndian.Uint64(raw[0:8]),
+			Move:   binary.BigEndian.Uint16(raw[8:10]),
+			Weight: binary.BigEndian.Uint16(raw[10:12]),
+			Learn:  binary.BigEndian.Uint32(raw[12:16]),
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+
+	return &Book{entries: entries}, nil
+}
+
+// Write stores entries as a Polyglot book: sorted by key, and by descending
+// weight within a key as other Polyglot tools expect.
+func Write(w io.Writer, entries []Entry) error {
+	sorted := make([]Entry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Key != sorted[j].Key {
+			return sorted[i].Key < sorted[j].Key
+		}
+		return sorted[i].Weight > sorted[j].Weight
+	})
+
+	buf := make([]byte, len(sorted)*entrySize)
+	for i, entry := range sorted {
+		raw := buf[i*entrySize:]
+		binary.BigEndian.PutUint64(raw[0:8], entry.Key)
+		binary.BigEndian.PutUint16(raw[8:10], entry.Move)
+		binary.BigEndian.PutUint16(raw[10:12], entry.Weight)
+		binary.BigEndian.PutUint32(raw[12:16], entry.Learn)
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+// Len returns the number of entries in the book.
+func (b *Book) Len() int {
+	return len(b.entries)
+}
+
+// Entries returns the entries stored for a Polyglot key.
+func (b *Book) Entries(key uint64) []Entry {
+	start := sort.Search(len(b.entries), func(i int) bool {
+		return b.entries[i].Key >= key
+	})
+	end := start
+	for end < len(b.entries) && b.entries[end].Key == key {
+		end++
+	}
+	return b.entries[start:end]
+}
+
+// Moves returns the book moves of pos that are in legalMoves, in book order.
+// Entries that match no legal move, which a key collision can produce, are
+// dropped.
+func (b *Book) Moves(pos *board.Position, legalMoves []board.Move) []Candidate {
+	entries := b.Entries(pos.PolyglotKey())
+	if len(entries) == 0 {
+		return nil
+	}
+
+	candidates := make([]Candidate, 0, len(entries))
+	for _, entry := range entries {
+		for _, move := range legalMoves {
+			if EncodeMove(pos, move) == entry.Move {
+				candidates = append(candidates, Candidate{Move: move, Weight: entry.Weight})
+				break
+			}
+		}
+	}
+	return candidates
+}
+
+// Pick chooses a book move for pos. Moves with a zero weight are never
+// played. It reports false when the book has no playable move.
+func (b *Book) Pick(pos *board.Position, legalMoves []board.Move, selection Selection, rng *rand.Rand) (board.Move, bool) {
+	candidates := b.Moves(pos, legalMoves)
+
+	playable := candidates[:0]
+	total := 0
+	for _, candidate := range candidates {
+		if candidate.Weight > 0 {
+			playable = append(playable, candidate)
+			total += int(candidate.Weight)
+		}
+	}
+	if len(playable) == 0 {
+		return board.NoMove, false
+	}
+
+	switch selection {
+	case SelectBest:
+		best := playable[0]
+		for _, candidate := range playable[1:] {
+			if candidate.Weight > best.Weight {
+				best = candidate
+			}
+		}
+		return best.Move, true
+	case SelectUniform:
+		return playable[rng.Intn(len(playable))].Move, true
+	}
+
+	pick := rng.Intn(total)
+	for _, candidate := range playable {
+		pick -= int(candidate.Weight)
+		if pick < 0 {
+			return candidate.Move, true
+		}
+	}
+	return playable[len(playable)-1].Move, true
+}
+
+// EncodeMove returns the Polyglot encoding of move: the destination file and
+// rank in bits 0-5, the origin in bits 6-11 and the promotion piece in bits
+// 12-14 (knight 1, bishop 2, rook 3, queen 4). Castles are written as the king
+// capturing its own rook, e1h1 rather than e1g1.
+func EncodeMove(pos *board.Position, move board.Move) uint16 {
+	endIdx := move.EndIdx()
+	if move.Flag() == board.Castle {
+		_, endIdx, _ = pos.CastleMoveSquares(move)
+	}
+
+	var promotion uint16
+	switch move.Flag() {
+	case board.KnightPromotion:
+		promotion = 1
+	case board.BishopPromotion:
+		promotion = 2
+	case board.RookPromotion:
+		promotion = 3
+	case board.QueenPromotion:
+		promotion = 4
+	}
+
+	return uint16(endIdx) | uint16(move.StartIdx())<<6 | promotion<<12
+}
@@ -0,0 +1,238 @@
+package book
+
+import (
+	"bytes"
+	board "chessV2/internal/board"
+	"chessV2/internal/movegen"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func legalMoves(t *testing.T, pos *board.Position) []board.Move {
+	t.Helper()
+	var buf [256]board.Move
+	count := movegen.NewPseudoLegalMoveGenerator().LegalMovesInto(pos, board.NewPositionUpdater(), buf[:])
+	return buf[:count]
+}
+
+func findMove(t *testing.T, pos *board.Position, uci string) board.Move {
+	t.Helper()
+	for _, move := range legalMoves(t, pos) {
+		if move.UCI() == uci {
+			return move
+		}
+	}
+	t.Fatalf("illegal move %s", uci)
+	return board.NoMove
+}
+
+// startBook writes a small book for the starting position: e2e4 weighted 3,
+// d2d4 weighted 1, c2c4 weighted 0 and one entry that is not a legal move.
+func startBook(t *testing.T) *Book {
+	t.Helper()
+	pos, err := board.NewPositionFromFEN(board.FenStartPos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := pos.PolyglotKey()
+
+	var buf bytes.Buffer
+	err = Write(&buf, []Entry{
+		{Key: key, Move: EncodeMove(pos, findMove(t, pos, "d2d4")), Weight: 1},
+		{Key: key, Move: EncodeMove(pos, findMove(t, pos, "e2e4")), Weight: 3},
+		{Key: key, Move: EncodeMove(pos, findMove(t, pos, "c2c4")), Weight: 0},
+		{Key: key, Move: uint16(board.E4) | uint16(board.E1)<<6, Weight: 9},
+		{Key: key + 1, Move: EncodeMove(pos, findMove(t, pos, "g1f3")), Weight: 5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestEncodeMove(t *testing.T) {
+	data := map[string]struct {
+		fen      string
+		uci      string
+		chess960 bool
+		expected uint16
+	}{
+		"pawn double push": {
+			fen:      board.FenStartPos,
+			uci:      "e2e4",
+			expected: uint16(board.E4) | uint16(board.E2)<<6,
+		},
+		"white king side castle as king takes rook": {
+			fen:      "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			uci:      "e1g1",
+			expected: uint16(board.H1) | uint16(board.E1)<<6,
+		},
+		"black queen side castle as king takes rook": {
+			fen:      "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1",
+			uci:      "e8c8",
+			expected: uint16(board.A8) | uint16(board.E8)<<6,
+		},
+		"chess960 castle already names the rook": {
+			fen:      "r3k2r/8/8/8/8/8/8/1R2K1R1 w GB - 0 1",
+			uci:      "e1g1",
+			chess960: true,
+			expected: uint16(board.G1) | uint16(board.E1)<<6,
+		},
+		"queen promotion": {
+			fen:      "8/P7/8/8/8/8/8/k6K w - - 0 1",
+			uci:      "a7a8q",
+			expected: uint16(board.A8) | uint16(board.A7)<<6 | 4<<12,
+		},
+		"knight promotion": {
+			fen:      "8/P7/8/8/8/8/8/k6K w - - 0 1",
+			uci:      "a7a8n",
+			expected: uint16(board.A8) | uint16(board.A7)<<6 | 1<<12,
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			pos, err := board.NewPositionFromFEN(d.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pos.SetChess960(d.chess960)
+
+			assert.Equal(t, d.expected, EncodeMove(pos, findMove(t, pos, d.uci)))
+		})
+	}
+}
+
+func TestBookFileRoundTrip(t *testing.T) {
+	entries := []Entry{
+		{Key: 7, Move: 3, Weight: 1, Learn: 42},
+		{Key: 2, Move: 1, Weight: 5},
+		{Key: 7, Move: 4, Weight: 9},
+	}
+	path := filepath.Join(t.TempDir(), "test.bin")
+
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, entries))
+	assert.Equal(t, len(entries)*entrySize, buf.Len())
+	assert.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+
+	b, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, b.Len())
+	assert.Equal(t, []Entry{{Key: 2, Move: 1, Weight: 5}}, b.Entries(2))
+	assert.Equal(t, []Entry{{Key: 7, Move: 4, Weight: 9}, {Key: 7, Move: 3, Weight: 1, Learn: 42}}, b.Entries(7))
+	assert.Empty(t, b.Entries(5))
+}
+
+func TestReadRejectsTruncatedBook(t *testing.T) {
+	_, err := Read(bytes.NewReader(make([]byte, entrySize+3)))
+	assert.Error(t, err)
+}
+
+func TestBookMovesSkipsIllegalEntries(t *testing.T) {
+	b := startBook(t)
+	pos, err := board.NewPositionFromFEN(board.FenStartPos)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var moves []string
+	for _, candidate := range b.Moves(pos, legalMoves(t, pos)) {
+		moves = append(moves, candidate.Move.UCI())
+	}
+	assert.Equal(t, []string{"e2e4", "d2d4", "c2c4"}, moves)
+}
+
+func TestBookPick(t *testing.T) {
+	data := map[string]struct {
+		selection Selection
+		allowed   []string
+	}{
+		"best weight": {
+			selection: SelectBest,
+			allowed:   []string{"e2e4"},
+		},
+		"weighted random": {
+			selection: SelectWeighted,
+			allowed:   []string{"e2e4", "d2d4"},
+		},
+		"uniform": {
+			selection: SelectUniform,
+			allowed:   []string{"e2e4", "d2d4"},
+		},
+	}
+
+	b := startBook(t)
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			pos, err := board.NewPositionFromFEN(board.FenStartPos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			legal := legalMoves(t, pos)
+			rng := rand.New(rand.NewSource(1))
+
+			seen := map[string]int{}
+			for i := 0; i < 400; i++ {
+				move, ok := b.Pick(pos, legal, d.selection, rng)
+				assert.True(t, ok)
+				seen[move.UCI()]++
+			}
+			for move := range seen {
+				assert.Contains(t, d.allowed, move)
+			}
+			assert.Len(t, seen, len(d.allowed))
+		})
+	}
+}
+
+func TestBookPickWeightsDraws(t *testing.T) {
+	b := startBook(t)
+	pos, err := board.NewPositionFromFEN(board.FenStartPos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legal := legalMoves(t, pos)
+	rng := rand.New(rand.NewSource(1))
+
+	e4 := 0
+	for i := 0; i < 4000; i++ {
+		move, _ := b.Pick(pos, legal, SelectWeighted, rng)
+		if move.UCI() == "e2e4" {
+			e4++
+		}
+	}
+	assert.InDelta(t, 3000, e4, 150)
+}
+
+func TestBookPickOutOfBook(t *testing.T) {
+	b := startBook(t)
+	pos, err := board.NewPositionFromFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	move, ok := b.Pick(pos, legalMoves(t, pos), SelectBest, rand.New(rand.NewSource(1)))
+	assert.False(t, ok)
+	assert.Equal(t, board.NoMove, move)
+}
+
+func TestParseSelection(t *testing.T) {
+	for _, s := range []Selection{SelectWeighted, SelectBest, SelectUniform} {
+		parsed, err := ParseSelection(s.String())
+		assert.NoError(t, err)
+		assert.Equal(t, s, parsed)
+	}
+
+	_, err := ParseSelection("random")
+	assert.Error(t, err)
+}
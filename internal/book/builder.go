@@ -0,0 +1,149 @@
+package book
+
+import (
+	board "chessV2/internal/board"
+	"sort"
+)
+
+// GameResult is the outcome of a game added to a Builder.
+type GameResult int
+
+const (
+	// ResultUnknown marks games that ended without a decisive or drawn
+	// position, such as adjudicated games. They count as half a point.
+	ResultUnknown GameResult = iota
+	ResultWhiteWins
+	ResultBlackWins
+	ResultDraw
+)
+
+// MoveStats counts the games in which a move was played, from the point of
+// view of the side that played it.
+type MoveStats struct {
+	Games   int
+	Wins    int
+	Draws   int
+	Losses  int
+	Unknown int
+}
+
+// Score returns the points scored with the move per game, between 0 and 1.
+func (s MoveStats) Score() float64 {
+	if s.Games == 0 {
+		return 0
+	}
+	return float64(2*s.Wins+s.Draws+s.Unknown) / float64(2*s.Games)
+}
+
+// weight is the Polyglot convention: two per win and one per draw.
+func (s MoveStats) weight() int {
+	return 2*s.Wins + s.Draws + s.Unknown
+}
+
+type builderKey struct {
+	key  uint64
+	move uint16
+}
+
+// Builder aggregates move statistics per position from whole games and turns
+// them into book entries.
+type Builder struct {
+	maxPly int
+	stats  map[builderKey]*MoveStats
+}
+
+// NewBuilder returns a builder that records the first maxPly moves of every
+// game.
+func NewBuilder(maxPly int) *Builder {
+	return &Builder{
+		maxPly: maxPly,
+		stats:  make(map[builderKey]*MoveStats),
+	}
+}
+
+// AddGame replays moves from start and records each of the first maxPly moves
+// with the game result. start is not modified.
+func (b *Builder) AddGame(start *board.Position, moves []board.Move, result GameResult) {
+	pos := start.Clone()
+	updater := board.NewPositionUpdater()
+
+	for ply, move := range moves {
+		if ply >= b.maxPly {
+			break
+		}
+		b.add(pos, move, result)
+		updater.MakeMove(pos, move)
+	}
+}
+
+func (b *Builder) add(pos *board.Position, move board.Move, result GameResult) {
+	k := builderKey{key: pos.PolyglotKey(), move: EncodeMove(pos, move)}
+	stats := b.stats[k]
+	if stats == nil {
+		stats = &MoveStats{}
+		b.stats[k] = stats
+	}
+
+	stats.Games++
+	switch result {
+	case ResultDraw:
+		stats.Draws++
+	case ResultUnknown:
+		stats.Unknown++
+	case ResultWhiteWins, ResultBlackWins:
+		if (result == ResultWhiteWins) == (pos.ActiveColor() == board.White) {
+			stats.Wins++
+		} else {
+			stats.Losses++
+		}
+	}
+}
+
+// Stats returns the statistics of the moves recorded for a Polyglot key, by
+// Polyglot move encoding.
+func (b *Builder) Stats(key uint64) map[uint16]MoveStats {
+	stats := make(map[uint16]MoveStats)
+	for k, s := range b.stats {
+		if k.key == key {
+			stats[k.move] = *s
+		}
+	}
+	return stats
+}
+
+// Entries returns the book entries of the moves played in at least minGames
+// games with a score of at least minScore. Weights follow the Polyglot
+// convention, two per win and one per draw, scaled down when the largest one
+// does not fit in 16 bits.
+func (b *Builder) Entries(minGames int, minScore float64) []Entry {
+	entries := make([]Entry, 0, len(b.stats))
+	weights := make([]int, 0, len(b.stats))
+	maxWeight := 0
+	for k, s := range b.stats {
+		if s.Games < minGames || s.Score() < minScore {
+			continue
+		}
+		entries = append(entries, Entry{Key: k.key, Move: k.move})
+		weights = append(weights, s.weight())
+		maxWeight = max(maxWeight, s.weight())
+	}
+
+	for i := range entries {
+		weight := weights[i]
+		if maxWeight > 0xffff {
+			weight = weight * 0xffff / maxWeight
+		}
+		entries[i].Weight = uint16(weight)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Key != entries[j].Key {
+			return entries[i].Key < entries[j].Key
+		}
+		if entries[i].Weight != entries[j].Weight {
+			return entries[i].Weight > entries[j].Weight
+		}
+		return entries[i].Move < entries[j].Move
+	})
+	return entries
+}
@@ -0,0 +1,163 @@
+package book
+
+import (
+	board "chessV2/internal/board"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// playMoves resolves UCI moves from the starting position.
+func playMoves(t *testing.T, ucis ...string) (*board.Position, []board.Move) {
+	t.Helper()
+	start, err := board.NewPositionFromFEN(board.FenStartPos)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pos := start.Clone()
+	updater := board.NewPositionUpdater()
+	moves := make([]board.Move, 0, len(ucis))
+	for _, uci := range ucis {
+		move := findMove(t, pos, uci)
+		moves = append(moves, move)
+		updater.MakeMove(pos, move)
+	}
+	return start, moves
+}
+
+func uciEncoding(t *testing.T, ucis ...string) (uint64, uint16) {
+	t.Helper()
+	start, moves := playMoves(t, ucis...)
+	pos := start.Clone()
+	updater := board.NewPositionUpdater()
+	for _, move := range moves[:len(moves)-1] {
+		updater.MakeMove(pos, move)
+	}
+	return pos.PolyglotKey(), EncodeMove(pos, moves[len(moves)-1])
+}
+
+func testBuilder(t *testing.T, maxPly int) *Builder {
+	t.Helper()
+	b := NewBuilder(maxPly)
+	for _, game := range []struct {
+		moves  []string
+		result GameResult
+	}{
+		{[]string{"e2e4", "e7e5", "g1f3"}, ResultWhiteWins},
+		{[]string{"e2e4", "c7c5"}, ResultDraw},
+		{[]string{"d2d4", "d7d5"}, ResultBlackWins},
+		{[]string{"d2d4", "g8f6"}, ResultUnknown},
+	} {
+		start, moves := playMoves(t, game.moves...)
+		b.AddGame(start, moves, game.result)
+	}
+	return b
+}
+
+func TestBuilderStats(t *testing.T) {
+	b := testBuilder(t, 2)
+
+	startKey, e4 := uciEncoding(t, "e2e4")
+	_, d4 := uciEncoding(t, "d2d4")
+	assert.Equal(t, map[uint16]MoveStats{
+		e4: {Games: 2, Wins: 1, Draws: 1},
+		d4: {Games: 2, Losses: 1, Unknown: 1},
+	}, b.Stats(startKey))
+
+	afterE4, e5 := uciEncoding(t, "e2e4", "e7e5")
+	_, c5 := uciEncoding(t, "e2e4", "c7c5")
+	assert.Equal(t, map[uint16]MoveStats{
+		e5: {Games: 1, Losses: 1},
+		c5: {Games: 1, Draws: 1},
+	}, b.Stats(afterE4))
+
+	afterE5, _ := uciEncoding(t, "e2e4", "e7e5", "g1f3")
+	assert.Empty(t, b.Stats(afterE5))
+}
+
+func TestBuilderEntries(t *testing.T) {
+	startKey, e4 := uciEncoding(t, "e2e4")
+	_, d4 := uciEncoding(t, "d2d4")
+	afterE4, e5 := uciEncoding(t, "e2e4", "e7e5")
+	_, c5 := uciEncoding(t, "e2e4", "c7c5")
+	afterD4, d5 := uciEncoding(t, "d2d4", "d7d5")
+	_, nf6 := uciEncoding(t, "d2d4", "g8f6")
+
+	data := map[string]struct {
+		maxPly   int
+		minGames int
+		minScore float64
+		expected []Entry
+	}{
+		"every move": {
+			maxPly: 2,
+			expected: []Entry{
+				{Key: startKey, Move: e4, Weight: 3},
+				{Key: startKey, Move: d4, Weight: 1},
+				{Key: afterE4, Move: c5, Weight: 1},
+				{Key: afterE4, Move: e5, Weight: 0},
+				{Key: afterD4, Move: d5, Weight: 2},
+				{Key: afterD4, Move: nf6, Weight: 1},
+			},
+		},
+		"first ply only": {
+			maxPly: 1,
+			expected: []Entry{
+				{Key: startKey, Move: e4, Weight: 3},
+				{Key: startKey, Move: d4, Weight: 1},
+			},
+		},
+		"minimum two games": {
+			maxPly:   2,
+			minGames: 2,
+			expected: []Entry{
+				{Key: startKey, Move: e4, Weight: 3},
+				{Key: startKey, Move: d4, Weight: 1},
+			},
+		},
+		"minimum score of a draw": {
+			maxPly:   2,
+			minScore: 0.5,
+			expected: []Entry{
+				{Key: startKey, Move: e4, Weight: 3},
+				{Key: afterE4, Move: c5, Weight: 1},
+				{Key: afterD4, Move: d5, Weight: 2},
+				{Key: afterD4, Move: nf6, Weight: 1},
+			},
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			entries := testBuilder(t, d.maxPly).Entries(d.minGames, d.minScore)
+			assert.ElementsMatch(t, d.expected, entries)
+			for i := 1; i < len(entries); i++ {
+				assert.LessOrEqual(t, entries[i-1].Key, entries[i].Key)
+			}
+		})
+	}
+}
+
+func TestBuilderScalesLargeWeights(t *testing.T) {
+	b := NewBuilder(1)
+	start, e4 := playMoves(t, "e2e4")
+	_, d4 := playMoves(t, "d2d4")
+	for i := 0; i < 40000; i++ {
+		b.AddGame(start, e4, ResultWhiteWins)
+	}
+	for i := 0; i < 20000; i++ {
+		b.AddGame(start, d4, ResultWhiteWins)
+	}
+
+	entries := b.Entries(1, 0)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, uint16(0xffff), entries[0].Weight)
+	assert.Equal(t, uint16(0xffff/2), entries[1].Weight)
+}
+
+func TestMoveStatsScore(t *testing.T) {
+	assert.Equal(t, 0.0, MoveStats{}.Score())
+	assert.Equal(t, 0.75, MoveStats{Games: 2, Wins: 1, Draws: 1}.Score())
+	assert.Equal(t, 0.25, MoveStats{Games: 2, Losses: 1, Unknown: 1}.Score())
+}
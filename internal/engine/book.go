@@ -0,0 +1,46 @@
+package engine
+
+import (
+	board "chessV2/internal/board"
+	"chessV2/internal/book"
+)
+
+// DefaultBookDepth is the number of plies from the start of the game during
+// which the opening book is consulted.
+const DefaultBookDepth = 20
+
+// SetBook sets the opening book Search plays from before searching. A nil
+// book turns book moves off.
+func (e *Engine) SetBook(b *book.Book) {
+	e.book = b
+}
+
+// SetBookDepth sets the last game ply, counted from the FEN move number, at
+// which the book is still consulted.
+func (e *Engine) SetBookDepth(plies int) {
+	e.bookDepth = max(plies, 0)
+}
+
+// SetBookSelection sets how a move is chosen among the book moves.
+func (e *Engine) SetBookSelection(selection book.Selection) {
+	e.bookSelection = selection
+}
+
+// BookMove returns a book move for pos, or false when there is no book, pos
+// is past the book depth or the book has no playable move.
+func (e *Engine) BookMove(pos *board.Position) (board.Move, bool) {
+	if e.book == nil || gamePly(pos) >= e.bookDepth {
+		return board.NoMove, false
+	}
+	return e.book.Pick(pos, e.LegalMoves(pos), e.bookSelection, e.bookRand)
+}
+
+// gamePly returns the number of plies played before pos, from its move
+// number and side to move.
+func gamePly(pos *board.Position) int {
+	ply := (pos.FullMoveNumber() - 1) * 2
+	if pos.ActiveColor() == board.Black {
+		ply++
+	}
+	return ply
+}
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"bytes"
+	board "chessV2/internal/board"
+	"chessV2/internal/book"
+	"chessV2/internal/search"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testBook returns a book that answers the starting position with a2a3, a
+// move the search would not choose at depth 1.
+func testBook(t *testing.T, e *Engine) *book.Book {
+	t.Helper()
+	pos, err := board.NewPositionFromFEN(board.FenStartPos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	move, err := e.FindMoveByUCI(pos, "a2a3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err = book.Write(&buf, []book.Entry{{Key: pos.PolyglotKey(), Move: book.EncodeMove(pos, move), Weight: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := book.Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestEngineSearchPlaysBookMoves(t *testing.T) {
+	data := map[string]struct {
+		fen       string
+		useBook   bool
+		bookDepth int
+		expected  string
+	}{
+		"book move from the starting position": {
+			fen:       board.FenStartPos,
+			useBook:   true,
+			bookDepth: DefaultBookDepth,
+			expected:  "a2a3",
+		},
+		"no book": {
+			fen:       board.FenStartPos,
+			useBook:   false,
+			bookDepth: DefaultBookDepth,
+		},
+		"book depth zero": {
+			fen:       board.FenStartPos,
+			useBook:   true,
+			bookDepth: 0,
+		},
+		"position past the book depth": {
+			fen:       "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 11",
+			useBook:   true,
+			bookDepth: DefaultBookDepth,
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			e := NewEngine()
+			if d.useBook {
+				e.SetBook(testBook(t, e))
+			}
+			e.SetBookDepth(d.bookDepth)
+			e.SetBookSelection(book.SelectBest)
+
+			pos, err := board.NewPositionFromFEN(d.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := e.Search(pos, search.Limits{Depth: 1})
+			assert.NoError(t, err)
+			if d.expected != "" {
+				assert.Equal(t, d.expected, result.BestMove.UCI())
+				assert.Equal(t, 0, result.Stats.Depth)
+				assert.True(t, result.Book)
+				return
+			}
+			assert.NotEqual(t, "a2a3", result.BestMove.UCI())
+			assert.Equal(t, 1, result.Stats.Depth)
+			assert.False(t, result.Book)
+		})
+	}
+}
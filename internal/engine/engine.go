@@ -2,10 +2,12 @@ package engine
 
 import (
 	board "chessV2/internal/board"
+	"chessV2/internal/book"
 	"chessV2/internal/eval"
 	"chessV2/internal/movegen"
 	"chessV2/internal/search"
 	"fmt"
+	"math/rand"
 	"time"
 )
 
@@ -15,6 +17,11 @@ type Engine struct {
 	evaluator       eval.Evaluator
 	searcher        search.Searcher
 	usePerftTricks  bool
+	perftThreads    int
+	book            *book.Book
+	bookDepth       int
+	bookSelection   book.Selection
+	bookRand        *rand.Rand
 }
 
 const (
@@ -35,6 +42,9 @@ func NewEngine() *Engine {
 		evaluator:       evaluator,
 		searcher:        searcher,
 		usePerftTricks:  true,
+		perftThreads:    1,
+		bookDepth:       DefaultBookDepth,
+		bookRand:        rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
@@ -56,7 +66,7 @@ func (e *Engine) Move() {}
 func (e *Engine) BestMoveDepth(pos *board.Position, depth int) (board.Move, error) {
 	result, err := e.Search(pos, search.Limits{Depth: depth})
 	if err != nil {
-		return board.Move{}, err
+		return board.NoMove, err
 	}
 	return result.BestMove, nil
 }
@@ -68,7 +78,7 @@ func (e *Engine) SearchDepth(pos *board.Position, depth int) (search.Result, err
 func (e *Engine) BestMoveTime(pos *board.Position, moveTime time.Duration) (board.Move, error) {
 	result, err := e.Search(pos, search.Limits{MoveTime: moveTime})
 	if err != nil {
-		return board.Move{}, err
+		return board.NoMove, err
 	}
 	return result.BestMove, nil
 }
@@ -77,7 +87,12 @@ func (e *Engine) SearchTime(pos *board.Position, moveTime time.Duration) (search
 	return e.Search(pos, search.Limits{MoveTime: moveTime})
 }
 
+// Search plays a book move when the opening book has one for pos, marking the
+// result with Book, and searches otherwise.
 func (e *Engine) Search(pos *board.Position, limits search.Limits) (search.Result, error) {
+	if move, ok := e.BookMove(pos); ok {
+		return search.Result{BestMove: move, PV: []board.Move{move}, Book: true}, nil
+	}
 	return e.searcher.Search(pos, limits)
 }
 
@@ -88,7 +103,7 @@ func (e *Engine) FindMoveByUCI(pos *board.Position, uci string) (board.Move, err
 			return move, nil
 		}
 	}
-	return board.Move{}, fmt.Errorf("illegal move: %s", uci)
+	return board.NoMove, fmt.Errorf("illegal move: %s", uci)
 }
 
 func (e *Engine) ApplyUCIMove(pos *board.Position, uci string) error {
@@ -100,6 +115,27 @@ func (e *Engine) ApplyUCIMove(pos *board.Position, uci string) error {
 	return nil
 }
 
+func (e *Engine) ApplyMove(pos *board.Position, move board.Move) {
+	e.positionUpdater.MakeMove(pos, move)
+}
+
+func (e *Engine) MoveSAN(pos *board.Position, move board.Move) string {
+	return e.moveGenerator.SAN(pos, e.positionUpdater, move)
+}
+
+func (e *Engine) FindMoveBySAN(pos *board.Position, san string) (board.Move, error) {
+	return e.moveGenerator.ParseSAN(pos, e.positionUpdater, san)
+}
+
+func (e *Engine) ApplySANMove(pos *board.Position, san string) error {
+	move, err := e.FindMoveBySAN(pos, san)
+	if err != nil {
+		return err
+	}
+	e.positionUpdater.MakeMove(pos, move)
+	return nil
+}
+
 func (e *Engine) ApplyUCIMoves(pos *board.Position, moves []string) error {
 	for _, uci := range moves {
 		if err := e.ApplyUCIMove(pos, uci); err != nil {
@@ -129,6 +165,10 @@ func (e *Engine) LegalMoves(pos *board.Position) []board.Move {
 }
 
 func (e *Engine) PerftDivide(pos *board.Position, depth int) (map[string]uint64, uint64) {
+	if e.perftThreads > 1 && depth > 1 {
+		return e.perftDivideParallel(pos, depth)
+	}
+
 	var moveBuffers [MaxPerftPly][MaxLegalMoves]board.Move
 	var tt *perftTT
 	if e.usePerftTricks {
@@ -152,6 +192,11 @@ func (e *Engine) PerftDivide(pos *board.Position, depth int) (map[string]uint64,
 }
 
 func (e *Engine) MoveGenerationTest(pos *board.Position, depth int) uint64 {
+	if e.perftThreads > 1 && depth > 2 {
+		_, total := e.perftDivideParallel(pos, depth-1)
+		return total
+	}
+
 	var moveBuffers [MaxPerftPly][MaxLegalMoves]board.Move
 	var tt *perftTT
 	if e.usePerftTricks {
@@ -188,6 +188,65 @@ func TestEngine_LegalMoves_KnownPositionCounts(t *testing.T) {
 	}
 }
 
+func TestEngine_LegalMoves_Chess960Castles(t *testing.T) {
+	data := map[string]struct {
+		fen             string
+		expectedCastles []string
+	}{
+		"standard squares in Shredder-FEN": {
+			fen:             "r3k2r/8/8/8/8/8/8/R3K2R w HAha - 0 1",
+			expectedCastles: []string{"e1h1", "e1a1"},
+		},
+		"king does not move": {
+			fen:             "4k3/8/8/8/8/8/8/1RK5 w B - 0 1",
+			expectedCastles: []string{"c1b1"},
+		},
+		"castling rook pinned on the back rank": {
+			fen:             "4k3/8/8/8/8/8/8/qRK5 w B - 0 1",
+			expectedCastles: []string{},
+		},
+		"rook destination attacked": {
+			fen:             "3rk3/8/8/8/8/8/8/RK6 w A - 0 1",
+			expectedCastles: []string{"b1a1"},
+		},
+		"king destination attacked": {
+			fen:             "2r1k3/8/8/8/8/8/8/RK6 w A - 0 1",
+			expectedCastles: []string{},
+		},
+		"king destination occupied": {
+			fen:             "4k3/8/8/8/8/8/8/RKN5 w A - 0 1",
+			expectedCastles: []string{},
+		},
+		"rook passes the king": {
+			fen:             "4k3/8/8/8/8/8/8/5KR1 w G - 0 1",
+			expectedCastles: []string{"f1g1"},
+		},
+	}
+
+	engine := NewEngine()
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			pos, err := NewPositionFromFEN(d.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			castles := []string{}
+			for _, move := range engine.LegalMoves(pos) {
+				if move.Flag() == Castle {
+					castles = append(castles, move.UCI())
+				}
+			}
+			assert.ElementsMatch(t, d.expectedCastles, castles)
+
+			for _, uci := range d.expectedCastles {
+				assert.NoError(t, engine.ApplyUCIMove(pos.Clone(), uci))
+			}
+		})
+	}
+}
+
 func TestEngine_LegalMoves_NeverCaptureEnemyKing(t *testing.T) {
 	data := map[string]struct {
 		fen             string
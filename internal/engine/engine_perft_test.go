@@ -66,6 +66,54 @@ func TestEngine_MoveGenerationTest_Regressions(t *testing.T) {
 	}
 }
 
+func TestEngine_MoveGenerationTest_Chess960(t *testing.T) {
+	data := map[string]struct {
+		fen      string
+		depth    int
+		expected uint64
+	}{
+		"Rooks on f and h files depth 4": {
+			fen:      "bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9",
+			depth:    4,
+			expected: 12189,
+		},
+		"Rooks on e and h files depth 4": {
+			fen:      "2nnrbkr/p1qppppp/8/1ppb4/6PP/3PP3/PPP2P2/BQNNRBKR w HEhe - 1 9",
+			depth:    4,
+			expected: 18002,
+		},
+		"Rooks next to the king depth 4": {
+			fen:      "b1q1rrkb/pppppppp/3nn3/8/P7/1PPP4/4PPPP/BQNNRKRB w GE - 1 9",
+			depth:    4,
+			expected: 10471,
+		},
+		"Black rights only depth 4": {
+			fen:      "qbbnnrkr/2pp2pp/p7/1p2pp2/8/P3PP2/1PPP1KPP/QBBNNR1R w hf - 0 9",
+			depth:    4,
+			expected: 13440,
+		},
+		"King on g file depth 4": {
+			fen:      "1nbbnrkr/p1p1ppp1/3p4/1p3P1p/3Pq2P/8/PPP1P1P1/QNBBNRKR w HFhf - 0 9",
+			depth:    4,
+			expected: 31058,
+		},
+	}
+
+	engine := NewEngine()
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			pos, err := NewPositionFromFEN(d.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.True(t, pos.Chess960())
+			assert.Equal(t, d.expected, engine.MoveGenerationTest(pos, d.depth))
+		})
+	}
+}
+
 func TestEngine_MoveGenerationTest_KingCaptureIsNotCounted(t *testing.T) {
 	engine := NewEngine()
 	pos, err := NewPositionFromFEN("2r1k3/3P4/8/8/8/8/8/4K3 w - - 0 1")
@@ -75,3 +123,114 @@ func TestEngine_MoveGenerationTest_KingCaptureIsNotCounted(t *testing.T) {
 
 	assert.Equal(t, uint64(73), engine.MoveGenerationTest(pos, 3))
 }
+
+func TestEngine_PerftStatistics(t *testing.T) {
+	// Expected values are the chessprogramming.org perft results tables.
+	data := map[string]struct {
+		fen      string
+		expected []PerftStats
+	}{
+		"Start position": {
+			fen: FenStartPos,
+			expected: []PerftStats{
+				{Nodes: 20},
+				{Nodes: 400},
+				{Nodes: 8902, Captures: 34, Checks: 12},
+				{Nodes: 197281, Captures: 1576, Checks: 469, Checkmates: 8},
+			},
+		},
+		"Kiwipete": {
+			fen: "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+			expected: []PerftStats{
+				{Nodes: 48, Captures: 8, Castles: 2},
+				{Nodes: 2039, Captures: 351, EnPassants: 1, Castles: 91, Checks: 3},
+				{Nodes: 97862, Captures: 17102, EnPassants: 45, Castles: 3162, Checks: 993, Checkmates: 1},
+				{Nodes: 4085603, Captures: 757163, EnPassants: 1929, Castles: 128013, Promotions: 15172, Checks: 25523, DiscoveredChecks: 42, DoubleChecks: 6, Checkmates: 43},
+			},
+		},
+		"Perft position 3": {
+			fen: "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+			expected: []PerftStats{
+				{Nodes: 14, Captures: 1, Checks: 2},
+				{Nodes: 191, Captures: 14, Checks: 10},
+				{Nodes: 2812, Captures: 209, EnPassants: 2, Checks: 267, DiscoveredChecks: 3},
+				{Nodes: 43238, Captures: 3348, EnPassants: 123, Checks: 1680, DiscoveredChecks: 106, Checkmates: 17},
+				{Nodes: 674624, Captures: 52051, EnPassants: 1165, Checks: 52950, DiscoveredChecks: 1292, DoubleChecks: 3},
+			},
+		},
+		"Perft position 4": {
+			fen: "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+			expected: []PerftStats{
+				{Nodes: 6},
+				{Nodes: 264, Captures: 87, Castles: 6, Promotions: 48, Checks: 10},
+				{Nodes: 9467, Captures: 1021, EnPassants: 4, Promotions: 120, Checks: 38, DiscoveredChecks: 2, Checkmates: 22},
+			},
+		},
+	}
+
+	engine := NewEngine()
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			pos, err := NewPositionFromFEN(d.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, d.expected, engine.PerftStatistics(pos, len(d.expected)))
+			assert.Equal(t, d.fen, pos.FEN())
+		})
+	}
+}
+
+func TestEngine_PerftDivide_ParallelMatchesSequential(t *testing.T) {
+	data := map[string]struct {
+		fen   string
+		depth int
+	}{
+		"Start position depth 4": {
+			fen:   FenStartPos,
+			depth: 4,
+		},
+		"Kiwipete depth 3": {
+			fen:   "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+			depth: 3,
+		},
+		"Perft position 3 depth 5": {
+			fen:   "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+			depth: 5,
+		},
+		"Chess960 depth 3": {
+			fen:   "bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9",
+			depth: 3,
+		},
+		"Depth 1": {
+			fen:   FenStartPos,
+			depth: 1,
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			for _, tricks := range []bool{true, false} {
+				sequential := NewEngine()
+				sequential.SetPerftTricks(tricks)
+				parallel := NewEngine()
+				parallel.SetPerftTricks(tricks)
+				parallel.SetPerftThreads(4)
+
+				pos, err := NewPositionFromFEN(d.fen)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				expectedDivide, expectedTotal := sequential.PerftDivide(pos, d.depth)
+				divide, total := parallel.PerftDivide(pos, d.depth)
+				assert.Equal(t, expectedDivide, divide)
+				assert.Equal(t, expectedTotal, total)
+				assert.Equal(t, sequential.MoveGenerationTest(pos, d.depth+1), parallel.MoveGenerationTest(pos, d.depth+1))
+				assert.Equal(t, d.fen, pos.FEN())
+			}
+		})
+	}
+}
@@ -47,7 +47,7 @@ func TestApplyUCIMovesRegression_CurrentIllegalMoveCases(t *testing.T) {
 				"a1b1", "b8c6", "b1a1", "e6e5", "f3e5", "c6e5", "a1b1", "d5d4",
 				"b1a1", "f8a3", "b2a3", "e8f8", "a1b1", "f8e8", "b1b7",
 			},
-			expectedFEN: "r1bqk1nr/1Rp2ppp/8/4n3/p2p4/P7/P1PPPPPP/2BQKB1R b K - 0 1",
+			expectedFEN: "r1bqk1nr/1Rp2ppp/8/4n3/p2p4/P7/P1PPPPPP/2BQKB1R b K - 0 12",
 			expectedLegal: []string{
 				"e8d7", "e8e7", "e8f8", "e5d3", "e5f3", "d8d7",
 			},
@@ -57,7 +57,7 @@ func TestApplyUCIMovesRegression_CurrentIllegalMoveCases(t *testing.T) {
 				"b1c3", "a7a5", "g1f3", "b8c6", "d2d4", "c6b4", "a1b1", "b4a2",
 				"c3a2", "d7d6", "b1a1", "c8g4", "c1e3", "g4f3",
 			},
-			expectedFEN: "r2qkbnr/1pp1pppp/3p4/p7/3P4/4Bb2/NPP1PPPP/R2QKB1R w Kkq - 0 1",
+			expectedFEN: "r2qkbnr/1pp1pppp/3p4/p7/3P4/4Bb2/NPP1PPPP/R2QKB1R w Kkq - 0 8",
 			expectedLegal: []string{
 				"e1d2", "d4d5", "e3f4", "a1b1",
 			},
@@ -80,3 +80,20 @@ func TestApplyUCIMovesRegression_CurrentIllegalMoveCases(t *testing.T) {
 		})
 	}
 }
+
+func TestApplySANMoves(t *testing.T) {
+	engine := NewEngine()
+	pos, err := NewPositionFromFEN(FenStartPos)
+	assert.NoError(t, err)
+
+	for _, san := range []string{"e4", "e5", "Nf3", "Nc6", "Bb5", "a6", "Bxc6", "dxc6", "O-O"} {
+		assert.NoError(t, engine.ApplySANMove(pos, san), san)
+	}
+	assert.Equal(t, "r1bqkbnr/1pp2ppp/p1p5/4p3/4P3/5N2/PPPP1PPP/RNBQ1RK1 b kq - 1 5", pos.FEN())
+
+	move, err := engine.FindMoveBySAN(pos, "Qxd2")
+	assert.NoError(t, err)
+	assert.Equal(t, "Qxd2", engine.MoveSAN(pos, move))
+
+	assert.Error(t, engine.ApplySANMove(pos, "Qxd1"))
+}
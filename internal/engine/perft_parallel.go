@@ -0,0 +1,83 @@
+package engine
+
+import (
+	board "chessV2/internal/board"
+	"sync"
+	"sync/atomic"
+)
+
+// SetPerftThreads sets how many goroutines PerftDivide and
+// MoveGenerationTest split their tree across. Values below 2 keep the
+// sequential walk.
+func (e *Engine) SetPerftThreads(threads int) {
+	e.perftThreads = max(threads, 1)
+}
+
+// perftJob is one second-level subtree: a root move and one reply to it.
+type perftJob struct {
+	root  int
+	move  board.Move
+	reply board.Move
+}
+
+// perftDivideParallel returns the same counts as the sequential
+// PerftDivide. Root moves alone are too few and too uneven to keep every
+// worker busy, so the work is split by root move and reply. Each worker
+// walks its own copy of the position with its own move buffers, and all of
+// them share one lock-free TT. depth must be at least 2.
+func (e *Engine) perftDivideParallel(pos *board.Position, depth int) (map[string]uint64, uint64) {
+	var tt *perftTT
+	if e.usePerftTricks {
+		tt = newPerftTT()
+	}
+
+	var rootMoves [MaxLegalMoves]board.Move
+	rootCount := e.moveGenerator.LegalMovesInto(pos, e.positionUpdater, rootMoves[:])
+
+	jobs := make([]perftJob, 0, rootCount*32)
+	var replies [MaxLegalMoves]board.Move
+	for i, move := range rootMoves[:rootCount] {
+		history := e.positionUpdater.MakeMove(pos, move)
+		replyCount := e.moveGenerator.LegalMovesInto(pos, e.positionUpdater, replies[:])
+		for _, reply := range replies[:replyCount] {
+			jobs = append(jobs, perftJob{root: i, move: move, reply: reply})
+		}
+		e.positionUpdater.UnMakeMove(pos, history)
+	}
+
+	counts := make([]uint64, rootCount)
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < min(e.perftThreads, len(jobs)); w++ {
+		wg.Add(1)
+		go func(workerPos *board.Position) {
+			defer wg.Done()
+
+			var moveBuffers [MaxPerftPly][MaxLegalMoves]board.Move
+			for {
+				idx := int(next.Add(1) - 1)
+				if idx >= len(jobs) {
+					return
+				}
+
+				job := jobs[idx]
+				moveHistory := e.positionUpdater.MakeMove(workerPos, job.move)
+				replyHistory := e.positionUpdater.MakeMove(workerPos, job.reply)
+				count := e.moveGenerationTestWithBuffers(workerPos, depth-1, 2, &moveBuffers, tt)
+				e.positionUpdater.UnMakeMove(workerPos, replyHistory)
+				e.positionUpdater.UnMakeMove(workerPos, moveHistory)
+
+				atomic.AddUint64(&counts[job.root], count)
+			}
+		}(pos.Clone())
+	}
+	wg.Wait()
+
+	res := make(map[string]uint64, rootCount)
+	total := uint64(0)
+	for i, move := range rootMoves[:rootCount] {
+		res[move.UCI()] = counts[i]
+		total += counts[i]
+	}
+	return res, total
+}
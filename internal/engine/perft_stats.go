@@ -0,0 +1,113 @@
+package engine
+
+import (
+	board "chessV2/internal/board"
+	"chessV2/internal/movegen"
+	"math/bits"
+)
+
+// PerftStats breaks the nodes of one perft depth down by the move that
+// reached them, with the columns of the chessprogramming perft results
+// tables. A single check is discovered when a piece other than the one that
+// moved gives it, a castle checking with its rook; double checks have their
+// own column and are not counted as discovered.
+type PerftStats struct {
+	Nodes            uint64
+	Captures         uint64
+	EnPassants       uint64
+	Castles          uint64
+	Promotions       uint64
+	Checks           uint64
+	DiscoveredChecks uint64
+	DoubleChecks     uint64
+	Checkmates       uint64
+}
+
+// Add accumulates other into s.
+func (s *PerftStats) Add(other PerftStats) {
+	s.Nodes += other.Nodes
+	s.Captures += other.Captures
+	s.EnPassants += other.EnPassants
+	s.Castles += other.Castles
+	s.Promotions += other.Promotions
+	s.Checks += other.Checks
+	s.DiscoveredChecks += other.DiscoveredChecks
+	s.DoubleChecks += other.DoubleChecks
+	s.Checkmates += other.Checkmates
+}
+
+// PerftStatistics walks the tree to depth once and returns the statistics of
+// every depth from 1 to depth, index 0 holding depth 1. Unlike
+// MoveGenerationTest it uses the standard depth convention and never takes
+// the perft shortcuts, since those skip the leaf moves.
+func (e *Engine) PerftStatistics(pos *board.Position, depth int) []PerftStats {
+	if depth <= 0 {
+		return nil
+	}
+	depth = min(depth, MaxPerftPly)
+
+	var moveBuffers [MaxPerftPly][MaxLegalMoves]board.Move
+	stats := make([]PerftStats, depth)
+	e.perftStatistics(pos, 0, stats, &moveBuffers)
+	return stats
+}
+
+func (e *Engine) perftStatistics(pos *board.Position, ply int, stats []PerftStats, moveBuffers *[MaxPerftPly][MaxLegalMoves]board.Move) {
+	moves := moveBuffers[ply][:]
+	moveCount := e.moveGenerator.LegalMovesInto(pos, e.positionUpdater, moves)
+	for i := 0; i < moveCount; i++ {
+		move := moves[i]
+		captured := pos.PieceAt(move.EndIdx()) != board.NoPiece && move.Flag() != board.Castle
+		history := e.positionUpdater.MakeMove(pos, move)
+		e.countPerftMove(pos, move, captured, &stats[ply])
+		if ply+1 < len(stats) {
+			e.perftStatistics(pos, ply+1, stats, moveBuffers)
+		}
+		e.positionUpdater.UnMakeMove(pos, history)
+	}
+}
+
+// countPerftMove adds move, which has just been played, to the statistics
+// of its depth.
+func (e *Engine) countPerftMove(pos *board.Position, move board.Move, captured bool, s *PerftStats) {
+	s.Nodes++
+
+	switch flag := move.Flag(); {
+	case flag == board.EnPassant:
+		s.Captures++
+		s.EnPassants++
+	case flag == board.Castle:
+		s.Castles++
+	case flag >= board.QueenPromotion && flag <= board.RookPromotion:
+		s.Promotions++
+	}
+	if captured {
+		s.Captures++
+	}
+
+	mover := move.Piece().Color()
+	kingIdx := pos.WhiteKingIdx()
+	if mover == board.White {
+		kingIdx = pos.BlackKingIdx()
+	}
+	checkers := movegen.AttackersTo(pos, kingIdx, mover)
+	if checkers == 0 {
+		return
+	}
+	s.Checks++
+
+	checkingSq := move.EndIdx()
+	if move.Flag() == board.Castle {
+		_, _, checkingSq = pos.CastleMoveSquares(move)
+	}
+	if bits.OnesCount64(checkers) > 1 {
+		s.DoubleChecks++
+	} else if checkers != uint64(1)<<checkingSq {
+		s.DiscoveredChecks++
+	}
+
+	var replies [MaxLegalMoves]board.Move
+	if e.moveGenerator.LegalMovesInto(pos, e.positionUpdater, replies[:]) == 0 {
+		s.Checkmates++
+	}
+}
@@ -1,12 +1,18 @@
 package engine
 
+import "sync/atomic"
+
 const perftTTSize = 1 << 20
 
+// perftTTEntry stores the key XORed with the count so that a probe racing a
+// store from another worker sees a mismatched key instead of a torn entry.
 type perftTTEntry struct {
-	keyWithDepth uint64
-	count        uint64
+	check atomic.Uint64
+	count atomic.Uint64
 }
 
+// perftTT is safe for concurrent use without locks; parallel perft workers
+// share one table.
 type perftTT struct {
 	entries []perftTTEntry
 	mask    uint64
@@ -22,15 +28,15 @@ func newPerftTT() *perftTT {
 func (tt *perftTT) probe(zobrist uint64, depth int8) (uint64, bool) {
 	combined := zobrist ^ (uint64(depth) << 56)
 	e := &tt.entries[zobrist&tt.mask]
-	if e.keyWithDepth == combined {
-		return e.count, true
+	count := e.count.Load()
+	if e.check.Load()^count == combined {
+		return count, true
 	}
 	return 0, false
 }
 
 func (tt *perftTT) store(zobrist uint64, depth int8, count uint64) {
-	tt.entries[zobrist&tt.mask] = perftTTEntry{
-		keyWithDepth: zobrist ^ (uint64(depth) << 56),
-		count:        count,
-	}
+	e := &tt.entries[zobrist&tt.mask]
+	e.check.Store(zobrist ^ (uint64(depth) << 56) ^ count)
+	e.count.Store(count)
 }
@@ -22,7 +22,9 @@ func (e *ZeroEvaluator) Evaluate(pos *board.Position) Score {
 	return DrawScore
 }
 
-type StaticEvaluator struct{}
+type StaticEvaluator struct {
+	pawnCache []pawnEntry
+}
 
 type phaseScore struct {
 	mg Score
@@ -76,13 +78,11 @@ const (
 	rookRaidPenaltyBase    Score = 20
 )
 
-const kingSafetyTradeValue Score = 2000
-
 var passedPawnBonusMG = [8]Score{0, 0, 8, 14, 24, 40, 70, 0}
 var passedPawnBonusEG = [8]Score{0, 0, 16, 28, 48, 80, 130, 0}
 
 func NewStaticEvaluator() *StaticEvaluator {
-	return &StaticEvaluator{}
+	return &StaticEvaluator{pawnCache: make([]pawnEntry, pawnCacheSize)}
 }
 
 var pieceValues = [7]Score{
@@ -308,11 +308,12 @@ func (e *StaticEvaluator) Evaluate(pos *board.Position) Score {
 	whiteScore -= kingSafetyPenalty(pos, board.White, phase)
 	blackScore -= kingSafetyPenalty(pos, board.Black, phase)
 
-	whiteScore += passedPawnScore(pos, board.White, phase)
-	blackScore += passedPawnScore(pos, board.Black, phase)
+	pawns := e.pawnStructure(pos)
+	whiteScore += passedPawnScore(pos, board.White, pawns.passed[0], phase)
+	blackScore += passedPawnScore(pos, board.Black, pawns.passed[1], phase)
 
-	whiteScore -= pawnStructurePenalty(pos, board.White, phase)
-	blackScore -= pawnStructurePenalty(pos, board.Black, phase)
+	whiteScore -= pawnStructurePenalty(pawns, 0, phase)
+	blackScore -= pawnStructurePenalty(pawns, 1, phase)
 
 	if pos.ActiveColor() == board.White {
 		return whiteScore - blackScore
@@ -367,11 +368,9 @@ func pieceSafetyScore(pos *board.Position, color int8) Score {
 			continue
 		}
 
-		attackers := attackCountOnSquare(pos, enemyColor, idx)
-		defenders := attackCountOnSquare(pos, color, idx)
-		leastAttacker := leastAttackerValueOnSquare(pos, enemyColor, idx)
-		leastDefender := leastAttackerValueOnSquare(pos, color, idx)
-		pieceValue := tradeSafetyValue(piece.Type())
+		attackerMask := movegen.AttackersTo(pos, idx, enemyColor)
+		attackers := bits.OnesCount64(attackerMask)
+		defenders := bits.OnesCount64(movegen.AttackersTo(pos, idx, color))
 
 		if defenders > 0 {
 			bonus := protectedPieceWeights[piece.Type()]
@@ -392,26 +391,35 @@ func pieceSafetyScore(pos *board.Position, color int8) Score {
 			penalty += exposedPieceWeights[piece.Type()] * Score(attackers-defenders)
 		}
 
-		if leastAttacker > 0 && leastAttacker < pieceValue {
-			tradeLoss := pieceValue - leastAttacker
-			switch {
-			case defenders == 0:
-				penalty += tradeLoss
-			case leastDefender == 0:
-				penalty += tradeLoss * 3 / 4
-			case leastDefender > leastAttacker:
-				penalty += tradeLoss * 3 / 5
-			}
-		}
-
-		penalty += heavyPieceOverextensionPenalty(pos, piece, idx, color, attackers, defenders, leastAttacker, leastDefender)
+		threat := tradeThreat(pos, piece, idx, attackerMask)
+		penalty += threat * 3 / 5
+		penalty += heavyPieceOverextensionPenalty(pos, piece, idx, color, attackers, defenders, threat)
 		score -= penalty
 	}
 
 	return score
 }
 
-func heavyPieceOverextensionPenalty(pos *board.Position, piece board.Piece, idx, color int8, attackers, defenders int, leastAttacker, leastDefender Score) Score {
+// tradeThreat returns the part of the SEE threat on the piece at idx that a
+// cheaper attacker wins over an even trade. A piece hanging to an attacker of
+// its own value is already charged through exposedPieceWeights, and its owner
+// can usually trade it off.
+func tradeThreat(pos *board.Position, piece board.Piece, idx int8, attackers uint64) Score {
+	threat := movegen.SEEThreat(pos, idx)
+	if threat == 0 {
+		return DrawScore
+	}
+
+	least := movegen.SEEValue(board.King)
+	for attackers != 0 {
+		square := int8(bits.TrailingZeros64(attackers))
+		attackers &= attackers - 1
+		least = min(least, movegen.SEEValue(pos.PieceAt(square).Type()))
+	}
+	return Score(max(min(threat, movegen.SEEValue(piece.Type())-least), 0))
+}
+
+func heavyPieceOverextensionPenalty(pos *board.Position, piece board.Piece, idx, color int8, attackers, defenders int, threat Score) Score {
 	if piece.Type() != board.Queen && piece.Type() != board.Rook {
 		return DrawScore
 	}
@@ -434,13 +442,8 @@ func heavyPieceOverextensionPenalty(pos *board.Position, piece board.Piece, idx,
 		penalty += basePenalty / 2
 	}
 
-	if leastAttacker > 0 {
-		if leastAttacker < tradeSafetyValue(piece.Type()) {
-			penalty += basePenalty
-		}
-		if leastDefender == 0 || leastDefender > leastAttacker {
-			penalty += basePenalty / 2
-		}
+	if threat > 0 {
+		penalty += basePenalty + basePenalty/2
 	}
 
 	penalty += unsafeHeavyRaidPenalty(pos, piece, idx, color, attackers, defenders)
@@ -488,22 +491,17 @@ func kingSafetyPenalty(pos *board.Position, color int8, phase int) Score {
 	}
 
 	ring := movegen.KingRingMask(kingIdx)
-	enemyAttacks := attackMap(pos, enemyColor)
+	enemyAttacks := movegen.AttackedSquares(pos, enemyColor)
 	penalty += Score(bits.OnesCount64(ring&enemyAttacks)) * phaseBlend(kingRingAttackWeightMG, kingRingAttackWeightEG, phase)
 	penalty += pawnShieldPenalty(pos, color, kingIdx, phase)
 	return penalty
 }
 
-func passedPawnScore(pos *board.Position, color int8, phase int) Score {
+func passedPawnScore(pos *board.Position, color int8, passed uint64, phase int) Score {
 	score := DrawScore
-	for idx := int8(0); idx < 64; idx++ {
-		piece := pos.PieceAt(idx)
-		if piece == board.NoPiece || piece.Color() != color || piece.Type() != board.Pawn {
-			continue
-		}
-		if !isPassedPawn(pos, color, idx) {
-			continue
-		}
+	for passed != 0 {
+		idx := int8(bits.TrailingZeros64(passed))
+		passed &= passed - 1
 
 		rank := board.RankFromIdx(idx)
 		progress := rank
@@ -512,7 +510,7 @@ func passedPawnScore(pos *board.Position, color int8, phase int) Score {
 		}
 
 		bonus := phaseBlend(passedPawnBonusMG[progress], passedPawnBonusEG[progress], phase)
-		if attackCountOnSquare(pos, color, idx) > 0 {
+		if movegen.AttackersTo(pos, idx, color) != 0 {
 			bonus += bonus / 4
 		}
 		score += bonus
@@ -520,45 +518,9 @@ func passedPawnScore(pos *board.Position, color int8, phase int) Score {
 	return score
 }
 
-func pawnStructurePenalty(pos *board.Position, color int8, phase int) Score {
-	var fileCounts [8]int
-	for idx := int8(0); idx < 64; idx++ {
-		piece := pos.PieceAt(idx)
-		if piece == board.NoPiece || piece.Color() != color || piece.Type() != board.Pawn {
-			continue
-		}
-		fileCounts[board.FileFromIdx(idx)]++
-	}
-
-	penalty := DrawScore
-	for idx := int8(0); idx < 64; idx++ {
-		piece := pos.PieceAt(idx)
-		if piece == board.NoPiece || piece.Color() != color || piece.Type() != board.Pawn {
-			continue
-		}
-
-		file := board.FileFromIdx(idx)
-		leftCount := 0
-		rightCount := 0
-		if file > 0 {
-			leftCount = fileCounts[file-1]
-		}
-		if file < 7 {
-			rightCount = fileCounts[file+1]
-		}
-		if leftCount == 0 && rightCount == 0 {
-			penalty += phaseBlend(isolatedPawnPenaltyMG, isolatedPawnPenaltyEG, phase)
-		}
-	}
-
-	for file := 0; file < 8; file++ {
-		if fileCounts[file] > 1 {
-			extras := fileCounts[file] - 1
-			penalty += phaseBlend(doubledPawnPenaltyMG, doubledPawnPenaltyEG, phase) * Score(extras)
-		}
-	}
-
-	return penalty
+func pawnStructurePenalty(pawns pawnEntry, colorIdx int, phase int) Score {
+	return phaseBlend(isolatedPawnPenaltyMG, isolatedPawnPenaltyEG, phase)*Score(pawns.isolated[colorIdx]) +
+		phaseBlend(doubledPawnPenaltyMG, doubledPawnPenaltyEG, phase)*Score(pawns.doubled[colorIdx])
 }
 
 func isPassedPawn(pos *board.Position, color, idx int8) bool {
@@ -590,62 +552,6 @@ func isPassedPawn(pos *board.Position, color, idx int8) bool {
 	return true
 }
 
-func attackMap(pos *board.Position, color int8) uint64 {
-	mask := uint64(0)
-	for idx := int8(0); idx < 64; idx++ {
-		piece := pos.PieceAt(idx)
-		if piece == board.NoPiece || piece.Color() != color {
-			continue
-		}
-		mask |= movegen.PieceAttackMask(pos, piece, idx)
-	}
-	return mask
-}
-
-func attackCountOnSquare(pos *board.Position, color, square int8) int {
-	count := 0
-	squareMask := uint64(1) << square
-	for idx := int8(0); idx < 64; idx++ {
-		piece := pos.PieceAt(idx)
-		if piece == board.NoPiece || piece.Color() != color {
-			continue
-		}
-		if movegen.PieceAttackMask(pos, piece, idx)&squareMask != 0 {
-			count++
-		}
-	}
-	return count
-}
-
-func leastAttackerValueOnSquare(pos *board.Position, color, square int8) Score {
-	lowest := Score(0)
-	squareMask := uint64(1) << square
-
-	for idx := int8(0); idx < 64; idx++ {
-		piece := pos.PieceAt(idx)
-		if piece == board.NoPiece || piece.Color() != color {
-			continue
-		}
-		if movegen.PieceAttackMask(pos, piece, idx)&squareMask == 0 {
-			continue
-		}
-
-		value := tradeSafetyValue(piece.Type())
-		if lowest == 0 || value < lowest {
-			lowest = value
-		}
-	}
-
-	return lowest
-}
-
-func tradeSafetyValue(pieceType int8) Score {
-	if pieceType == board.King {
-		return kingSafetyTradeValue
-	}
-	return pieceValues[pieceType]
-}
-
 func isEnemyTerritorySquare(color, idx int8) bool {
 	rank := board.RankFromIdx(idx)
 	if color == board.White {
@@ -662,12 +568,9 @@ func enemyTerritoryDepth(color, idx int8) int8 {
 	return 4 - rank
 }
 
+// safeHeavyRetreatCount counts the moves back towards home that SEE says do
+// not lose the piece.
 func safeHeavyRetreatCount(pos *board.Position, piece board.Piece, idx, color int8) int {
-	enemyColor := board.White
-	if color == board.White {
-		enemyColor = board.Black
-	}
-
 	currentDepth := enemyTerritoryDepth(color, idx)
 	targets := movegen.PseudoLegalTargetsMask(pos, piece, idx)
 	count := 0
@@ -680,14 +583,11 @@ func safeHeavyRetreatCount(pos *board.Position, piece board.Piece, idx, color in
 			continue
 		}
 
-		enemyAttackers := attackCountOnSquare(pos, enemyColor, target)
-		friendlyDefenders := attackCountOnSquare(pos, color, target)
-		if movegen.PieceAttackMask(pos, piece, idx)&(uint64(1)<<target) != 0 && friendlyDefenders > 0 {
-			friendlyDefenders--
+		flag := int8(board.NormalMove)
+		if pos.PieceAt(target) != board.NoPiece {
+			flag = board.Capture
 		}
-		leastEnemy := leastAttackerValueOnSquare(pos, enemyColor, target)
-
-		if enemyAttackers == 0 || friendlyDefenders > enemyAttackers || leastEnemy >= tradeSafetyValue(piece.Type()) {
+		if movegen.SEEGreaterOrEqual(pos, board.NewMove(piece, idx, target, flag), 0) {
 			count++
 		}
 	}
@@ -0,0 +1,62 @@
+package eval
+
+import board "chessV2/internal/board"
+
+const pawnCacheSize = 1 << 14
+
+// pawnEntry holds what eval derives from the pawns alone, indexed by color
+// (0 white, 1 black): the isolated pawns, the extra pawns on doubled files
+// and the passed pawns. It is cached by the position's pawn key, which every
+// position updater maintains.
+type pawnEntry struct {
+	key      uint64
+	filled   bool
+	isolated [2]int8
+	doubled  [2]int8
+	passed   [2]uint64
+}
+
+// pawnStructure returns the pawn entry of pos from the cache, computing and
+// storing it on a miss. An evaluator without a cache always computes it.
+func (e *StaticEvaluator) pawnStructure(pos *board.Position) pawnEntry {
+	if len(e.pawnCache) == 0 {
+		return computePawnEntry(pos)
+	}
+
+	key := pos.PawnKey()
+	slot := &e.pawnCache[key&(pawnCacheSize-1)]
+	if !slot.filled || slot.key != key {
+		*slot = computePawnEntry(pos)
+	}
+	return *slot
+}
+
+func computePawnEntry(pos *board.Position) pawnEntry {
+	entry := pawnEntry{key: pos.PawnKey(), filled: true}
+	for colorIdx, color := range [2]int8{board.White, board.Black} {
+		var fileCounts [8]int8
+		for idx := int8(0); idx < 64; idx++ {
+			piece := pos.PieceAt(idx)
+			if piece == board.NoPiece || piece.Color() != color || piece.Type() != board.Pawn {
+				continue
+			}
+			fileCounts[board.FileFromIdx(idx)]++
+			if isPassedPawn(pos, color, idx) {
+				entry.passed[colorIdx] |= uint64(1) << idx
+			}
+		}
+
+		for file := int8(0); file < 8; file++ {
+			if fileCounts[file] == 0 {
+				continue
+			}
+			if fileCounts[file] > 1 {
+				entry.doubled[colorIdx] += fileCounts[file] - 1
+			}
+			if (file == 0 || fileCounts[file-1] == 0) && (file == 7 || fileCounts[file+1] == 0) {
+				entry.isolated[colorIdx] += fileCounts[file]
+			}
+		}
+	}
+	return entry
+}
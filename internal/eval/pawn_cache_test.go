@@ -0,0 +1,78 @@
+package eval
+
+import (
+	board "chessV2/internal/board"
+	"chessV2/internal/movegen/reference"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputePawnEntry(t *testing.T) {
+	data := map[string]struct {
+		fen      string
+		expected pawnEntry
+	}{
+		"start position": {
+			fen: board.FenStartPos,
+		},
+		"isolated doubled and passed pawns": {
+			fen: "4k3/8/8/P7/8/P3P3/4P2p/4K3 w - - 0 1",
+			expected: pawnEntry{
+				isolated: [2]int8{4, 1},
+				doubled:  [2]int8{2, 0},
+				passed:   [2]uint64{1<<board.A3 | 1<<board.A5 | 1<<board.E2 | 1<<board.E3, 1 << board.H2},
+			},
+		},
+		"blocked pawns are not passed": {
+			fen: "4k3/8/3p4/3P4/8/8/8/4K3 w - - 0 1",
+			expected: pawnEntry{
+				isolated: [2]int8{1, 1},
+			},
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			pos, err := board.NewPositionFromFEN(d.fen)
+			assert.NoError(t, err)
+
+			entry := computePawnEntry(pos)
+			assert.Equal(t, d.expected.isolated, entry.isolated)
+			assert.Equal(t, d.expected.doubled, entry.doubled)
+			assert.Equal(t, d.expected.passed, entry.passed)
+		})
+	}
+}
+
+func TestPawnCacheMatchesUncachedEvaluation(t *testing.T) {
+	updaters := map[string]board.MoveApplier{
+		"zobrist": board.NewPositionUpdater(),
+		"plain":   board.NewPlainPositionUpdater(),
+	}
+
+	for name, updater := range updaters {
+		t.Run(name, func(t *testing.T) {
+			cached := NewStaticEvaluator()
+			uncached := &StaticEvaluator{}
+			rng := rand.New(rand.NewSource(1))
+
+			for game := 0; game < 20; game++ {
+				pos, err := board.NewPositionFromFEN(board.FenStartPos)
+				assert.NoError(t, err)
+				for ply := 0; ply < 80; ply++ {
+					if cached.Evaluate(pos) != uncached.Evaluate(pos) {
+						assert.Fail(t, "cached evaluation differs", pos.FEN())
+						return
+					}
+					moves := reference.LegalMoves(pos)
+					if len(moves) == 0 {
+						break
+					}
+					updater.MakeMove(pos, moves[rng.Intn(len(moves))])
+				}
+			}
+		})
+	}
+}
@@ -88,6 +88,8 @@ func (s Summary) ReasonSummary() string {
 		"checkmate",
 		"draw by repetition",
 		"stalemate",
+		"fifty-move rule",
+		"insufficient material",
 		"max plies",
 		"illegal move",
 		"search error",
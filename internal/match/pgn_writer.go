@@ -0,0 +1,87 @@
+package match
+
+import (
+	board "chessV2/internal/board"
+	"chessV2/internal/engine"
+	"chessV2/internal/pgn"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type PGNWriter struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewPGNWriter(path string) (*PGNWriter, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PGNWriter{file: file}, nil
+}
+
+func (w *PGNWriter) Write(game *pgn.Game) error {
+	if w == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return pgn.Write(w.file, game)
+}
+
+func (w *PGNWriter) Close() error {
+	if w == nil {
+		return nil
+	}
+	return w.file.Close()
+}
+
+// matchGamePGN converts a finished referee game into a PGN game. outcome is
+// from the current engine's point of view, as in gameResult.
+func matchGamePGN(current, opponent string, date time.Time, gameIndex int, currentAsWhite bool, moves []string, outcome int, reason string) (*pgn.Game, error) {
+	game, err := pgn.NewGameFromUCI(engine.NewEngine(), board.FenStartPos, moves)
+	if err != nil {
+		return nil, fmt.Errorf("game %d: %w", gameIndex+1, err)
+	}
+
+	white, black := current, opponent
+	if !currentAsWhite {
+		white, black = black, white
+	}
+
+	game.SetTag("Event", "gochess match")
+	game.SetTag("Site", "?")
+	game.SetTag("Date", date.UTC().Format("2006.01.02"))
+	game.SetTag("Round", strconv.Itoa(gameIndex+1))
+	game.SetTag("White", white)
+	game.SetTag("Black", black)
+	game.SetTag("Termination", reason)
+	game.Result = pgnResult(outcome, currentAsWhite)
+
+	return game, nil
+}
+
+func pgnResult(outcome int, currentAsWhite bool) string {
+	switch {
+	case outcome == 0:
+		return pgn.ResultDraw
+	case (outcome == 1) == currentAsWhite:
+		return pgn.ResultWhiteWins
+	default:
+		return pgn.ResultBlackWins
+	}
+}
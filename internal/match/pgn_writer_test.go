@@ -0,0 +1,48 @@
+package match
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"chessV2/internal/engine"
+	"chessV2/internal/pgn"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPGNWriterWritesMatchGames(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "games.pgn")
+
+	writer, err := NewPGNWriter(path)
+	assert.NoError(t, err)
+	assert.NotNil(t, writer)
+
+	date := time.Date(2026, 4, 1, 19, 0, 0, 0, time.UTC)
+	game, err := matchGamePGN("current", "opponent", date, 1, false, []string{"f2f3", "e7e5", "g2g4", "d8h4"}, 1, "checkmate")
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Write(game))
+	assert.NoError(t, writer.Close())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "[Event \"gochess match\"]\n"))
+	assert.Contains(t, string(data), "[Round \"2\"]\n[White \"opponent\"]\n[Black \"current\"]\n[Result \"0-1\"]\n")
+	assert.Contains(t, string(data), "1. f3 e5 2. g4 Qh4# 0-1")
+
+	games, err := pgn.NewReader(strings.NewReader(string(data)), engine.NewEngine()).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, games, 1)
+	assert.Equal(t, []string{"f2f3", "e7e5", "g2g4", "d8h4"}, games[0].UCIMoves())
+}
+
+func TestPGNResult(t *testing.T) {
+	assert.Equal(t, pgn.ResultWhiteWins, pgnResult(1, true))
+	assert.Equal(t, pgn.ResultBlackWins, pgnResult(-1, true))
+	assert.Equal(t, pgn.ResultBlackWins, pgnResult(1, false))
+	assert.Equal(t, pgn.ResultWhiteWins, pgnResult(-1, false))
+	assert.Equal(t, pgn.ResultDraw, pgnResult(0, false))
+}
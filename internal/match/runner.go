@@ -28,6 +28,7 @@ type Config struct {
 	CurrentLabel  string
 	CurrentBinary string
 	RecordPath    string
+	PGNPath       string
 	Progress      func(Snapshot)
 }
 
@@ -140,6 +141,14 @@ func RunMatch(cfg Config) (Summary, error) {
 		defer recordWriter.Close()
 	}
 
+	pgnWriter, err := NewPGNWriter(cfg.PGNPath)
+	if err != nil {
+		return Summary{}, err
+	}
+	if pgnWriter != nil {
+		defer pgnWriter.Close()
+	}
+
 	stopTicker := make(chan struct{})
 	var tickerWG sync.WaitGroup
 	if cfg.Progress != nil {
@@ -172,7 +181,7 @@ func RunMatch(cfg Config) (Summary, error) {
 		workers.Add(1)
 		go func() {
 			defer workers.Done()
-			if err := runWorker(currentBinary.Path, opponent.Path, cfg.MoveTime, jobs, results, state, recordWriter); err != nil {
+			if err := runWorker(currentBinary.Path, opponent.Path, cfg.MoveTime, jobs, results, state, recordWriter, pgnWriter); err != nil {
 				select {
 				case errs <- err:
 				default:
@@ -210,7 +219,7 @@ func RunMatch(cfg Config) (Summary, error) {
 	return state.summary, nil
 }
 
-func runWorker(currentPath, opponentPath string, moveTime time.Duration, jobs <-chan int, results chan<- gameResult, state *matchState, recordWriter *RecordWriter) error {
+func runWorker(currentPath, opponentPath string, moveTime time.Duration, jobs <-chan int, results chan<- gameResult, state *matchState, recordWriter *RecordWriter, pgnWriter *PGNWriter) error {
 	currentClient, err := NewUCIClient(currentPath)
 	if err != nil {
 		return err
@@ -235,6 +244,7 @@ func runWorker(currentPath, opponentPath string, moveTime time.Duration, jobs <-
 		state.startGame(gameIndex, currentAsWhite)
 
 		start := time.Now()
+		var moves []string
 		outcome, plies, reason, nodes, searchTime, diagnostic, err := playSingleGame(
 			currentClient,
 			opponentClient,
@@ -242,13 +252,23 @@ func runWorker(currentPath, opponentPath string, moveTime time.Duration, jobs <-
 			effectiveMoveTime(moveTime, state.cfg.MoveOverhead),
 			gameIndex,
 			recordWriter,
-			func(ply int) {
+			func(ply int, move string) {
+				moves = append(moves, move)
 				state.updatePlies(gameIndex, ply)
 			},
 		)
 		if err != nil {
 			return err
 		}
+		if pgnWriter != nil {
+			game, err := matchGamePGN(state.summary.Current, state.summary.Opponent, state.summary.Date, gameIndex, currentAsWhite, moves, outcome, reason)
+			if err != nil {
+				return err
+			}
+			if err := pgnWriter.Write(game); err != nil {
+				return err
+			}
+		}
 		if diagnostic != nil {
 			diagnostic.GameIndex = gameIndex + 1
 		}
@@ -414,7 +434,7 @@ func (s *matchState) emitLocked() {
 	})
 }
 
-func playSingleGame(currentClient, opponentClient *UCIClient, currentIsWhite bool, moveTime time.Duration, gameIndex int, recordWriter *RecordWriter, onPly func(int)) (int, int, string, uint64, time.Duration, *IllegalMoveDiagnostic, error) {
+func playSingleGame(currentClient, opponentClient *UCIClient, currentIsWhite bool, moveTime time.Duration, gameIndex int, recordWriter *RecordWriter, onPly func(ply int, move string)) (int, int, string, uint64, time.Duration, *IllegalMoveDiagnostic, error) {
 	referee := engine.NewEngine()
 	pos, err := board.NewPositionFromFEN(board.FenStartPos)
 	if err != nil {
@@ -422,13 +442,15 @@ func playSingleGame(currentClient, opponentClient *UCIClient, currentIsWhite boo
 	}
 
 	moves := make([]string, 0, defaultMaxPlies)
-	repetitionCount := map[uint64]int{pos.ZobristKey(): 1}
+	positionKeys := make([]uint64, 0, defaultMaxPlies+1)
+	positionKeys = append(positionKeys, pos.ZobristKey())
 	var totalNodes uint64
 	var totalSearchTime time.Duration
 
 	for ply := 0; ply < defaultMaxPlies; ply++ {
-		if repetitionCount[pos.ZobristKey()] >= 3 {
-			return 0, ply, "draw by repetition", totalNodes, totalSearchTime, nil, nil
+		drawReason := pos.DrawReason(positionKeys)
+		if drawReason == board.DrawByRepetition || drawReason == board.DrawByInsufficientMaterial {
+			return 0, ply, drawReason.String(), totalNodes, totalSearchTime, nil, nil
 		}
 
 		legalMoves := referee.LegalMoves(pos)
@@ -442,6 +464,9 @@ func playSingleGame(currentClient, opponentClient *UCIClient, currentIsWhite boo
 			}
 			return 0, ply, "stalemate", totalNodes, totalSearchTime, nil, nil
 		}
+		if drawReason == board.DrawByFiftyMoveRule {
+			return 0, ply, drawReason.String(), totalNodes, totalSearchTime, nil, nil
+		}
 
 		client := selectClient(currentClient, opponentClient, pos.ActiveColor(), currentIsWhite)
 		fenBefore := pos.FEN()
@@ -477,9 +502,9 @@ func playSingleGame(currentClient, opponentClient *UCIClient, currentIsWhite boo
 		}
 
 		moves = append(moves, bestMove)
-		repetitionCount[pos.ZobristKey()]++
+		positionKeys = append(positionKeys, pos.ZobristKey())
 		if onPly != nil {
-			onPly(ply + 1)
+			onPly(ply+1, bestMove)
 		}
 	}
 
@@ -103,6 +103,60 @@ func (c *UCIClient) BestMove(moves []string, moveTime time.Duration) (string, Se
 	return fields[1], stats, nil
 }
 
+// SetOption sends a setoption command and waits until the engine is ready
+// again.
+func (c *UCIClient) SetOption(name string, value string) error {
+	if err := c.send(fmt.Sprintf("setoption name %s value %s", name, value)); err != nil {
+		return err
+	}
+	return c.ready()
+}
+
+// Perft runs "go perft" on fen after moves and returns the divide and the
+// total. Engines answer with one "move: count" line per root move and a
+// final "Nodes searched: total" line, as Stockfish does.
+func (c *UCIClient) Perft(fen string, moves []string, depth int, timeout time.Duration) (map[string]uint64, uint64, error) {
+	if err := c.ready(); err != nil {
+		return nil, 0, err
+	}
+
+	position := "position fen " + fen
+	if len(moves) > 0 {
+		position += " moves " + strings.Join(moves, " ")
+	}
+	if err := c.send(position); err != nil {
+		return nil, 0, err
+	}
+	if err := c.send(fmt.Sprintf("go perft %d", depth)); err != nil {
+		return nil, 0, err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	divide := make(map[string]uint64)
+	for {
+		select {
+		case line, ok := <-c.lines:
+			if !ok {
+				return nil, 0, fmt.Errorf("uci process ended before %q", "Nodes searched")
+			}
+			if total, ok := strings.CutPrefix(line, "Nodes searched:"); ok {
+				nodes, err := strconv.ParseUint(strings.TrimSpace(total), 10, 64)
+				if err != nil {
+					return nil, 0, fmt.Errorf("invalid perft total: %q", line)
+				}
+				return divide, nodes, nil
+			}
+			if move, count, ok := parsePerftLine(line); ok {
+				divide[move] = count
+			}
+		case <-timer.C:
+			return nil, 0, fmt.Errorf("timeout waiting for %q", "Nodes searched")
+		}
+	}
+}
+
 func (c *UCIClient) Close() error {
 	_ = c.send("quit")
 	return c.cmd.Wait()
@@ -183,6 +237,19 @@ func parseInfoStats(line string, stats SearchStats) SearchStats {
 	return stats
 }
 
+// parsePerftLine reads a "e2e4: 20" divide line.
+func parsePerftLine(line string) (string, uint64, bool) {
+	move, count, ok := strings.Cut(line, ":")
+	if !ok || len(move) < 4 || len(move) > 5 || strings.ContainsAny(move, " ") {
+		return "", 0, false
+	}
+	nodes, err := strconv.ParseUint(strings.TrimSpace(count), 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return move, nodes, true
+}
+
 func scanLines(r io.Reader, out chan<- string) {
 	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
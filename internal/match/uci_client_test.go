@@ -0,0 +1,31 @@
+package match
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePerftLine(t *testing.T) {
+	data := map[string]struct {
+		line  string
+		move  string
+		nodes uint64
+		ok    bool
+	}{
+		"quiet move":     {line: "e2e4: 20", move: "e2e4", nodes: 20, ok: true},
+		"promotion":      {line: "a7a8q: 1", move: "a7a8q", nodes: 1, ok: true},
+		"nodes searched": {line: "Nodes searched: 400", ok: false},
+		"info line":      {line: "info string NNUE evaluation enabled", ok: false},
+		"bad count":      {line: "e2e4: x", ok: false},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			move, nodes, ok := parsePerftLine(d.line)
+			assert.Equal(t, d.ok, ok)
+			assert.Equal(t, d.move, move)
+			assert.Equal(t, d.nodes, nodes)
+		})
+	}
+}
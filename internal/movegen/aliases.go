@@ -13,6 +13,7 @@ const (
 	FenStartPos = board.FenStartPos
 
 	NoPiece = board.NoPiece
+	NoMove  = board.NoMove
 
 	King   = board.King
 	Queen  = board.Queen
@@ -0,0 +1,108 @@
+package movegen
+
+import "math/bits"
+
+// AttackersTo returns the pieces of color that attack sq in the current
+// position.
+func AttackersTo(pos *Position, sq int8, color int8) uint64 {
+	ensureAttackTables()
+	return attackersTo(pos, sq, pos.Occupied()) & pos.OccupancyMask(color)
+}
+
+// AttackedSquares returns every square at least one piece of color attacks.
+func AttackedSquares(pos *Position, color int8) uint64 {
+	ensureAttackTables()
+
+	occ := pos.Occupied()
+	mask := uint64(0)
+	pieces := pos.OccupancyMask(color)
+	for pieces != 0 {
+		idx := int8(bits.TrailingZeros64(pieces))
+		pieces &= pieces - 1
+
+		switch pos.PieceAt(idx).Type() {
+		case Pawn:
+			mask |= pawnAttackMask(color, idx)
+		case Knight:
+			mask |= knightAttacksMask[idx]
+		case Bishop:
+			mask |= bishopAttacksMagic(idx, occ)
+		case Rook:
+			mask |= rookAttacksMagic(idx, occ)
+		case Queen:
+			mask |= bishopAttacksMagic(idx, occ) | rookAttacksMagic(idx, occ)
+		case King:
+			mask |= kingAttacksMask[idx]
+		}
+	}
+	return mask
+}
+
+// PinnedPieces returns the pieces of color that cannot leave the line
+// between their king and an enemy slider without exposing the king.
+func PinnedPieces(pos *Position, color int8) uint64 {
+	ensureAttackTables()
+	enemyOcc := pos.OccupancyMask(color ^ (White | Black))
+	return sliderBlockers(pos, kingSquare(pos, color), enemyOcc) & pos.OccupancyMask(color)
+}
+
+// DiscoveredCheckCandidates returns the pieces of color standing alone
+// between one of their own sliders and the enemy king: moving such a piece
+// off the line gives check.
+func DiscoveredCheckCandidates(pos *Position, color int8) uint64 {
+	ensureAttackTables()
+	enemyKing := kingSquare(pos, color^(White|Black))
+	return sliderBlockers(pos, enemyKing, pos.OccupancyMask(color)) & pos.OccupancyMask(color)
+}
+
+// CheckSquares returns the squares from which a piece of pieceType and color
+// would attack the enemy king. Kings never give check and get an empty mask.
+func CheckSquares(pos *Position, color int8, pieceType int8) uint64 {
+	ensureAttackTables()
+
+	enemyKing := kingSquare(pos, color^(White|Black))
+	occ := pos.Occupied()
+	switch pieceType {
+	case Pawn:
+		if color == White {
+			return pawnAttacksBy[0][enemyKing]
+		}
+		return pawnAttacksBy[1][enemyKing]
+	case Knight:
+		return knightAttacksMask[enemyKing]
+	case Bishop:
+		return bishopAttacksMagic(enemyKing, occ)
+	case Rook:
+		return rookAttacksMagic(enemyKing, occ)
+	case Queen:
+		return bishopAttacksMagic(enemyKing, occ) | rookAttacksMagic(enemyKing, occ)
+	}
+	return 0
+}
+
+// sliderBlockers returns the pieces, of either color, that are the only
+// piece between sq and a slider of sliderOcc aimed at it.
+func sliderBlockers(pos *Position, sq int8, sliderOcc uint64) uint64 {
+	occ := pos.Occupied()
+	snipers := rookAttacksMagic(sq, 0) & (pos.RookBoard() | pos.QueenBoard()) & sliderOcc
+	snipers |= bishopAttacksMagic(sq, 0) & (pos.BishopBoard() | pos.QueenBoard()) & sliderOcc
+
+	blockers := uint64(0)
+	for snipers != 0 {
+		sniper := int8(bits.TrailingZeros64(snipers))
+		snipers &= snipers - 1
+
+		between := betweenMasks[sq][sniper] & occ
+		if bits.OnesCount64(between) == 1 {
+			blockers |= between
+		}
+	}
+	return blockers
+}
+
+func kingSquare(pos *Position, color int8) int8 {
+	if color == White {
+		return pos.WhiteKingIdx()
+	}
+	return pos.BlackKingIdx()
+}
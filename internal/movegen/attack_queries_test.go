@@ -0,0 +1,123 @@
+package movegen
+
+import (
+	board "chessV2/internal/board"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttackQueries(t *testing.T) {
+	data := map[string]struct {
+		fen       string
+		assertion func(t *testing.T, pos *Position)
+	}{
+		"attackers of f3 in the start position": {
+			fen: FenStartPos,
+			assertion: func(t *testing.T, pos *Position) {
+				expected := uint64(1)<<E2 | uint64(1)<<G2 | uint64(1)<<G1
+				assert.Equal(t, expected, AttackersTo(pos, F3, White))
+				assert.Equal(t, uint64(0), AttackersTo(pos, F3, Black))
+			},
+		},
+		"knight pinned by a rook": {
+			fen: "4k3/4r3/8/8/8/8/4N3/4K3 w - - 0 1",
+			assertion: func(t *testing.T, pos *Position) {
+				assert.Equal(t, uint64(1)<<E2, PinnedPieces(pos, White))
+				assert.Equal(t, uint64(0), PinnedPieces(pos, Black))
+				assert.Equal(t, uint64(0), DiscoveredCheckCandidates(pos, White))
+			},
+		},
+		"two blockers do not pin": {
+			fen: "4k3/4r3/8/8/4P3/8/4N3/4K3 w - - 0 1",
+			assertion: func(t *testing.T, pos *Position) {
+				assert.Equal(t, uint64(0), PinnedPieces(pos, White))
+			},
+		},
+		"knight masking a rook on the enemy king": {
+			fen: "4k3/8/8/8/8/8/4N3/4R1K1 w - - 0 1",
+			assertion: func(t *testing.T, pos *Position) {
+				assert.Equal(t, uint64(1)<<E2, DiscoveredCheckCandidates(pos, White))
+				assert.Equal(t, uint64(0), PinnedPieces(pos, Black))
+			},
+		},
+		"check squares around the black king": {
+			fen: "4k3/8/8/8/8/8/8/4K3 w - - 0 1",
+			assertion: func(t *testing.T, pos *Position) {
+				knightSquares := uint64(1)<<D6 | uint64(1)<<F6 | uint64(1)<<C7 | uint64(1)<<G7
+				assert.Equal(t, knightSquares, CheckSquares(pos, White, Knight))
+				assert.Equal(t, uint64(1)<<D7|uint64(1)<<F7, CheckSquares(pos, White, Pawn))
+				assert.Equal(t, uint64(1)<<D2|uint64(1)<<F2, CheckSquares(pos, Black, Pawn))
+				assert.Equal(t, uint64(0), CheckSquares(pos, White, King))
+				queenSquares := CheckSquares(pos, White, Queen)
+				assert.Equal(t, CheckSquares(pos, White, Rook)|CheckSquares(pos, White, Bishop), queenSquares)
+				assert.NotZero(t, queenSquares&(uint64(1)<<E2))
+			},
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			pos, err := NewPositionFromFEN(d.fen)
+			assert.NoError(t, err)
+			d.assertion(t, pos)
+		})
+	}
+}
+
+// TestAttackQueriesMatchPerPieceMasks checks the bitboard queries against
+// PieceAttackMask and the legal generator's own pin analysis.
+func TestAttackQueriesMatchPerPieceMasks(t *testing.T) {
+	generator := NewPseudoLegalMoveGenerator()
+	updater := board.NewPositionUpdater()
+
+	var check func(t *testing.T, pos *Position, depth int)
+	check = func(t *testing.T, pos *Position, depth int) {
+		for _, color := range [2]int8{White, Black} {
+			var attackers [64]uint64
+			attacked := uint64(0)
+			for idx := int8(0); idx < 64; idx++ {
+				piece := pos.PieceAt(idx)
+				if piece == NoPiece || piece.Color() != color {
+					continue
+				}
+				mask := PieceAttackMask(pos, piece, idx)
+				attacked |= mask
+				for sq := int8(0); sq < 64; sq++ {
+					if mask&(uint64(1)<<sq) != 0 {
+						attackers[sq] |= uint64(1) << idx
+					}
+				}
+			}
+
+			assert.Equal(t, attacked, AttackedSquares(pos, color), pos.FEN())
+			for sq := int8(0); sq < 64; sq++ {
+				assert.Equal(t, attackers[sq], AttackersTo(pos, sq, color), "%s on %s", pos.FEN(), board.IdxToSquare(sq))
+			}
+		}
+
+		color := pos.ActiveColor()
+		var info positionAnalysis
+		computePositionAnalysis(pos, kingSquare(pos, color), pos.OccupancyMask(color), pos.OccupancyMask(pos.OpponentColor()), &info)
+		assert.Equal(t, info.pinnedMask, PinnedPieces(pos, color), pos.FEN())
+
+		if depth == 0 {
+			return
+		}
+		var moves [256]Move
+		count := generator.LegalMovesInto(pos, updater, moves[:])
+		for _, move := range moves[:count] {
+			history := updater.MakeMove(pos, move)
+			check(t, pos, depth-1)
+			updater.UnMakeMove(pos, history)
+		}
+	}
+
+	for _, fen := range stagedGenerationFENs {
+		t.Run(fen, func(t *testing.T) {
+			pos, err := NewPositionFromFEN(fen)
+			assert.NoError(t, err)
+			check(t, pos, 1)
+		})
+	}
+}
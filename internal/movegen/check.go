@@ -180,3 +180,61 @@ func IsKingInCheck(pos *Position, kingColor int8) bool {
 
 	return isAttacked
 }
+
+// GivesCheck reports whether the legal move leaves the opponent in check,
+// without playing it. Pawn and knight checks are read from the target
+// square; every slider check, direct or discovered, including promotions, en
+// passant and the castling rook, falls out of re-scanning the enemy king's
+// lines with the occupancy after the move.
+func GivesCheck(pos *Position, move Move) bool {
+	ensureAttackTables()
+
+	color := move.Piece().Color()
+	enemyKing := kingSquare(pos, color^(White|Black))
+	enemyKingMask := uint64(1) << enemyKing
+	from, to := move.StartIdx(), move.EndIdx()
+
+	sliders := pos.OccupancyMask(color) &^ (uint64(1) << from)
+	rookQueens := (pos.RookBoard() | pos.QueenBoard()) & sliders
+	bishopQueens := (pos.BishopBoard() | pos.QueenBoard()) & sliders
+	occ := pos.Occupied()&^(uint64(1)<<from) | uint64(1)<<to
+
+	pieceType := move.Piece().Type()
+	switch move.Flag() {
+	case board.Castle:
+		kingTo, rookFrom, rookTo := pos.CastleMoveSquares(move)
+		occ = pos.Occupied()&^(uint64(1)<<from|uint64(1)<<rookFrom) | uint64(1)<<kingTo | uint64(1)<<rookTo
+		rookQueens = rookQueens&^(uint64(1)<<rookFrom) | uint64(1)<<rookTo
+	case board.EnPassant:
+		capturedIdx := to - 8
+		if color == Black {
+			capturedIdx = to + 8
+		}
+		occ &^= uint64(1) << capturedIdx
+	default:
+		if promoted := promotionPieceType(move.Flag()); promoted != 0 {
+			pieceType = promoted
+		}
+	}
+
+	switch pieceType {
+	case Pawn:
+		if pawnAttackMask(color, to)&enemyKingMask != 0 {
+			return true
+		}
+	case Knight:
+		if knightAttacksMask[to]&enemyKingMask != 0 {
+			return true
+		}
+	case Bishop:
+		bishopQueens |= uint64(1) << to
+	case Rook:
+		rookQueens |= uint64(1) << to
+	case Queen:
+		bishopQueens |= uint64(1) << to
+		rookQueens |= uint64(1) << to
+	}
+
+	return rookAttacksMagic(enemyKing, occ)&rookQueens != 0 ||
+		bishopAttacksMagic(enemyKing, occ)&bishopQueens != 0
+}
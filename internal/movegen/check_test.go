@@ -1,8 +1,10 @@
 package movegen
 
 import (
-	"github.com/stretchr/testify/assert"
+	board "chessV2/internal/board"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestIsSquareAttackedByPawn(t *testing.T) {
@@ -462,3 +464,84 @@ func TestIsDiagonallyAttacked(t *testing.T) {
 		})
 	}
 }
+
+func TestGivesCheck(t *testing.T) {
+	data := map[string]struct {
+		fen      string
+		chess960 bool
+		expected []string
+	}{
+		"discovered check by en passant": {
+			fen:      "8/1k6/8/3pP3/8/8/8/4K2B w - d6 0 1",
+			expected: []string{"e5d6", "h1d5"},
+		},
+		"check through the captured en passant pawn": {
+			fen:      "8/8/8/k2pP2R/8/8/8/4K3 w - d6 0 1",
+			expected: []string{"e5d6"},
+		},
+		"knight promotion check": {
+			fen:      "8/4P3/3k4/8/8/8/8/4K3 w - - 0 1",
+			expected: []string{"e7e8n"},
+		},
+		"castle checks with the rook": {
+			fen:      "5k2/8/8/8/8/8/8/4K2R w K - 0 1",
+			expected: []string{"e1g1", "h1f1", "h1h8"},
+		},
+		"chess960 castle checks with the rook": {
+			fen:      "3k4/8/8/8/8/8/8/1RK5 w B - 0 1",
+			chess960: true,
+			expected: []string{"c1b1", "b1b8"},
+		},
+	}
+
+	generator := NewPseudoLegalMoveGenerator()
+	updater := board.NewPositionUpdater()
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			pos, err := NewPositionFromFEN(d.fen)
+			assert.NoError(t, err)
+			pos.SetChess960(d.chess960)
+
+			var moves [256]Move
+			count := generator.LegalMovesInto(pos, updater, moves[:])
+			checks := make([]string, 0)
+			for _, move := range moves[:count] {
+				if GivesCheck(pos, move) {
+					checks = append(checks, move.UCI())
+				}
+			}
+			assert.ElementsMatch(t, d.expected, checks)
+		})
+	}
+}
+
+// TestGivesCheckMatchesMakeMove plays every move three plies deep from the
+// perft positions and compares GivesCheck with the king test after the move.
+func TestGivesCheckMatchesMakeMove(t *testing.T) {
+	generator := NewPseudoLegalMoveGenerator()
+	updater := board.NewPositionUpdater()
+
+	var check func(t *testing.T, pos *Position, depth int)
+	check = func(t *testing.T, pos *Position, depth int) {
+		var moves [256]Move
+		count := generator.LegalMovesInto(pos, updater, moves[:])
+		for _, move := range moves[:count] {
+			givesCheck := GivesCheck(pos, move)
+			history := updater.MakeMove(pos, move)
+			assert.Equal(t, IsKingInCheck(pos, pos.ActiveColor()), givesCheck, "%s after %s", pos.FEN(), move.UCI())
+			if depth > 0 {
+				check(t, pos, depth-1)
+			}
+			updater.UnMakeMove(pos, history)
+		}
+	}
+
+	for _, fen := range stagedGenerationFENs {
+		t.Run(fen, func(t *testing.T) {
+			pos, err := NewPositionFromFEN(fen)
+			assert.NoError(t, err)
+			check(t, pos, 2)
+		})
+	}
+}
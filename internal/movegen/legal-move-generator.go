@@ -5,6 +5,30 @@ import (
 	"math/bits"
 )
 
+// genKind selects which part of the legal move list a generator call writes.
+// genCaptures and genQuiets split the list in two: captures, en passant and
+// every promotion on one side, all other moves including castles on the
+// other. genEvasions is the full list, but only when the side to move is in
+// check.
+type genKind uint8
+
+const (
+	genAll genKind = iota
+	genCaptures
+	genQuiets
+	genEvasions
+)
+
+func appendPieceMoves(piece Piece, startIdx int8, targets uint64, enemyOcc uint64, kind genKind, dst []Move, count int) int {
+	if kind != genCaptures {
+		count = appendNormalMovesFromMask(piece, startIdx, targets&^enemyOcc, dst, count)
+	}
+	if kind != genQuiets {
+		count = appendCaptureMovesFromMask(piece, startIdx, targets&enemyOcc, dst, count)
+	}
+	return count
+}
+
 func appendNormalMovesFromMask(piece Piece, startIdx int8, targets uint64, dst []Move, count int) int {
 	for targets != 0 {
 		targetIdx := int8(bits.TrailingZeros64(targets))
@@ -72,7 +96,7 @@ const (
 	notHFile uint64 = 0x7F7F7F7F7F7F7F7F
 )
 
-func (g *PseudoLegalMoveGenerator) appendKingMoves(pos *Position, piece Piece, kingIdx int8, enemyColor int8, enemyKingMask uint64, info *positionAnalysis, dst []Move, count int) int {
+func (g *PseudoLegalMoveGenerator) appendKingMoves(pos *Position, piece Piece, kingIdx int8, enemyColor int8, enemyKingMask uint64, info *positionAnalysis, kind genKind, dst []Move, count int) int {
 	friendlyOcc := pos.OccupancyMask(pos.ActiveColor())
 	targets := kingAttacksMask[kingIdx] &^ friendlyOcc &^ enemyKingMask
 	occWithoutKing := pos.Occupied() &^ (uint64(1) << kingIdx)
@@ -83,6 +107,12 @@ func (g *PseudoLegalMoveGenerator) appendKingMoves(pos *Position, piece Piece, k
 	} else {
 		enemyOcc = pos.BlackOccupied()
 	}
+	switch kind {
+	case genCaptures:
+		targets &= enemyOcc
+	case genQuiets:
+		targets &^= enemyOcc
+	}
 
 	enemyPawns := pos.PawnBoard() & enemyOcc
 	var pawnAttacks uint64
@@ -132,50 +162,75 @@ func (g *PseudoLegalMoveGenerator) appendKingMoves(pos *Position, piece Piece, k
 		count++
 	}
 
-	if info.inCheck {
+	if info.inCheck || kind == genCaptures {
 		return count
 	}
 
+	return appendCastleMoves(pos, piece, kingIdx, enemyColor, dst, count)
+}
+
+// appendCastleMoves handles standard and Chess960 castling: every square
+// between the king and its destination and between the rook and its
+// destination must be empty apart from those two pieces, and no square the
+// king crosses or lands on may be attacked once the castling rook has left,
+// which also rejects a castling rook pinned along the back rank.
+func appendCastleMoves(pos *Position, piece Piece, kingIdx int8, enemyColor int8, dst []Move, count int) int {
+	color := pos.ActiveColor()
 	castleRights := pos.CastleRights()
-	if pos.ActiveColor() == White && kingIdx == E1 {
-		if castleRights&KingSideCastle != 0 &&
-			pos.PieceAt(F1) == NoPiece && pos.PieceAt(G1) == NoPiece &&
-			!isSquareAttacked(pos, F1, enemyColor, occWithoutKing) &&
-			!isSquareAttacked(pos, G1, enemyColor, occWithoutKing&^(uint64(1)<<G1)) {
-			dst[count] = board.NewMove(piece, E1, G1, board.Castle)
-			count++
-		}
-		if castleRights&QueenSideCastle != 0 &&
-			pos.PieceAt(B1) == NoPiece && pos.PieceAt(C1) == NoPiece && pos.PieceAt(D1) == NoPiece &&
-			!isSquareAttacked(pos, D1, enemyColor, occWithoutKing) &&
-			!isSquareAttacked(pos, C1, enemyColor, occWithoutKing&^(uint64(1)<<C1)) {
-			dst[count] = board.NewMove(piece, E1, C1, board.Castle)
-			count++
-		}
+	if castleRights == NoCastle {
 		return count
 	}
 
-	if pos.ActiveColor() == Black && kingIdx == E8 {
-		if castleRights&KingSideCastle != 0 &&
-			pos.PieceAt(F8) == NoPiece && pos.PieceAt(G8) == NoPiece &&
-			!isSquareAttacked(pos, F8, enemyColor, occWithoutKing) &&
-			!isSquareAttacked(pos, G8, enemyColor, occWithoutKing&^(uint64(1)<<G8)) {
-			dst[count] = board.NewMove(piece, E8, G8, board.Castle)
-			count++
+	for _, side := range [2]int8{KingSideCastle, QueenSideCastle} {
+		if castleRights&side == 0 {
+			continue
 		}
-		if castleRights&QueenSideCastle != 0 &&
-			pos.PieceAt(B8) == NoPiece && pos.PieceAt(C8) == NoPiece && pos.PieceAt(D8) == NoPiece &&
-			!isSquareAttacked(pos, D8, enemyColor, occWithoutKing) &&
-			!isSquareAttacked(pos, C8, enemyColor, occWithoutKing&^(uint64(1)<<C8)) {
-			dst[count] = board.NewMove(piece, E8, C8, board.Castle)
-			count++
+
+		rookIdx := pos.CastleRookIdx(color, side)
+		if pos.PieceAt(rookIdx) != Piece(color|Rook) || board.RankFromIdx(rookIdx) != board.RankFromIdx(kingIdx) {
+			continue
+		}
+
+		kingEndIdx, rookEndIdx := board.CastleDestinations(color, side)
+		occ := pos.Occupied() &^ (uint64(1)<<kingIdx | uint64(1)<<rookIdx)
+		if occ&(rankSpan(kingIdx, kingEndIdx)|rankSpan(rookIdx, rookEndIdx)) != 0 {
+			continue
+		}
+
+		safe := true
+		kingPath := rankSpan(kingIdx, kingEndIdx)&^(uint64(1)<<kingIdx) | uint64(1)<<kingEndIdx
+		for kingPath != 0 {
+			sq := int8(bits.TrailingZeros64(kingPath))
+			kingPath &^= 1 << sq
+			if isSquareAttacked(pos, sq, enemyColor, occ) {
+				safe = false
+				break
+			}
+		}
+		if !safe {
+			continue
 		}
+
+		endIdx := kingEndIdx
+		if pos.Chess960() {
+			endIdx = rookIdx
+		}
+		dst[count] = board.NewMove(piece, kingIdx, endIdx, board.Castle)
+		count++
 	}
 
 	return count
 }
 
-func (g *PseudoLegalMoveGenerator) appendPawnMoves(pos *Position, positionUpdater board.MoveApplier, piece Piece, idx int8, enemyOcc uint64, info *positionAnalysis, pinRay uint64, isPinned bool, inCheckColor int8, dst []Move, count int) int {
+// rankSpan returns the squares from a to b inclusive; both must share a rank.
+func rankSpan(a, b int8) uint64 {
+	if a > b {
+		a, b = b, a
+	}
+	return (uint64(1)<<(b-a+1) - 1) << a
+}
+
+func (g *PseudoLegalMoveGenerator) appendPawnMoves(pos *Position, positionUpdater board.MoveApplier, piece Piece, idx int8, enemyOcc uint64, info *positionAnalysis, pinRay uint64, isPinned bool, inCheckColor int8, kind genKind, dst []Move, count int) int {
 	var quietTargets uint64
 	var captureTargets uint64
 	var promotionTargets uint64
@@ -231,7 +286,12 @@ func (g *PseudoLegalMoveGenerator) appendPawnMoves(pos *Position, positionUpdate
 	quietTargets &^= promotionTargets
 	captureTargets &^= promotionTargets
 
-	count = appendPawnQuietMoves(piece, idx, quietTargets, dst, count)
+	if kind != genCaptures {
+		count = appendPawnQuietMoves(piece, idx, quietTargets, dst, count)
+	}
+	if kind == genQuiets {
+		return count
+	}
 	count = appendCaptureMovesFromMask(piece, idx, captureTargets, dst, count)
 	count = appendPromotionMoves(piece, idx, promotionTargets, dst, count)
 
@@ -252,7 +312,7 @@ func (g *PseudoLegalMoveGenerator) appendPawnMoves(pos *Position, positionUpdate
 	return count
 }
 
-func (g *PseudoLegalMoveGenerator) appendNonKingMovesNoCheck(pos *Position, positionUpdater board.MoveApplier, piecesMask uint64, friendlyOcc uint64, enemyOccNoKing uint64, enemyKingMask uint64, info *positionAnalysis, dst []Move, count int) int {
+func (g *PseudoLegalMoveGenerator) appendNonKingMovesNoCheck(pos *Position, positionUpdater board.MoveApplier, piecesMask uint64, friendlyOcc uint64, enemyOccNoKing uint64, enemyKingMask uint64, info *positionAnalysis, kind genKind, dst []Move, count int) int {
 	for piecesMask != 0 {
 		idx := int8(bits.TrailingZeros64(piecesMask))
 		piecesMask &^= 1 << idx
@@ -263,27 +323,25 @@ func (g *PseudoLegalMoveGenerator) appendNonKingMovesNoCheck(pos *Position, posi
 
 		switch pieceType {
 		case Pawn:
-			count = g.appendPawnMoves(pos, positionUpdater, piece, idx, enemyOccNoKing, info, pinRay, info.pinnedMask&(1<<idx) != 0, pos.ActiveColor(), dst, count)
+			count = g.appendPawnMoves(pos, positionUpdater, piece, idx, enemyOccNoKing, info, pinRay, info.pinnedMask&(1<<idx) != 0, pos.ActiveColor(), kind, dst, count)
 		case Knight:
 			if pinRay != 0 {
 				continue
 			}
 			targets := knightAttacksMask[idx] &^ friendlyOcc &^ enemyKingMask
-			count = appendNormalMovesFromMask(piece, idx, targets&^enemyOccNoKing, dst, count)
-			count = appendCaptureMovesFromMask(piece, idx, targets&enemyOccNoKing, dst, count)
+			count = appendPieceMoves(piece, idx, targets, enemyOccNoKing, kind, dst, count)
 		case Bishop, Rook, Queen:
 			targets := sliderTargetsMask(pos, idx, pieceType, friendlyOcc) &^ enemyKingMask
 			if pinRay != 0 {
 				targets &= pinRay
 			}
-			count = appendNormalMovesFromMask(piece, idx, targets&^enemyOccNoKing, dst, count)
-			count = appendCaptureMovesFromMask(piece, idx, targets&enemyOccNoKing, dst, count)
+			count = appendPieceMoves(piece, idx, targets, enemyOccNoKing, kind, dst, count)
 		}
 	}
 	return count
 }
 
-func (g *PseudoLegalMoveGenerator) appendNonKingMovesNoCheckNoPins(pos *Position, positionUpdater board.MoveApplier, piecesMask uint64, friendlyOcc uint64, enemyOccNoKing uint64, enemyKingMask uint64, dst []Move, count int) int {
+func (g *PseudoLegalMoveGenerator) appendNonKingMovesNoCheckNoPins(pos *Position, positionUpdater board.MoveApplier, piecesMask uint64, friendlyOcc uint64, enemyOccNoKing uint64, enemyKingMask uint64, kind genKind, dst []Move, count int) int {
 	for piecesMask != 0 {
 		idx := int8(bits.TrailingZeros64(piecesMask))
 		piecesMask &^= 1 << idx
@@ -291,21 +349,19 @@ func (g *PseudoLegalMoveGenerator) appendNonKingMovesNoCheckNoPins(pos *Position
 		piece := pos.PieceAt(idx)
 		switch piece.Type() {
 		case Pawn:
-			count = g.appendPawnMoves(pos, positionUpdater, piece, idx, enemyOccNoKing, nil, 0, false, pos.ActiveColor(), dst, count)
+			count = g.appendPawnMoves(pos, positionUpdater, piece, idx, enemyOccNoKing, nil, 0, false, pos.ActiveColor(), kind, dst, count)
 		case Knight:
 			targets := knightAttacksMask[idx] &^ friendlyOcc &^ enemyKingMask
-			count = appendNormalMovesFromMask(piece, idx, targets&^enemyOccNoKing, dst, count)
-			count = appendCaptureMovesFromMask(piece, idx, targets&enemyOccNoKing, dst, count)
+			count = appendPieceMoves(piece, idx, targets, enemyOccNoKing, kind, dst, count)
 		case Bishop, Rook, Queen:
 			targets := sliderTargetsMask(pos, idx, piece.Type(), friendlyOcc) &^ enemyKingMask
-			count = appendNormalMovesFromMask(piece, idx, targets&^enemyOccNoKing, dst, count)
-			count = appendCaptureMovesFromMask(piece, idx, targets&enemyOccNoKing, dst, count)
+			count = appendPieceMoves(piece, idx, targets, enemyOccNoKing, kind, dst, count)
 		}
 	}
 	return count
 }
 
-func (g *PseudoLegalMoveGenerator) appendNonKingMovesInCheck(pos *Position, positionUpdater board.MoveApplier, piecesMask uint64, friendlyOcc uint64, enemyOccNoKing uint64, enemyKingMask uint64, info *positionAnalysis, dst []Move, count int) int {
+func (g *PseudoLegalMoveGenerator) appendNonKingMovesInCheck(pos *Position, positionUpdater board.MoveApplier, piecesMask uint64, friendlyOcc uint64, enemyOccNoKing uint64, enemyKingMask uint64, info *positionAnalysis, kind genKind, dst []Move, count int) int {
 	evasionMask := info.evasionMask
 	for piecesMask != 0 {
 		idx := int8(bits.TrailingZeros64(piecesMask))
@@ -317,27 +373,62 @@ func (g *PseudoLegalMoveGenerator) appendNonKingMovesInCheck(pos *Position, posi
 
 		switch pieceType {
 		case Pawn:
-			count = g.appendPawnMoves(pos, positionUpdater, piece, idx, enemyOccNoKing, info, pinRay, info.pinnedMask&(1<<idx) != 0, pos.ActiveColor(), dst, count)
+			count = g.appendPawnMoves(pos, positionUpdater, piece, idx, enemyOccNoKing, info, pinRay, info.pinnedMask&(1<<idx) != 0, pos.ActiveColor(), kind, dst, count)
 		case Knight:
 			if pinRay != 0 {
 				continue
 			}
 			targets := knightAttacksMask[idx] &^ friendlyOcc &^ enemyKingMask & evasionMask
-			count = appendNormalMovesFromMask(piece, idx, targets&^enemyOccNoKing, dst, count)
-			count = appendCaptureMovesFromMask(piece, idx, targets&enemyOccNoKing, dst, count)
+			count = appendPieceMoves(piece, idx, targets, enemyOccNoKing, kind, dst, count)
 		case Bishop, Rook, Queen:
 			targets := sliderTargetsMask(pos, idx, pieceType, friendlyOcc) &^ enemyKingMask & evasionMask
 			if pinRay != 0 {
 				targets &= pinRay
 			}
-			count = appendNormalMovesFromMask(piece, idx, targets&^enemyOccNoKing, dst, count)
-			count = appendCaptureMovesFromMask(piece, idx, targets&enemyOccNoKing, dst, count)
+			count = appendPieceMoves(piece, idx, targets, enemyOccNoKing, kind, dst, count)
 		}
 	}
 	return count
 }
 
 func (g *PseudoLegalMoveGenerator) LegalMovesInto(pos *Position, positionUpdater board.MoveApplier, dst []Move) int {
+	return g.legalMovesInto(pos, positionUpdater, genAll, dst)
+}
+
+// CapturesInto writes the legal captures, en passant captures and promotions,
+// quiet promotions included, to dst and returns how many were written.
+func (g *PseudoLegalMoveGenerator) CapturesInto(pos *Position, positionUpdater board.MoveApplier, dst []Move) int {
+	return g.legalMovesInto(pos, positionUpdater, genCaptures, dst)
+}
+
+// QuietsInto writes the legal moves CapturesInto leaves out: non-capturing,
+// non-promoting moves and castles.
+func (g *PseudoLegalMoveGenerator) QuietsInto(pos *Position, positionUpdater board.MoveApplier, dst []Move) int {
+	return g.legalMovesInto(pos, positionUpdater, genQuiets, dst)
+}
+
+// EvasionsInto writes every legal move when the side to move is in check and
+// nothing otherwise.
+func (g *PseudoLegalMoveGenerator) EvasionsInto(pos *Position, positionUpdater board.MoveApplier, dst []Move) int {
+	return g.legalMovesInto(pos, positionUpdater, genEvasions, dst)
+}
+
+// QuietChecksInto writes the quiet moves that give check. It is meant for
+// positions where the side to move is not in check; evasions cover the rest.
+func (g *PseudoLegalMoveGenerator) QuietChecksInto(pos *Position, positionUpdater board.MoveApplier, dst []Move) int {
+	count := g.QuietsInto(pos, positionUpdater, dst)
+
+	checks := 0
+	for i := 0; i < count; i++ {
+		if GivesCheck(pos, dst[i]) {
+			dst[checks] = dst[i]
+			checks++
+		}
+	}
+	return checks
+}
+
+func (g *PseudoLegalMoveGenerator) legalMovesInto(pos *Position, positionUpdater board.MoveApplier, kind genKind, dst []Move) int {
 	count := 0
 	color := pos.ActiveColor()
 	kingIdx := pos.BlackKingIdx()
@@ -362,7 +453,13 @@ func (g *PseudoLegalMoveGenerator) LegalMovesInto(pos *Position, positionUpdater
 
 	var info positionAnalysis
 	computePositionAnalysis(pos, kingIdx, friendlyOcc, enemyOcc, &info)
-	count = g.appendKingMoves(pos, kingPiece, kingIdx, enemyColor, enemyKingMask, &info, dst, count)
+	if kind == genEvasions {
+		if info.checkerCount == 0 {
+			return 0
+		}
+		kind = genAll
+	}
+	count = g.appendKingMoves(pos, kingPiece, kingIdx, enemyColor, enemyKingMask, &info, kind, dst, count)
 
 	if info.checkerCount >= 2 {
 		return count
@@ -371,10 +468,10 @@ func (g *PseudoLegalMoveGenerator) LegalMovesInto(pos *Position, positionUpdater
 	piecesMask := friendlyOcc &^ (uint64(1) << kingIdx)
 	if info.checkerCount == 0 {
 		if info.pinnedMask == 0 {
-			return g.appendNonKingMovesNoCheckNoPins(pos, positionUpdater, piecesMask, friendlyOcc, enemyOccNoKing, enemyKingMask, dst, count)
+			return g.appendNonKingMovesNoCheckNoPins(pos, positionUpdater, piecesMask, friendlyOcc, enemyOccNoKing, enemyKingMask, kind, dst, count)
 		}
-		return g.appendNonKingMovesNoCheck(pos, positionUpdater, piecesMask, friendlyOcc, enemyOccNoKing, enemyKingMask, &info, dst, count)
+		return g.appendNonKingMovesNoCheck(pos, positionUpdater, piecesMask, friendlyOcc, enemyOccNoKing, enemyKingMask, &info, kind, dst, count)
 	}
 
-	return g.appendNonKingMovesInCheck(pos, positionUpdater, piecesMask, friendlyOcc, enemyOccNoKing, enemyKingMask, &info, dst, count)
+	return g.appendNonKingMovesInCheck(pos, positionUpdater, piecesMask, friendlyOcc, enemyOccNoKing, enemyKingMask, &info, kind, dst, count)
 }
@@ -0,0 +1,130 @@
+package movegen
+
+import (
+	board "chessV2/internal/board"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var stagedGenerationFENs = []string{
+	FenStartPos,
+	"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+	"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+	"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+	"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+	"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+	"bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9",
+}
+
+// TestStagedGeneratorsPartitionLegalMoves walks two plies from each position
+// and checks that captures and quiets split the legal list exactly, that
+// evasions appear only in check, and that quiet checks are the checking
+// quiets.
+func TestStagedGeneratorsPartitionLegalMoves(t *testing.T) {
+	generator := NewPseudoLegalMoveGenerator()
+	updater := board.NewPositionUpdater()
+
+	var check func(t *testing.T, pos *Position, depth int)
+	check = func(t *testing.T, pos *Position, depth int) {
+		var legal, captures, quiets, evasions, quietChecks [256]Move
+		legalCount := generator.LegalMovesInto(pos, updater, legal[:])
+		captureCount := generator.CapturesInto(pos, updater, captures[:])
+		quietCount := generator.QuietsInto(pos, updater, quiets[:])
+		evasionCount := generator.EvasionsInto(pos, updater, evasions[:])
+
+		staged := append(append([]Move{}, captures[:captureCount]...), quiets[:quietCount]...)
+		assert.ElementsMatch(t, legal[:legalCount], staged, pos.FEN())
+
+		for _, move := range captures[:captureCount] {
+			assert.True(t, isStagedTactical(pos, move), "%s is not tactical in %s", move.UCI(), pos.FEN())
+		}
+		for _, move := range quiets[:quietCount] {
+			assert.False(t, isStagedTactical(pos, move), "%s is tactical in %s", move.UCI(), pos.FEN())
+		}
+
+		inCheck := IsKingInCheck(pos, pos.ActiveColor())
+		if inCheck {
+			assert.ElementsMatch(t, legal[:legalCount], evasions[:evasionCount], pos.FEN())
+		} else {
+			assert.Equal(t, 0, evasionCount, pos.FEN())
+
+			expected := make([]Move, 0)
+			for _, move := range quiets[:quietCount] {
+				history := updater.MakeMove(pos, move)
+				if IsKingInCheck(pos, pos.ActiveColor()) {
+					expected = append(expected, move)
+				}
+				updater.UnMakeMove(pos, history)
+			}
+			quietCheckCount := generator.QuietChecksInto(pos, updater, quietChecks[:])
+			assert.ElementsMatch(t, expected, quietChecks[:quietCheckCount], pos.FEN())
+		}
+
+		if depth == 0 {
+			return
+		}
+		for _, move := range legal[:legalCount] {
+			history := updater.MakeMove(pos, move)
+			check(t, pos, depth-1)
+			updater.UnMakeMove(pos, history)
+		}
+	}
+
+	for _, fen := range stagedGenerationFENs {
+		t.Run(fen, func(t *testing.T) {
+			pos, err := NewPositionFromFEN(fen)
+			assert.NoError(t, err)
+			check(t, pos, 1)
+			assert.Equal(t, fen, pos.FEN())
+		})
+	}
+}
+
+func TestQuietChecksInto(t *testing.T) {
+	data := map[string]struct {
+		fen      string
+		expected []string
+	}{
+		"direct rook check": {
+			fen:      "4k3/8/8/8/8/8/8/R3K3 w - - 0 1",
+			expected: []string{"a1a8"},
+		},
+		"discovered check by a knight": {
+			fen:      "4k3/8/8/8/8/8/4N3/4R1K1 w - - 0 1",
+			expected: []string{"e2c1", "e2c3", "e2d4", "e2f4", "e2g3"},
+		},
+		"rook checks and castle check": {
+			fen:      "5k2/8/8/8/8/8/8/4K2R w K - 0 1",
+			expected: []string{"h1f1", "e1g1", "h1h8"},
+		},
+	}
+
+	generator := NewPseudoLegalMoveGenerator()
+	updater := board.NewPositionUpdater()
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			pos, err := NewPositionFromFEN(d.fen)
+			assert.NoError(t, err)
+
+			var moves [256]Move
+			count := generator.QuietChecksInto(pos, updater, moves[:])
+			uciMoves := make([]string, 0, count)
+			for _, move := range moves[:count] {
+				uciMoves = append(uciMoves, move.UCI())
+			}
+			assert.ElementsMatch(t, d.expected, uciMoves)
+		})
+	}
+}
+
+func isStagedTactical(pos *Position, move Move) bool {
+	switch move.Flag() {
+	case board.Capture, board.EnPassant, board.QueenPromotion, board.RookPromotion, board.BishopPromotion, board.KnightPromotion:
+		return true
+	case board.Castle:
+		return false
+	}
+	return pos.PieceAt(move.EndIdx()) != NoPiece
+}
@@ -0,0 +1,179 @@
+package movegen
+
+import board "chessV2/internal/board"
+
+// IsPseudoLegal reports whether move could be produced by the generator in
+// pos if king safety were ignored: the moving piece stands on its start
+// square and belongs to the side to move, the flag matches what the move
+// does, and the piece can reach the target. Castles are checked in full,
+// king safety included. Hash and killer moves taken from another position
+// go through this before being played.
+func IsPseudoLegal(pos *Position, move Move) bool {
+	from, to := move.StartIdx(), move.EndIdx()
+	if from < 0 || from > 63 || to < 0 || to > 63 || from == to {
+		return false
+	}
+
+	piece := move.Piece()
+	color := pos.ActiveColor()
+	if piece == NoPiece || pos.PieceAt(from) != piece || piece.Color() != color {
+		return false
+	}
+	ensureAttackTables()
+
+	if move.Flag() == board.Castle {
+		return piece.Type() == King && isCastleMoveLegal(pos, move)
+	}
+
+	target := pos.PieceAt(to)
+	if target != NoPiece && (target.Color() == color || target.Type() == King) {
+		return false
+	}
+
+	occ := pos.Occupied()
+	switch piece.Type() {
+	case Pawn:
+		return isPawnMovePseudoLegal(pos, move, target != NoPiece)
+	case Knight:
+		return isPlainFlagPseudoLegal(move.Flag(), target != NoPiece) && knightAttacksMask[from]&(uint64(1)<<to) != 0
+	case Bishop:
+		return isPlainFlagPseudoLegal(move.Flag(), target != NoPiece) && bishopAttacksMagic(from, occ)&(uint64(1)<<to) != 0
+	case Rook:
+		return isPlainFlagPseudoLegal(move.Flag(), target != NoPiece) && rookAttacksMagic(from, occ)&(uint64(1)<<to) != 0
+	case Queen:
+		return isPlainFlagPseudoLegal(move.Flag(), target != NoPiece) && (bishopAttacksMagic(from, occ)|rookAttacksMagic(from, occ))&(uint64(1)<<to) != 0
+	case King:
+		return isPlainFlagPseudoLegal(move.Flag(), target != NoPiece) && kingAttacksMask[from]&(uint64(1)<<to) != 0
+	}
+	return false
+}
+
+// IsLegal reports whether move is one of the legal moves in pos, without
+// generating them. It reuses the pin and check analysis of the legal
+// generator, so it is cheap enough to validate a transposition table or
+// killer move before the move list exists.
+func IsLegal(pos *Position, move Move) bool {
+	if !IsPseudoLegal(pos, move) {
+		return false
+	}
+	if move.Flag() == board.Castle {
+		return true
+	}
+
+	from, to := move.StartIdx(), move.EndIdx()
+	color := pos.ActiveColor()
+	enemyColor := color ^ (White | Black)
+	kingIdx := kingSquare(pos, color)
+
+	if from == kingIdx {
+		occ := pos.Occupied() &^ (uint64(1)<<from | uint64(1)<<to)
+		return !isSquareAttacked(pos, to, enemyColor, occ)
+	}
+	if move.Flag() == board.EnPassant {
+		return isEnPassantLegal(pos, move, kingIdx, enemyColor)
+	}
+
+	var info positionAnalysis
+	computePositionAnalysis(pos, kingIdx, pos.OccupancyMask(color), pos.OccupancyMask(enemyColor), &info)
+	if info.checkerCount > 1 {
+		return false
+	}
+	if info.checkerCount == 1 && info.evasionMask&(uint64(1)<<to) == 0 {
+		return false
+	}
+	if info.pinnedMask&(uint64(1)<<from) != 0 && info.pinRayBySq[from]&(uint64(1)<<to) == 0 {
+		return false
+	}
+	return true
+}
+
+func isPlainFlagPseudoLegal(flag int8, isCapture bool) bool {
+	if isCapture {
+		return flag == board.Capture
+	}
+	return flag == board.NormalMove
+}
+
+func isPawnMovePseudoLegal(pos *Position, move Move, isCapture bool) bool {
+	from, to := move.StartIdx(), move.EndIdx()
+	color := pos.ActiveColor()
+	occ := pos.Occupied()
+
+	forward := int8(8)
+	startRank := int8(1)
+	if color == Black {
+		forward = -8
+		startRank = 6
+	}
+
+	flag := move.Flag()
+	if flag == board.EnPassant {
+		return to == pos.EnPassantIdx() && pawnAttackMask(color, from)&(uint64(1)<<to) != 0
+	}
+
+	isPush := !isCapture && to == from+forward
+	isAttack := isCapture && pawnAttackMask(color, from)&(uint64(1)<<to) != 0
+	if promotionPieceType(flag) != 0 {
+		return isPromotionSquare(color, to) && (isPush || isAttack)
+	}
+	if isPromotionSquare(color, to) {
+		return false
+	}
+
+	switch flag {
+	case board.NormalMove:
+		return isPush
+	case board.Capture:
+		return isAttack
+	case board.PawnDoubleMove:
+		return !isCapture && board.RankFromIdx(from) == startRank && to == from+2*forward &&
+			occ&(uint64(1)<<(from+forward)) == 0
+	}
+	return false
+}
+
+// isCastleMoveLegal matches move against the castles appendCastleMoves
+// emits, which already covers rights, rook placement, empty squares and
+// attacked squares.
+func isCastleMoveLegal(pos *Position, move Move) bool {
+	color := pos.ActiveColor()
+	kingIdx := kingSquare(pos, color)
+	enemyColor := color ^ (White | Black)
+	if move.StartIdx() != kingIdx || isSquareAttacked(pos, kingIdx, enemyColor, pos.Occupied()) {
+		return false
+	}
+
+	var castles [2]Move
+	count := appendCastleMoves(pos, move.Piece(), kingIdx, enemyColor, castles[:], 0)
+	for _, castle := range castles[:count] {
+		if castle == move {
+			return true
+		}
+	}
+	return false
+}
+
+// isEnPassantLegal replays the capture on the occupancy: the captured pawn
+// leaves with the capturing one, which can expose the king along a rank as
+// well as along the pawn's own diagonal.
+func isEnPassantLegal(pos *Position, move Move, kingIdx int8, enemyColor int8) bool {
+	from, to := move.StartIdx(), move.EndIdx()
+	capturedIdx := to - 8
+	if enemyColor == White {
+		capturedIdx = to + 8
+	}
+
+	occ := pos.Occupied()&^(uint64(1)<<from|uint64(1)<<capturedIdx) | uint64(1)<<to
+	enemyOcc := pos.OccupancyMask(enemyColor) &^ (uint64(1) << capturedIdx)
+
+	pawnAttackers := pawnAttacksBy[1][kingIdx]
+	if enemyColor == White {
+		pawnAttackers = pawnAttacksBy[0][kingIdx]
+	}
+	if pawnAttackers&pos.PawnBoard()&enemyOcc != 0 || knightAttacksMask[kingIdx]&pos.KnightBoard()&enemyOcc != 0 {
+		return false
+	}
+	rookQueens := (pos.RookBoard() | pos.QueenBoard()) & enemyOcc
+	bishopQueens := (pos.BishopBoard() | pos.QueenBoard()) & enemyOcc
+	return rookAttacksMagic(kingIdx, occ)&rookQueens == 0 && bishopAttacksMagic(kingIdx, occ)&bishopQueens == 0
+}
@@ -0,0 +1,167 @@
+package movegen
+
+import (
+	board "chessV2/internal/board"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsLegal(t *testing.T) {
+	data := map[string]struct {
+		fen      string
+		move     Move
+		expected bool
+	}{
+		"start position knight move": {
+			fen:      FenStartPos,
+			move:     board.NewMove(Piece(White|Knight), G1, F3, board.NormalMove),
+			expected: true,
+		},
+		"wrong side to move": {
+			fen:      FenStartPos,
+			move:     board.NewMove(Piece(Black|Knight), G8, F6, board.NormalMove),
+			expected: false,
+		},
+		"double push flagged as a single push": {
+			fen:      FenStartPos,
+			move:     board.NewMove(Piece(White|Pawn), E2, E4, board.NormalMove),
+			expected: false,
+		},
+		"double push through a piece": {
+			fen:      "4k3/8/8/8/8/4n3/4P3/4K3 w - - 0 1",
+			move:     board.NewMove(Piece(White|Pawn), E2, E4, board.PawnDoubleMove),
+			expected: false,
+		},
+		"capture flag on an empty square": {
+			fen:      FenStartPos,
+			move:     board.NewMove(Piece(White|Knight), G1, F3, board.Capture),
+			expected: false,
+		},
+		"pinned knight": {
+			fen:      "4k3/4r3/8/8/8/8/4N3/4K3 w - - 0 1",
+			move:     board.NewMove(Piece(White|Knight), E2, C3, board.NormalMove),
+			expected: false,
+		},
+		"pinned rook along the pin": {
+			fen:      "4k3/4r3/8/8/8/8/4R3/4K3 w - - 0 1",
+			move:     board.NewMove(Piece(White|Rook), E2, E7, board.Capture),
+			expected: true,
+		},
+		"rook move that does not block a check": {
+			fen:      "4k3/4r3/8/8/8/8/8/3RK3 w - - 0 1",
+			move:     board.NewMove(Piece(White|Rook), D1, D4, board.NormalMove),
+			expected: false,
+		},
+		"interpose against a check": {
+			fen:      "4k3/4r3/8/8/8/8/3R4/4K3 w - - 0 1",
+			move:     board.NewMove(Piece(White|Rook), D2, E2, board.NormalMove),
+			expected: true,
+		},
+		"king steps along the checking ray": {
+			fen:      "4k3/4r3/8/8/8/8/8/4K3 w - - 0 1",
+			move:     board.NewMove(Piece(White|King), E1, E2, board.NormalMove),
+			expected: false,
+		},
+		"en passant exposing the king on the rank": {
+			fen:      "8/8/8/KPp4r/8/8/8/4k3 w - c6 0 1",
+			move:     board.NewMove(Piece(White|Pawn), B5, C6, board.EnPassant),
+			expected: false,
+		},
+		"en passant removing the checking pawn": {
+			fen:      "8/8/8/2pP4/1K6/8/8/4k3 w - c6 0 1",
+			move:     board.NewMove(Piece(White|Pawn), D5, C6, board.EnPassant),
+			expected: true,
+		},
+		"castle through an attacked square": {
+			fen:      "4k3/8/8/8/8/8/5r2/4K2R w K - 0 1",
+			move:     board.NewMove(Piece(White|King), E1, G1, board.Castle),
+			expected: false,
+		},
+		"castle": {
+			fen:      "4k3/8/8/8/8/8/8/4K2R w K - 0 1",
+			move:     board.NewMove(Piece(White|King), E1, G1, board.Castle),
+			expected: true,
+		},
+		"promotion without the promotion flag": {
+			fen:      "4k3/P7/8/8/8/8/8/4K3 w - - 0 1",
+			move:     board.NewMove(Piece(White|Pawn), A7, A8, board.NormalMove),
+			expected: false,
+		},
+		"capturing promotion": {
+			fen:      "1r2k3/P7/8/8/8/8/8/4K3 w - - 0 1",
+			move:     board.NewMove(Piece(White|Pawn), A7, B8, board.KnightPromotion),
+			expected: true,
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			pos, err := NewPositionFromFEN(d.fen)
+			assert.NoError(t, err)
+			assert.Equal(t, d.expected, IsLegal(pos, d.move))
+		})
+	}
+}
+
+// TestIsLegalMatchesLegalMoves plays random games from the generation test
+// positions and, in every position reached, checks IsLegal against the
+// legal move list for every piece, target and flag of the side to move and
+// for moves carried over from earlier positions.
+func TestIsLegalMatchesLegalMoves(t *testing.T) {
+	generator := NewPseudoLegalMoveGenerator()
+	updater := board.NewPositionUpdater()
+	rng := rand.New(rand.NewSource(1))
+
+	fens := append([]string{
+		"8/8/8/KPp4r/8/8/8/4k3 w - c6 0 1",
+		"8/8/8/2pP4/1K6/8/8/4k3 w - c6 0 1",
+		"4k3/8/8/8/8/8/5r2/R3K2R w KQ - 0 1",
+	}, stagedGenerationFENs...)
+
+	for _, fen := range fens {
+		t.Run(fen, func(t *testing.T) {
+			for game := 0; game < 16; game++ {
+				pos, err := NewPositionFromFEN(fen)
+				assert.NoError(t, err)
+
+				var carried []Move
+				for ply := 0; ply < 40; ply++ {
+					var moves [256]Move
+					count := generator.LegalMovesInto(pos, updater, moves[:])
+					legal := make(map[Move]bool, count)
+					for _, move := range moves[:count] {
+						legal[move] = true
+						assert.True(t, IsPseudoLegal(pos, move), "%s in %s", move.UCI(), pos.FEN())
+					}
+
+					candidates := carried
+					for from := int8(0); from < 64; from++ {
+						piece := pos.PieceAt(from)
+						if piece == NoPiece || piece.Color() != pos.ActiveColor() {
+							continue
+						}
+						for to := int8(0); to < 64; to++ {
+							for flag := int8(board.NormalMove); flag <= board.Capture; flag++ {
+								candidates = append(candidates, board.NewMove(piece, from, to, flag))
+							}
+						}
+					}
+					for _, move := range candidates {
+						if IsLegal(pos, move) != legal[move] {
+							assert.Fail(t, "IsLegal disagrees with the legal move list", "%s (flag %d) in %s", move.UCI(), move.Flag(), pos.FEN())
+							return
+						}
+					}
+
+					if count == 0 {
+						break
+					}
+					carried = append([]Move(nil), moves[:count]...)
+					updater.MakeMove(pos, moves[rng.Intn(count)])
+				}
+			}
+		})
+	}
+}
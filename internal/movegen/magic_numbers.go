@@ -0,0 +1,82 @@
+// Code generated by "magicgen -seed 1"; DO NOT EDIT.
+
+package movegen
+
+const (
+	rookMagicTableSize   = 102400
+	bishopMagicTableSize = 5248
+)
+
+var rookMagicNumbers = [64]uint64{
+	0x018010a040018000, 0x0040002000401001, 0x290010a841e00100, 0x29001000050900a0,
+	0x4080030400800800, 0x1200040200100801, 0x2200208200040851, 0x220000820425004c,
+	0x0104800740008020, 0x0420400020005000, 0x0844801000200480, 0x4004808008001000,
+	0x4009000410080100, 0x0003000400020900, 0x4804000810020104, 0x0074800641800900,
+	0x0862818014400020, 0x0040048020004480, 0x11a1010040200012, 0x0020828010000800,
+	0x0848808004020800, 0x4522808004000200, 0x0000010100020004, 0x400206000092411c,
+	0x818004444000a000, 0x0180a000c0005002, 0x000b104100200100, 0x24022202000a4010,
+	0x0100040080080080, 0x0002010200080490, 0x0180390400221098, 0x0410008200010044,
+	0x0310400089800020, 0x08c0804009002902, 0x1004402001001504, 0x0105021001000920,
+	0x0000040080800801, 0x0a02001002000804, 0x0108284204005041, 0x0008004082002411,
+	0x02802281c0028001, 0x0009044000910020, 0x0000200010008080, 0x0040201001010008,
+	0x8000080004008080, 0x3010400420080110, 0x0000414210040008, 0x0010348400460001,
+	0x0080002000401040, 0x0460200088400080, 0x8201822000100280, 0x0600100008008280,
+	0x00c0800800040080, 0x0024040080020080, 0x22c11a0108100c00, 0x0204008114104200,
+	0x8800800010290041, 0x0000401500228206, 0x8002a00011090041, 0x0000042008100101,
+	0x0283000800100205, 0x0002008810010402, 0x0490102200880104, 0x0800010920940042,
+}
+
+var rookMagicShifts = [64]uint8{
+	52, 53, 53, 53, 53, 53, 53, 52, 53, 54, 54, 54, 54, 54, 54, 53,
+	53, 54, 54, 54, 54, 54, 54, 53, 53, 54, 54, 54, 54, 54, 54, 53,
+	53, 54, 54, 54, 54, 54, 54, 53, 53, 54, 54, 54, 54, 54, 54, 53,
+	53, 54, 54, 54, 54, 54, 54, 53, 52, 53, 53, 53, 53, 53, 53, 52,
+}
+
+var rookMagicOffsets = [64]uint32{
+	0, 4096, 6144, 8192, 10240, 12288, 14336, 16384,
+	20480, 22528, 23552, 24576, 25600, 26624, 27648, 28672,
+	30720, 32768, 33792, 34816, 35840, 36864, 37888, 38912,
+	40960, 43008, 44032, 45056, 46080, 47104, 48128, 49152,
+	51200, 53248, 54272, 55296, 56320, 57344, 58368, 59392,
+	61440, 63488, 64512, 65536, 66560, 67584, 68608, 69632,
+	71680, 73728, 74752, 75776, 76800, 77824, 78848, 79872,
+	81920, 86016, 88064, 90112, 92160, 94208, 96256, 98304,
+}
+
+var bishopMagicNumbers = [64]uint64{
+	0x8040229e24002080, 0x4008589084004000, 0x001000c081000001, 0x1a84040088a00240,
+	0x0801104008021044, 0x0002080484040000, 0x0002048a09401000, 0x1001004202014040,
+	0x0424844404040408, 0x0000040812084200, 0x0012080240420000, 0x4044080681020029,
+	0x00000405a0050208, 0x0100082804904000, 0xcc01070082114000, 0x2010220084110901,
+	0x00400c1010212102, 0x800a802004810608, 0x109000180230c010, 0x0008400424010009,
+	0x400a800c00a00387, 0x0001008020a01000, 0x8001302482901000, 0x2100a10486051001,
+	0x4c10100104200220, 0x0001200010042140, 0x00040a0005080100, 0x4289080011004100,
+	0x4001001001004020, 0x1828020840900400, 0x0000852042080206, 0x0002102000841106,
+	0x32018808c0401009, 0x8052100280041804, 0x2009004800010801, 0xa012008020820200,
+	0x00104a0020020080, 0x0400980202004100, 0x0402042040910820, 0x0101010112020440,
+	0x0200a8080804c041, 0x0002350108046011, 0x0002060202008100, 0x1804004204808802,
+	0x10004208a4010200, 0x22d0600810410020, 0x0809410404000080, 0x0028081080800020,
+	0x414c210802100180, 0x1100808090112010, 0x1412c20100884104, 0x000018a042021041,
+	0x0036805002021009, 0x0462061002120419, 0x4008200114450001, 0x0810040808404600,
+	0x400082241202400a, 0x8040004202012020, 0x100090089c008800, 0x0013000000841104,
+	0x1104088404104402, 0x2000410960080084, 0x0802080810109200, 0x5810028204040212,
+}
+
+var bishopMagicShifts = [64]uint8{
+	58, 59, 59, 59, 59, 59, 59, 58, 59, 59, 59, 59, 59, 59, 59, 59,
+	59, 59, 57, 57, 57, 57, 59, 59, 59, 59, 57, 55, 55, 57, 59, 59,
+	59, 59, 57, 55, 55, 57, 59, 59, 59, 59, 57, 57, 57, 57, 59, 59,
+	59, 59, 59, 59, 59, 59, 59, 59, 58, 59, 59, 59, 59, 59, 59, 58,
+}
+
+var bishopMagicOffsets = [64]uint32{
+	0, 64, 96, 128, 160, 192, 224, 256,
+	320, 352, 384, 416, 448, 480, 512, 544,
+	576, 608, 640, 768, 896, 1024, 1152, 1184,
+	1216, 1248, 1280, 1408, 1920, 2432, 2560, 2592,
+	2624, 2656, 2688, 2816, 3328, 3840, 3968, 4000,
+	4032, 4064, 4096, 4224, 4352, 4480, 4608, 4640,
+	4672, 4704, 4736, 4768, 4800, 4832, 4864, 4896,
+	4928, 4992, 5024, 5056, 5088, 5120, 5152, 5184,
+}
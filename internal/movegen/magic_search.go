@@ -0,0 +1,228 @@
+package movegen
+
+import (
+	board "chessV2/internal/board"
+	"fmt"
+	"math/bits"
+	"math/rand"
+	"sort"
+)
+
+// MagicLayout selects how FindMagics sizes and places the attack tables of
+// the 64 squares of a slider. The zero value is the layout the package is
+// built with: each square indexes exactly as many bits as its relevant mask
+// has, and the tables are laid back to back.
+type MagicLayout struct {
+	// FixedShift gives every square the index width of the largest mask, 12
+	// bits for rooks and 9 for bishops, so lookups shift by a constant.
+	FixedShift bool
+	// Fancy lets the square tables overlap in the shared table wherever
+	// their entries agree or are unused.
+	Fancy bool
+}
+
+// maxMagicTries bounds the candidates FindMagic draws for one square.
+const maxMagicTries = 1 << 26
+
+// fancyMagicCandidates is how many valid magics a fancy layout tries per
+// square, keeping the one whose table packs lowest into the shared table.
+const fancyMagicCandidates = 8
+
+// MagicSet holds everything the magic lookup of one slider needs: square sq
+// looks up Table[Offsets[sq] + ((occ&mask)*Magics[sq])>>Shifts[sq]] in a
+// shared table of TableSize entries.
+type MagicSet struct {
+	PieceType int8
+	Magics    [64]uint64
+	Shifts    [64]uint8
+	Offsets   [64]uint32
+	TableSize int
+}
+
+func (l MagicLayout) String() string {
+	switch {
+	case l.FixedShift && l.Fancy:
+		return "fixed-shift fancy"
+	case l.FixedShift:
+		return "fixed-shift"
+	case l.Fancy:
+		return "fancy"
+	}
+	return "plain"
+}
+
+// EmbeddedMagics returns the magic set the package was built with.
+func EmbeddedMagics(pieceType int8) MagicSet {
+	if pieceType == Bishop {
+		return MagicSet{PieceType: Bishop, Magics: bishopMagicNumbers, Shifts: bishopMagicShifts, Offsets: bishopMagicOffsets, TableSize: bishopMagicTableSize}
+	}
+	return MagicSet{PieceType: Rook, Magics: rookMagicNumbers, Shifts: rookMagicShifts, Offsets: rookMagicOffsets, TableSize: rookMagicTableSize}
+}
+
+// FindMagics searches a magic for every square of pieceType, Rook or Bishop,
+// drawing candidates from rng, and lays the tables out as layout asks.
+func FindMagics(pieceType int8, layout MagicLayout, rng *rand.Rand) (MagicSet, error) {
+	ensureAttackTables()
+	set := MagicSet{PieceType: pieceType}
+
+	fixedBits := 12
+	if pieceType == Bishop {
+		fixedBits = 9
+	}
+	for sq := int8(0); sq < 64; sq++ {
+		indexBits := bits.OnesCount64(magicRelevantMask(sq, pieceType))
+		if layout.FixedShift {
+			indexBits = fixedBits
+		}
+		set.Shifts[sq] = uint8(64 - indexBits)
+	}
+
+	if layout.Fancy {
+		return set, packFancyMagics(&set, rng)
+	}
+
+	for sq := int8(0); sq < 64; sq++ {
+		magic, err := FindMagic(sq, pieceType, set.Shifts[sq], rng)
+		if err != nil {
+			return MagicSet{}, err
+		}
+		set.Magics[sq] = magic
+		set.Offsets[sq] = uint32(set.TableSize)
+		set.TableSize += 1 << (64 - set.Shifts[sq])
+	}
+	return set, nil
+}
+
+// FindMagic draws sparse random candidates until one maps every occupancy
+// of the square's relevant mask to an index of 64-shift bits without two
+// occupancies with different attacks sharing an index.
+func FindMagic(square int8, pieceType int8, shift uint8, rng *rand.Rand) (uint64, error) {
+	ensureAttackTables()
+	mask := magicRelevantMask(square, pieceType)
+	size := 1 << bits.OnesCount64(mask)
+	occupancies := make([]uint64, size)
+	attacks := make([]uint64, size)
+	for i := 0; i < size; i++ {
+		occupancies[i] = magicOccupancyFromIndex(i, mask)
+		attacks[i] = sliderAttacksFromOcc(square, occupancies[i], pieceType)
+	}
+
+	table := make([]uint64, 1<<(64-shift))
+	for try := 0; try < maxMagicTries; try++ {
+		magic := rng.Uint64() & rng.Uint64() & rng.Uint64()
+		if bits.OnesCount64((mask*magic)>>56) < 6 {
+			continue
+		}
+
+		clear(table)
+		found := true
+		for i, occ := range occupancies {
+			index := (occ * magic) >> shift
+			if table[index] != 0 && table[index] != attacks[i] {
+				found = false
+				break
+			}
+			table[index] = attacks[i]
+		}
+		if found {
+			return magic, nil
+		}
+	}
+	return 0, fmt.Errorf("no magic found for square %s with shift %d", board.IdxToSquare(square), shift)
+}
+
+// VerifyMagics builds the shared table of set and checks every occupancy of
+// every square against sliderAttacksFromOcc.
+func VerifyMagics(set MagicSet) error {
+	ensureAttackTables()
+	table := make([]uint64, set.TableSize)
+	for sq := int8(0); sq < 64; sq++ {
+		if int(set.Offsets[sq])+1<<(64-set.Shifts[sq]) > set.TableSize {
+			return fmt.Errorf("square %s: table window ends past %d entries", board.IdxToSquare(sq), set.TableSize)
+		}
+		mask := magicRelevantMask(sq, set.PieceType)
+		window := magicTableWindow(table, set.Offsets[sq], set.Shifts[sq])
+		if !fillMagicAttackTable(window, sq, mask, set.Magics[sq], set.Shifts[sq], set.PieceType) {
+			return fmt.Errorf("square %s: magic %#016x collides", board.IdxToSquare(sq), set.Magics[sq])
+		}
+	}
+
+	for sq := int8(0); sq < 64; sq++ {
+		mask := magicRelevantMask(sq, set.PieceType)
+		window := magicTableWindow(table, set.Offsets[sq], set.Shifts[sq])
+		for i := 0; i < 1<<bits.OnesCount64(mask); i++ {
+			occ := magicOccupancyFromIndex(i, mask)
+			expected := sliderAttacksFromOcc(sq, occ, set.PieceType)
+			if got := window[(occ*set.Magics[sq])>>set.Shifts[sq]]; got != expected {
+				return fmt.Errorf("square %s: occupancy %#016x looks up %#016x, want %#016x", board.IdxToSquare(sq), occ, got, expected)
+			}
+		}
+	}
+	return nil
+}
+
+// packFancyMagics places the largest tables first. For each square it tries
+// a few magics and keeps the one that fits at the lowest offset, where
+// fitting means every slot it uses is free or already holds the same
+// attacks.
+func packFancyMagics(set *MagicSet, rng *rand.Rand) error {
+	squares := make([]int8, 64)
+	for sq := range squares {
+		squares[sq] = int8(sq)
+	}
+	sort.SliceStable(squares, func(i, j int) bool {
+		return set.Shifts[squares[i]] < set.Shifts[squares[j]]
+	})
+
+	shared := make([]uint64, 0, 1<<17)
+	for _, sq := range squares {
+		mask := magicRelevantMask(sq, set.PieceType)
+		bestOffset, bestMagic := -1, uint64(0)
+		var best []uint64
+		for candidate := 0; candidate < fancyMagicCandidates; candidate++ {
+			magic, err := FindMagic(sq, set.PieceType, set.Shifts[sq], rng)
+			if err != nil {
+				return err
+			}
+			local := make([]uint64, 1<<(64-set.Shifts[sq]))
+			fillMagicAttackTable(local, sq, mask, magic, set.Shifts[sq], set.PieceType)
+			if offset := lowestFit(shared, local); bestOffset < 0 || offset+len(local) < bestOffset+len(best) {
+				bestOffset, bestMagic, best = offset, magic, local
+			}
+		}
+
+		if end := bestOffset + len(best); end > len(shared) {
+			shared = append(shared, make([]uint64, end-len(shared))...)
+		}
+		for i, attacks := range best {
+			if attacks != 0 {
+				shared[bestOffset+i] = attacks
+			}
+		}
+		set.Magics[sq] = bestMagic
+		set.Offsets[sq] = uint32(bestOffset)
+	}
+
+	set.TableSize = len(shared)
+	return nil
+}
+
+// lowestFit returns the first offset where local can overlay shared. Slots
+// past the end of shared are free.
+func lowestFit(shared []uint64, local []uint64) int {
+	for offset := 0; ; offset++ {
+		fits := true
+		for i, attacks := range local {
+			if offset+i >= len(shared) {
+				break
+			}
+			if attacks != 0 && shared[offset+i] != 0 && shared[offset+i] != attacks {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			return offset
+		}
+	}
+}
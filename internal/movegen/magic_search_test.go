@@ -0,0 +1,128 @@
+package movegen
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbeddedMagicsVerify(t *testing.T) {
+	assert.NoError(t, VerifyMagics(EmbeddedMagics(Rook)))
+	assert.NoError(t, VerifyMagics(EmbeddedMagics(Bishop)))
+}
+
+func TestVerifyMagicsRejectsBrokenSets(t *testing.T) {
+	data := map[string]struct {
+		change func(set *MagicSet)
+	}{
+		"colliding magic": {
+			change: func(set *MagicSet) { set.Magics[D4] = 1 },
+		},
+		"window past the table": {
+			change: func(set *MagicSet) { set.TableSize-- },
+		},
+		"overlapping windows": {
+			change: func(set *MagicSet) { set.Offsets[E4] = set.Offsets[D4] },
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			set := EmbeddedMagics(Bishop)
+			d.change(&set)
+			assert.Error(t, VerifyMagics(set))
+		})
+	}
+}
+
+func TestFindMagics(t *testing.T) {
+	data := map[string]struct {
+		layout       MagicLayout
+		expectedSize int
+	}{
+		"plain": {
+			layout:       MagicLayout{},
+			expectedSize: 5248,
+		},
+		"fixed shift": {
+			layout:       MagicLayout{FixedShift: true},
+			expectedSize: 64 * 512,
+		},
+		"fancy": {
+			layout: MagicLayout{Fancy: true},
+		},
+		"fixed shift fancy": {
+			layout: MagicLayout{FixedShift: true, Fancy: true},
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			set, err := FindMagics(Bishop, d.layout, rand.New(rand.NewSource(1)))
+			assert.NoError(t, err)
+			assert.NoError(t, VerifyMagics(set))
+			if d.expectedSize > 0 {
+				assert.Equal(t, d.expectedSize, set.TableSize)
+			}
+			if d.layout.FixedShift {
+				for _, shift := range set.Shifts {
+					assert.Equal(t, uint8(55), shift)
+				}
+			}
+			if d.layout.Fancy {
+				windows := 0
+				for _, shift := range set.Shifts {
+					windows += 1 << (64 - shift)
+				}
+				assert.LessOrEqual(t, set.TableSize, windows)
+			}
+		})
+	}
+}
+
+var magicLookupSink uint64
+
+// BenchmarkMagicLayouts searches rook and bishop magics for each layout and
+// times lookups over random occupancies, reporting the size of both shared
+// tables.
+func BenchmarkMagicLayouts(b *testing.B) {
+	layouts := []MagicLayout{{}, {FixedShift: true}, {Fancy: true}, {FixedShift: true, Fancy: true}}
+	for _, layout := range layouts {
+		b.Run(layout.String(), func(b *testing.B) {
+			rng := rand.New(rand.NewSource(1))
+			rook, err := FindMagics(Rook, layout, rng)
+			if err != nil {
+				b.Fatal(err)
+			}
+			bishop, err := FindMagics(Bishop, layout, rng)
+			if err != nil {
+				b.Fatal(err)
+			}
+			rookTable, bishopTable := buildMagicSetTable(rook), buildMagicSetTable(bishop)
+
+			occupancies := make([]uint64, 1024)
+			for i := range occupancies {
+				occupancies[i] = rng.Uint64() & rng.Uint64()
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				occ := occupancies[i&1023]
+				sq := int8(i & 63)
+				magicLookupSink ^= rookTable[rook.Offsets[sq]+uint32(((occ&rookMagicMasks[sq])*rook.Magics[sq])>>rook.Shifts[sq])]
+				magicLookupSink ^= bishopTable[bishop.Offsets[sq]+uint32(((occ&bishopMagicMasks[sq])*bishop.Magics[sq])>>bishop.Shifts[sq])]
+			}
+			b.ReportMetric(float64((rook.TableSize+bishop.TableSize)*8)/1024, "KiB")
+		})
+	}
+}
+
+func buildMagicSetTable(set MagicSet) []uint64 {
+	table := make([]uint64, set.TableSize)
+	for sq := int8(0); sq < 64; sq++ {
+		window := magicTableWindow(table, set.Offsets[sq], set.Shifts[sq])
+		fillMagicAttackTable(window, sq, magicRelevantMask(sq, set.PieceType), set.Magics[sq], set.Shifts[sq], set.PieceType)
+	}
+	return table
+}
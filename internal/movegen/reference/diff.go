@@ -0,0 +1,193 @@
+package reference
+
+import (
+	board "chessV2/internal/board"
+	"chessV2/internal/movegen"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Check compares the legal moves of generator with LegalMoves on pos, then
+// plays each of them with updater. After MakeMove the position must agree
+// with the one parsed back from its own FEN, Zobrist, pawn and material keys
+// included, and UnMakeMove must restore pos exactly. pos is left as it was.
+// The first difference is returned as an error naming the FEN and the move.
+func Check(pos *Position, generator *movegen.PseudoLegalMoveGenerator, updater board.MoveApplier) error {
+	var buf [256]Move
+	count := generator.LegalMovesInto(pos, updater, buf[:])
+	if missing, extra := diffMoves(buf[:count], LegalMoves(pos)); len(missing) > 0 || len(extra) > 0 {
+		return fmt.Errorf("%s: movegen misses [%s] and has extra [%s]", pos.FEN(), strings.Join(missing, " "), strings.Join(extra, " "))
+	}
+
+	fen := pos.FEN()
+	before := withoutCaches(pos)
+	for _, move := range buf[:count] {
+		history := updater.MakeMove(pos, move)
+		err := checkAgainstFEN(pos)
+		updater.UnMakeMove(pos, history)
+		if err != nil {
+			return fmt.Errorf("%s after %s: %w", fen, move.UCI(), err)
+		}
+		if withoutCaches(pos) != before {
+			return fmt.Errorf("%s: unmake of %s leaves %s", fen, move.UCI(), pos.FEN())
+		}
+	}
+	return nil
+}
+
+// withoutCaches copies pos with the lazily computed king safety cleared, so
+// that a cache filled by move generation does not count as a difference.
+func withoutCaches(pos *Position) Position {
+	copied := *pos
+	copied.SetKingSafety(board.White, board.NotCalculated)
+	copied.SetKingSafety(board.Black, board.NotCalculated)
+	return copied
+}
+
+// positionState is everything a position exposes that parsing its FEN must
+// reproduce.
+type positionState struct {
+	fen                                  string
+	zobrist, pawnKey, materialKey        uint64
+	occupied, white, black               uint64
+	pawns, knights, bishops, rooks       uint64
+	queens, kings                        uint64
+	whiteKing, blackKing                 int8
+	whiteCastles, blackCastles, epSquare int8
+}
+
+func stateOf(pos *Position) positionState {
+	return positionState{
+		fen:          pos.FEN(),
+		zobrist:      pos.ZobristKey(),
+		pawnKey:      pos.PawnKey(),
+		materialKey:  pos.MaterialKey(),
+		occupied:     pos.Occupied(),
+		white:        pos.WhiteOccupied(),
+		black:        pos.BlackOccupied(),
+		pawns:        pos.PawnBoard(),
+		knights:      pos.KnightBoard(),
+		bishops:      pos.BishopBoard(),
+		rooks:        pos.RookBoard(),
+		queens:       pos.QueenBoard(),
+		kings:        pos.KingBoard(),
+		whiteKing:    pos.WhiteKingIdx(),
+		blackKing:    pos.BlackKingIdx(),
+		whiteCastles: pos.WhiteCastleRights(),
+		blackCastles: pos.BlackCastleRights(),
+		epSquare:     pos.EnPassantIdx(),
+	}
+}
+
+// checkAgainstFEN catches incremental updates that drift from the board:
+// bitboards, king squares, castle rights or the hash keys.
+func checkAgainstFEN(pos *Position) error {
+	parsed, err := board.NewPositionFromFEN(pos.FEN())
+	if err != nil {
+		return err
+	}
+	parsed.SetChess960(pos.Chess960())
+
+	got, expected := stateOf(pos), stateOf(parsed)
+	if got != expected {
+		return fmt.Errorf("incremental state %+v differs from parsed %+v", got, expected)
+	}
+	return nil
+}
+
+// diffMoves returns the UCI and flag of the moves only want has and of
+// those only got has.
+func diffMoves(got []Move, want []Move) ([]string, []string) {
+	counts := make(map[Move]int, len(want))
+	for _, move := range want {
+		counts[move]++
+	}
+	for _, move := range got {
+		counts[move]--
+	}
+
+	missing := make([]string, 0)
+	extra := make([]string, 0)
+	for move, count := range counts {
+		name := fmt.Sprintf("%s/%d", move.UCI(), move.Flag())
+		for ; count > 0; count-- {
+			missing = append(missing, name)
+		}
+		for ; count < 0; count++ {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(extra)
+	return missing, extra
+}
+
+// Shrink looks for a smaller position on which failing still holds by
+// removing pieces other than the kings, castle rights, the en passant square
+// and the move clocks one at a time, keeping every change that still fails
+// and passes Validate, until none does. It returns the FEN of the smallest
+// failing position found.
+func Shrink(pos *Position, failing func(*Position) bool) string {
+	fen := pos.FEN()
+	chess960 := pos.Chess960()
+
+	for shrunk := true; shrunk; {
+		shrunk = false
+		for _, candidate := range shrinkCandidates(fen) {
+			candidatePos, err := board.NewValidPositionFromFEN(candidate)
+			if err != nil {
+				continue
+			}
+			if chess960 {
+				candidatePos.SetChess960(true)
+			}
+			if failing(candidatePos) {
+				fen = candidatePos.FEN()
+				shrunk = true
+				break
+			}
+		}
+	}
+	return fen
+}
+
+func shrinkCandidates(fen string) []string {
+	fields := strings.Fields(fen)
+	if len(fields) != 6 {
+		return nil
+	}
+	with := func(idx int, value string) string {
+		changed := append([]string(nil), fields...)
+		changed[idx] = value
+		return strings.Join(changed, " ")
+	}
+
+	candidates := make([]string, 0, 40)
+	squares := parsePlacement(fields[0])
+	for idx, piece := range squares {
+		if piece == 0 || piece == 'K' || piece == 'k' {
+			continue
+		}
+		removed := squares
+		removed[idx] = 0
+		candidates = append(candidates, with(0, placementFEN(removed)))
+	}
+
+	if castles := fields[2]; castles != "-" {
+		for i := range castles {
+			remaining := castles[:i] + castles[i+1:]
+			if remaining == "" {
+				remaining = "-"
+			}
+			candidates = append(candidates, with(2, remaining))
+		}
+	}
+	if fields[3] != "-" {
+		candidates = append(candidates, with(3, "-"))
+	}
+	if fields[4] != "0" || fields[5] != "1" {
+		candidates = append(candidates, strings.Join(append(fields[:4:4], "0", "1"), " "))
+	}
+	return candidates
+}
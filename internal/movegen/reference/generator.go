@@ -0,0 +1,322 @@
+// Package reference holds a deliberately simple mailbox move generator used
+// as an oracle for the bitboard generator in internal/movegen, together with
+// random position generation and a differential harness.
+//
+// Nothing here is fast. The generator walks the [64]Piece board with file
+// and rank offsets, plays every candidate on a copy of the board and keeps
+// it when its own king is not attacked afterwards. It shares no tables or
+// attack code with movegen, so the two can only agree by being right.
+package reference
+
+import board "chessV2/internal/board"
+
+type (
+	Move     = board.Move
+	Piece    = board.Piece
+	Position = board.Position
+)
+
+type mailbox [64]Piece
+
+type offset struct {
+	file int8
+	rank int8
+}
+
+var (
+	knightOffsets = [8]offset{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+	kingOffsets   = [8]offset{{1, 0}, {-1, 0}, {0, 1}, {0, -1}, {1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+	rookOffsets   = [4]offset{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	bishopOffsets = [4]offset{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+
+	promotionFlags = [4]int8{board.QueenPromotion, board.KnightPromotion, board.BishopPromotion, board.RookPromotion}
+)
+
+// LegalMoves returns every legal move of the side to move, with the same
+// flags and castle encoding as the movegen generator, in no particular
+// order.
+func LegalMoves(pos *Position) []Move {
+	squares := boardOf(pos)
+	color := pos.ActiveColor()
+
+	candidates := make([]Move, 0, 64)
+	for from := int8(0); from < 64; from++ {
+		piece := squares[from]
+		if piece == board.NoPiece || piece.Color() != color {
+			continue
+		}
+
+		switch piece.Type() {
+		case board.Pawn:
+			candidates = appendPawnMoves(pos, &squares, piece, from, candidates)
+		case board.Knight:
+			candidates = appendStepMoves(&squares, piece, from, knightOffsets[:], candidates)
+		case board.King:
+			candidates = appendStepMoves(&squares, piece, from, kingOffsets[:], candidates)
+		case board.Bishop:
+			candidates = appendSlideMoves(&squares, piece, from, bishopOffsets[:], candidates)
+		case board.Rook:
+			candidates = appendSlideMoves(&squares, piece, from, rookOffsets[:], candidates)
+		case board.Queen:
+			candidates = appendSlideMoves(&squares, piece, from, rookOffsets[:], candidates)
+			candidates = appendSlideMoves(&squares, piece, from, bishopOffsets[:], candidates)
+		}
+	}
+
+	legal := candidates[:0]
+	for _, move := range candidates {
+		after := squares
+		after.play(pos, move)
+		if !after.attacked(after.king(color), enemyOf(color)) {
+			legal = append(legal, move)
+		}
+	}
+	return appendCastles(pos, &squares, legal)
+}
+
+func boardOf(pos *Position) mailbox {
+	var squares mailbox
+	for idx := int8(0); idx < 64; idx++ {
+		squares[idx] = pos.PieceAt(idx)
+	}
+	return squares
+}
+
+func enemyOf(color int8) int8 {
+	return color ^ (board.White | board.Black)
+}
+
+// target returns the square offset d away from idx, or false off the board.
+func target(idx int8, d offset) (int8, bool) {
+	file, rank := board.FileFromIdx(idx)+d.file, board.RankFromIdx(idx)+d.rank
+	if file < 0 || file > 7 || rank < 0 || rank > 7 {
+		return 0, false
+	}
+	return rank*8 + file, true
+}
+
+// appendTarget adds the move to an empty square or an enemy piece other than
+// the king and reports whether a slider can continue past it.
+func appendTarget(squares *mailbox, piece Piece, from, to int8, moves []Move) ([]Move, bool) {
+	occupant := squares[to]
+	if occupant == board.NoPiece {
+		return append(moves, board.NewMove(piece, from, to, board.NormalMove)), true
+	}
+	if occupant.Color() != piece.Color() && occupant.Type() != board.King {
+		moves = append(moves, board.NewMove(piece, from, to, board.Capture))
+	}
+	return moves, false
+}
+
+func appendStepMoves(squares *mailbox, piece Piece, from int8, offsets []offset, moves []Move) []Move {
+	for _, d := range offsets {
+		if to, ok := target(from, d); ok {
+			moves, _ = appendTarget(squares, piece, from, to, moves)
+		}
+	}
+	return moves
+}
+
+func appendSlideMoves(squares *mailbox, piece Piece, from int8, offsets []offset, moves []Move) []Move {
+	for _, d := range offsets {
+		for to, ok := target(from, d); ok; to, ok = target(to, d) {
+			var open bool
+			moves, open = appendTarget(squares, piece, from, to, moves)
+			if !open {
+				break
+			}
+		}
+	}
+	return moves
+}
+
+func appendPawnMoves(pos *Position, squares *mailbox, piece Piece, from int8, moves []Move) []Move {
+	forward, startRank, lastRank := int8(1), int8(1), int8(7)
+	if piece.Color() == board.Black {
+		forward, startRank, lastRank = -1, 6, 0
+	}
+
+	appendPawnMove := func(to int8, flag int8) {
+		if board.RankFromIdx(to) != lastRank {
+			moves = append(moves, board.NewMove(piece, from, to, flag))
+			return
+		}
+		for _, promotion := range promotionFlags {
+			moves = append(moves, board.NewMove(piece, from, to, promotion))
+		}
+	}
+
+	if to, ok := target(from, offset{0, forward}); ok && squares[to] == board.NoPiece {
+		appendPawnMove(to, board.NormalMove)
+		if board.RankFromIdx(from) == startRank {
+			if double, _ := target(to, offset{0, forward}); squares[double] == board.NoPiece {
+				moves = append(moves, board.NewMove(piece, from, double, board.PawnDoubleMove))
+			}
+		}
+	}
+
+	for _, file := range [2]int8{-1, 1} {
+		to, ok := target(from, offset{file, forward})
+		if !ok {
+			continue
+		}
+		occupant := squares[to]
+		switch {
+		case to == pos.EnPassantIdx():
+			moves = append(moves, board.NewMove(piece, from, to, board.EnPassant))
+		case occupant != board.NoPiece && occupant.Color() != piece.Color() && occupant.Type() != board.King:
+			appendPawnMove(to, board.Capture)
+		}
+	}
+	return moves
+}
+
+// appendCastles adds the castles of the side to move: the king is not in
+// check, every square between king and rook and their destinations is empty
+// apart from those two, and no square the king crosses or lands on is
+// attacked once both have left the back rank.
+func appendCastles(pos *Position, squares *mailbox, moves []Move) []Move {
+	color := pos.ActiveColor()
+	kingIdx := squares.king(color)
+	king := Piece(color | board.King)
+	if squares.attacked(kingIdx, enemyOf(color)) {
+		return moves
+	}
+
+	for _, side := range [2]int8{board.KingSideCastle, board.QueenSideCastle} {
+		if pos.CastleRights()&side == 0 {
+			continue
+		}
+		rookIdx := pos.CastleRookIdx(color, side)
+		if squares[rookIdx] != Piece(color|board.Rook) {
+			continue
+		}
+
+		kingTo, rookTo := board.CastleDestinations(color, side)
+		lifted := *squares
+		lifted[kingIdx] = board.NoPiece
+		lifted[rookIdx] = board.NoPiece
+		if !lifted.emptyBetween(kingIdx, kingTo) || !lifted.emptyBetween(rookIdx, rookTo) {
+			continue
+		}
+
+		safe := true
+		step := int8(1)
+		if kingTo < kingIdx {
+			step = -1
+		}
+		for sq := kingIdx; sq != kingTo; {
+			sq += step
+			if lifted.attacked(sq, enemyOf(color)) {
+				safe = false
+				break
+			}
+		}
+		if !safe {
+			continue
+		}
+
+		to := kingTo
+		if pos.Chess960() {
+			to = rookIdx
+		}
+		moves = append(moves, board.NewMove(king, kingIdx, to, board.Castle))
+	}
+	return moves
+}
+
+// emptyBetween reports whether every square from a to b inclusive, both on
+// the same rank, is empty.
+func (squares *mailbox) emptyBetween(a, b int8) bool {
+	if a > b {
+		a, b = b, a
+	}
+	for sq := a; sq <= b; sq++ {
+		if squares[sq] != board.NoPiece {
+			return false
+		}
+	}
+	return true
+}
+
+// play applies a non-castle move. Castles are checked square by square in
+// appendCastles instead.
+func (squares *mailbox) play(pos *Position, move Move) {
+	from, to := move.StartIdx(), move.EndIdx()
+	piece := squares[from]
+	squares[from] = board.NoPiece
+
+	switch flag := move.Flag(); flag {
+	case board.EnPassant:
+		captured := to - 8
+		if piece.Color() == board.Black {
+			captured = to + 8
+		}
+		squares[captured] = board.NoPiece
+	case board.QueenPromotion:
+		piece = Piece(piece.Color() | board.Queen)
+	case board.KnightPromotion:
+		piece = Piece(piece.Color() | board.Knight)
+	case board.BishopPromotion:
+		piece = Piece(piece.Color() | board.Bishop)
+	case board.RookPromotion:
+		piece = Piece(piece.Color() | board.Rook)
+	}
+	squares[to] = piece
+}
+
+func (squares *mailbox) king(color int8) int8 {
+	for idx := int8(0); idx < 64; idx++ {
+		if squares[idx] == Piece(color|board.King) {
+			return idx
+		}
+	}
+	return -1
+}
+
+// attacked reports whether a piece of color attacks sq, looking outwards from
+// sq for each kind of attacker.
+func (squares *mailbox) attacked(sq int8, color int8) bool {
+	pawnRank := int8(-1)
+	if color == board.Black {
+		pawnRank = 1
+	}
+	for _, file := range [2]int8{-1, 1} {
+		if from, ok := target(sq, offset{file, pawnRank}); ok && squares[from] == Piece(color|board.Pawn) {
+			return true
+		}
+	}
+
+	for _, d := range knightOffsets {
+		if from, ok := target(sq, d); ok && squares[from] == Piece(color|board.Knight) {
+			return true
+		}
+	}
+	for _, d := range kingOffsets {
+		if from, ok := target(sq, d); ok && squares[from] == Piece(color|board.King) {
+			return true
+		}
+	}
+
+	if squares.slidingAttacker(sq, color, rookOffsets[:], board.Rook) ||
+		squares.slidingAttacker(sq, color, bishopOffsets[:], board.Bishop) {
+		return true
+	}
+	return false
+}
+
+func (squares *mailbox) slidingAttacker(sq int8, color int8, offsets []offset, slider int8) bool {
+	for _, d := range offsets {
+		for from, ok := target(sq, d); ok; from, ok = target(from, d) {
+			piece := squares[from]
+			if piece == board.NoPiece {
+				continue
+			}
+			if piece.Color() == color && (piece.Type() == slider || piece.Type() == board.Queen) {
+				return true
+			}
+			break
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package reference
+
+import (
+	board "chessV2/internal/board"
+	"chessV2/internal/movegen"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func perft(pos *Position, depth int) uint64 {
+	if depth == 0 {
+		return 1
+	}
+	updater := board.NewPlainPositionUpdater()
+	nodes := uint64(0)
+	for _, move := range LegalMoves(pos) {
+		history := updater.MakeMove(pos, move)
+		nodes += perft(pos, depth-1)
+		updater.UnMakeMove(pos, history)
+	}
+	return nodes
+}
+
+// TestLegalMovesPerft checks the reference on its own against the published
+// perft counts, so that it can serve as an oracle.
+func TestLegalMovesPerft(t *testing.T) {
+	data := map[string]struct {
+		fen      string
+		depth    int
+		expected uint64
+	}{
+		"start position": {
+			fen:      board.FenStartPos,
+			depth:    3,
+			expected: 8902,
+		},
+		"kiwipete": {
+			fen:      "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+			depth:    3,
+			expected: 97862,
+		},
+		"perft position 3": {
+			fen:      "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+			depth:    4,
+			expected: 43238,
+		},
+		"perft position 4": {
+			fen:      "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+			depth:    3,
+			expected: 9467,
+		},
+		"perft position 5": {
+			fen:      "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+			depth:    3,
+			expected: 62379,
+		},
+		"chess960": {
+			fen:      "bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9",
+			depth:    3,
+			expected: 12189,
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			pos, err := board.NewPositionFromFEN(d.fen)
+			assert.NoError(t, err)
+			assert.Equal(t, d.expected, perft(pos, d.depth))
+		})
+	}
+}
+
+func TestCheckRandomPositions(t *testing.T) {
+	generator := movegen.NewPseudoLegalMoveGenerator()
+	updater := board.NewPositionUpdater()
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 3000; i++ {
+		pos := RandomPosition(rng)
+		fen := pos.FEN()
+		if err := Check(pos, generator, updater); err != nil {
+			failing := func(pos *Position) bool { return Check(pos, generator, updater) != nil }
+			t.Fatalf("%v\nshrunk to %s", err, Shrink(pos, failing))
+		}
+		assert.Equal(t, fen, pos.FEN())
+	}
+}
+
+func TestRandomPositionsAreValid(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 500; i++ {
+		pos := RandomPosition(rng)
+		assert.NoError(t, pos.Validate(), pos.FEN())
+	}
+}
+
+func TestShrink(t *testing.T) {
+	pos, err := board.NewValidPositionFromFEN("r3k2r/pppq1ppp/2n5/3Np3/8/8/PPP2PPP/R3K2R w KQkq e6 4 12")
+	assert.NoError(t, err)
+
+	hasWhiteKnight := func(pos *Position) bool {
+		return pos.KnightBoard()&pos.WhiteOccupied() != 0
+	}
+	shrunk := Shrink(pos, hasWhiteKnight)
+	assert.Equal(t, "4k3/8/8/3N4/8/8/8/4K3 w - - 0 1", shrunk)
+	assert.False(t, strings.Contains(Shrink(pos, func(*Position) bool { return true }), "N"))
+}
+
+func TestCheckReportsMissingMoves(t *testing.T) {
+	missing, extra := diffMoves(
+		[]Move{board.NewMove(board.Piece(board.White|board.Pawn), board.E2, board.E4, board.PawnDoubleMove)},
+		[]Move{board.NewMove(board.Piece(board.White|board.Pawn), board.E2, board.E3, board.NormalMove)},
+	)
+	assert.Equal(t, []string{"e2e3/0"}, missing)
+	assert.Equal(t, []string{"e2e4/6"}, extra)
+}
@@ -0,0 +1,183 @@
+package reference
+
+import (
+	board "chessV2/internal/board"
+	"math/rand"
+	"strings"
+)
+
+// RandomPosition returns a position that passes Validate. Half of the time
+// it plays a random game from the standard or a random Chess960 start, which
+// keeps castle rights and en passant squares in play. Otherwise it scatters
+// a random set of pieces over the board, which reaches the sparse and odd
+// setups games rarely produce.
+func RandomPosition(rng *rand.Rand) *Position {
+	if rng.Intn(2) == 0 {
+		return randomGamePosition(rng)
+	}
+	for {
+		if pos, err := board.NewValidPositionFromFEN(randomPlacementFEN(rng)); err == nil {
+			return pos
+		}
+	}
+}
+
+func randomGamePosition(rng *rand.Rand) *Position {
+	fen := board.FenStartPos
+	if rng.Intn(4) == 0 {
+		fen = randomChess960FEN(rng)
+	}
+	pos, err := board.NewValidPositionFromFEN(fen)
+	if err != nil {
+		panic(err)
+	}
+
+	updater := board.NewPositionUpdater()
+	plies := rng.Intn(120)
+	for ply := 0; ply < plies; ply++ {
+		moves := LegalMoves(pos)
+		if len(moves) == 0 {
+			break
+		}
+		updater.MakeMove(pos, moves[rng.Intn(len(moves))])
+	}
+	return pos
+}
+
+// randomChess960FEN places the back rank pieces with the bishops on opposite
+// colors and the king between the rooks.
+func randomChess960FEN(rng *rand.Rand) string {
+	var rank [8]byte
+	free := func() []int {
+		squares := make([]int, 0, 8)
+		for file, piece := range rank {
+			if piece == 0 {
+				squares = append(squares, file)
+			}
+		}
+		return squares
+	}
+
+	rank[rng.Intn(4)*2] = 'b'
+	rank[rng.Intn(4)*2+1] = 'b'
+	for _, piece := range []byte{'q', 'n', 'n'} {
+		squares := free()
+		rank[squares[rng.Intn(len(squares))]] = piece
+	}
+	squares := free()
+	rank[squares[0]], rank[squares[1]], rank[squares[2]] = 'r', 'k', 'r'
+
+	black := string(rank[:])
+	white := strings.ToUpper(black)
+	castles := string([]byte{'A' + byte(squares[2]), 'A' + byte(squares[0]), 'a' + byte(squares[2]), 'a' + byte(squares[0])})
+	return black + "/pppppppp/8/8/8/8/PPPPPPPP/" + white + " w " + castles + " - 0 1"
+}
+
+// randomPlacementFEN puts both kings and up to a dozen other pieces on random
+// squares. Castle rights are granted when a king and rook stand on their
+// standard squares, and an en passant square when the last move could have
+// been a double push. The caller rejects the FENs that fail validation.
+func randomPlacementFEN(rng *rand.Rand) string {
+	var squares [64]byte
+	place := func(piece byte) {
+		for {
+			idx := rng.Intn(64)
+			rank := idx / 8
+			if squares[idx] != 0 || ((piece == 'P' || piece == 'p') && (rank == 0 || rank == 7)) {
+				continue
+			}
+			squares[idx] = piece
+			return
+		}
+	}
+
+	place('K')
+	place('k')
+	pieces := []byte("QRRBBNNPPPPPPPPqrrbbnnpppppppp")
+	for i := rng.Intn(13); i > 0; i-- {
+		place(pieces[rng.Intn(len(pieces))])
+	}
+
+	active := "w"
+	if rng.Intn(2) == 0 {
+		active = "b"
+	}
+
+	castles := ""
+	for _, right := range []struct {
+		char, king, rook byte
+		kingIdx, rookIdx int8
+	}{
+		{'K', 'K', 'R', board.E1, board.H1},
+		{'Q', 'K', 'R', board.E1, board.A1},
+		{'k', 'k', 'r', board.E8, board.H8},
+		{'q', 'k', 'r', board.E8, board.A8},
+	} {
+		if squares[right.kingIdx] == right.king && squares[right.rookIdx] == right.rook && rng.Intn(4) != 0 {
+			castles += string(right.char)
+		}
+	}
+	if castles == "" {
+		castles = "-"
+	}
+
+	enPassant := "-"
+	if active == "w" {
+		if file := rng.Intn(8); squares[32+file] == 'p' && squares[40+file] == 0 && squares[48+file] == 0 {
+			enPassant = board.IdxToSquare(int8(40 + file))
+		}
+	} else if file := rng.Intn(8); squares[24+file] == 'P' && squares[16+file] == 0 && squares[8+file] == 0 {
+		enPassant = board.IdxToSquare(int8(16 + file))
+	}
+
+	return placementFEN(squares) + " " + active + " " + castles + " " + enPassant + " 0 1"
+}
+
+// placementFEN writes the piece placement field of squares, which holds FEN
+// piece letters indexed like board squares and 0 for empty squares.
+func placementFEN(squares [64]byte) string {
+	var b strings.Builder
+	for rank := 7; rank >= 0; rank-- {
+		empty := 0
+		for file := 0; file < 8; file++ {
+			piece := squares[rank*8+file]
+			if piece == 0 {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				b.WriteByte(byte('0' + empty))
+				empty = 0
+			}
+			b.WriteByte(piece)
+		}
+		if empty > 0 {
+			b.WriteByte(byte('0' + empty))
+		}
+		if rank > 0 {
+			b.WriteByte('/')
+		}
+	}
+
+	return b.String()
+}
+
+// parsePlacement reads a FEN piece placement field into the layout
+// placementFEN writes.
+func parsePlacement(field string) [64]byte {
+	var squares [64]byte
+	rank, file := 7, 0
+	for _, char := range field {
+		switch {
+		case char == '/':
+			rank--
+			file = 0
+		case char >= '1' && char <= '8':
+			file += int(char - '0')
+		default:
+			squares[rank*8+file] = byte(char)
+			file++
+		}
+	}
+	return squares
+}
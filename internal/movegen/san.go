@@ -0,0 +1,304 @@
+package movegen
+
+import (
+	board "chessV2/internal/board"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidSAN   = errors.New("invalid SAN move")
+	ErrIllegalSAN   = errors.New("illegal SAN move")
+	ErrAmbiguousSAN = errors.New("ambiguous SAN move")
+)
+
+const sanMaxMoves = 256
+
+// SAN returns the Standard Algebraic Notation of a legal move in pos, including
+// disambiguation, capture marker, promotion piece and check/mate suffix.
+// pos is restored before returning.
+func (g *PseudoLegalMoveGenerator) SAN(pos *Position, positionUpdater board.MoveApplier, move Move) string {
+	var moves [sanMaxMoves]Move
+	count := g.LegalMovesInto(pos, positionUpdater, moves[:])
+
+	var b strings.Builder
+	b.Grow(8)
+
+	if move.Flag() == board.Castle {
+		if board.FileFromIdx(move.EndIdx()) > board.FileFromIdx(move.StartIdx()) {
+			b.WriteString("O-O")
+		} else {
+			b.WriteString("O-O-O")
+		}
+	} else {
+		piece := move.Piece()
+		pieceType := piece.Type()
+		isCapture := isSANCapture(pos, move)
+
+		if pieceType == Pawn {
+			if isCapture {
+				b.WriteByte(byte('a' + board.FileFromIdx(move.StartIdx())))
+			}
+		} else {
+			b.WriteByte(sanPieceLetter(pieceType))
+			b.WriteString(sanDisambiguation(move, moves[:count]))
+		}
+
+		if isCapture {
+			b.WriteByte('x')
+		}
+		b.WriteString(board.IdxToSquare(move.EndIdx()))
+
+		if promotion := sanPromotionLetter(move.Flag()); promotion != 0 {
+			b.WriteByte('=')
+			b.WriteByte(promotion)
+		}
+	}
+
+	if GivesCheck(pos, move) {
+		history := positionUpdater.MakeMove(pos, move)
+		var replies [sanMaxMoves]Move
+		if g.LegalMovesInto(pos, positionUpdater, replies[:]) == 0 {
+			b.WriteByte('#')
+		} else {
+			b.WriteByte('+')
+		}
+		positionUpdater.UnMakeMove(pos, history)
+	}
+
+	return b.String()
+}
+
+// ParseSAN resolves a SAN string against the legal moves of pos.
+// Check, mate, annotation and e.p. suffixes are ignored, castling accepts both
+// letter O and digit 0, and the promotion `=` is optional.
+func (g *PseudoLegalMoveGenerator) ParseSAN(pos *Position, positionUpdater board.MoveApplier, san string) (Move, error) {
+	text := strings.TrimSpace(san)
+	text = strings.TrimRight(text, "+#!? ")
+	text = strings.TrimSuffix(text, "e.p.")
+	text = strings.TrimRight(text, "+#!? ")
+	if text == "" {
+		return NoMove, fmt.Errorf("%w: %q", ErrInvalidSAN, san)
+	}
+
+	var moves [sanMaxMoves]Move
+	count := g.LegalMovesInto(pos, positionUpdater, moves[:])
+
+	switch text {
+	case "O-O", "0-0", "O-O-O", "0-0-0":
+		kingSide := len(text) == 3
+		for i := 0; i < count; i++ {
+			move := moves[i]
+			if move.Flag() != board.Castle {
+				continue
+			}
+			if (board.FileFromIdx(move.EndIdx()) > board.FileFromIdx(move.StartIdx())) == kingSide {
+				return move, nil
+			}
+		}
+		return NoMove, fmt.Errorf("%w: %q", ErrIllegalSAN, san)
+	}
+
+	promotionFlag := int8(board.NormalMove)
+	if eqIdx := strings.IndexByte(text, '='); eqIdx >= 0 {
+		if eqIdx != len(text)-2 {
+			return NoMove, fmt.Errorf("%w: %q", ErrInvalidSAN, san)
+		}
+		promotionFlag = sanPromotionFlag(text[eqIdx+1], true)
+		if promotionFlag == board.NormalMove {
+			return NoMove, fmt.Errorf("%w: %q", ErrInvalidSAN, san)
+		}
+		text = text[:eqIdx]
+	} else if len(text) >= 3 && isSANRank(text[len(text)-2]) {
+		if flag := sanPromotionFlag(text[len(text)-1], false); flag != board.NormalMove {
+			promotionFlag = flag
+			text = text[:len(text)-1]
+		}
+	}
+
+	pieceType := Pawn
+	if len(text) > 0 {
+		if t := sanPieceType(text[0]); t != 0 {
+			pieceType = t
+			text = text[1:]
+		}
+	}
+
+	if len(text) < 2 || !isSANFile(text[len(text)-2]) || !isSANRank(text[len(text)-1]) {
+		return NoMove, fmt.Errorf("%w: %q", ErrInvalidSAN, san)
+	}
+	endIdx := board.SquareToIdx(text[len(text)-2:])
+	text = text[:len(text)-2]
+	text = strings.TrimSuffix(text, "x")
+	text = strings.TrimSuffix(text, ":")
+
+	fromFile, fromRank := int8(-1), int8(-1)
+	for i := 0; i < len(text); i++ {
+		switch {
+		case isSANFile(text[i]) && fromFile < 0:
+			fromFile = int8(text[i] - 'a')
+		case isSANRank(text[i]) && fromRank < 0:
+			fromRank = int8(text[i] - '1')
+		default:
+			return NoMove, fmt.Errorf("%w: %q", ErrInvalidSAN, san)
+		}
+	}
+
+	var (
+		found   Move
+		matches int
+	)
+	for i := 0; i < count; i++ {
+		move := moves[i]
+		if move.Flag() == board.Castle || move.Piece().Type() != pieceType || move.EndIdx() != endIdx {
+			continue
+		}
+		if fromFile >= 0 && board.FileFromIdx(move.StartIdx()) != fromFile {
+			continue
+		}
+		if fromRank >= 0 && board.RankFromIdx(move.StartIdx()) != fromRank {
+			continue
+		}
+		if isPromotionFlag(move.Flag()) {
+			if move.Flag() != promotionFlag {
+				continue
+			}
+		} else if promotionFlag != board.NormalMove {
+			continue
+		}
+
+		found = move
+		matches++
+	}
+
+	switch matches {
+	case 0:
+		return NoMove, fmt.Errorf("%w: %q", ErrIllegalSAN, san)
+	case 1:
+		return found, nil
+	default:
+		return NoMove, fmt.Errorf("%w: %q", ErrAmbiguousSAN, san)
+	}
+}
+
+func sanDisambiguation(move Move, legalMoves []Move) string {
+	var (
+		ambiguous bool
+		sameFile  bool
+		sameRank  bool
+	)
+	startFile := board.FileFromIdx(move.StartIdx())
+	startRank := board.RankFromIdx(move.StartIdx())
+
+	for _, other := range legalMoves {
+		if other.Piece() != move.Piece() || other.EndIdx() != move.EndIdx() || other.StartIdx() == move.StartIdx() {
+			continue
+		}
+		ambiguous = true
+		if board.FileFromIdx(other.StartIdx()) == startFile {
+			sameFile = true
+		}
+		if board.RankFromIdx(other.StartIdx()) == startRank {
+			sameRank = true
+		}
+	}
+
+	switch {
+	case !ambiguous:
+		return ""
+	case !sameFile:
+		return string(rune('a' + startFile))
+	case !sameRank:
+		return string(rune('1' + startRank))
+	default:
+		return board.IdxToSquare(move.StartIdx())
+	}
+}
+
+func isSANCapture(pos *Position, move Move) bool {
+	return move.Flag() == board.EnPassant || pos.PieceAt(move.EndIdx()) != NoPiece
+}
+
+func isPromotionFlag(flag int8) bool {
+	return flag >= board.QueenPromotion && flag <= board.RookPromotion
+}
+
+func isSANFile(c byte) bool {
+	return c >= 'a' && c <= 'h'
+}
+
+func isSANRank(c byte) bool {
+	return c >= '1' && c <= '8'
+}
+
+func sanPieceLetter(pieceType int8) byte {
+	switch pieceType {
+	case King:
+		return 'K'
+	case Queen:
+		return 'Q'
+	case Rook:
+		return 'R'
+	case Bishop:
+		return 'B'
+	case Knight:
+		return 'N'
+	default:
+		return 0
+	}
+}
+
+func sanPieceType(c byte) int8 {
+	switch c {
+	case 'K':
+		return King
+	case 'Q':
+		return Queen
+	case 'R':
+		return Rook
+	case 'B':
+		return Bishop
+	case 'N':
+		return Knight
+	default:
+		return 0
+	}
+}
+
+func sanPromotionLetter(flag int8) byte {
+	switch flag {
+	case board.QueenPromotion:
+		return 'Q'
+	case board.RookPromotion:
+		return 'R'
+	case board.BishopPromotion:
+		return 'B'
+	case board.KnightPromotion:
+		return 'N'
+	default:
+		return 0
+	}
+}
+
+// sanPromotionFlag reads a promotion piece letter. A lowercase b only counts
+// after '=', since as a bare suffix it reads as the b-file.
+func sanPromotionFlag(c byte, afterEquals bool) int8 {
+	switch c {
+	case 'Q', 'q':
+		return board.QueenPromotion
+	case 'R', 'r':
+		return board.RookPromotion
+	case 'B':
+		return board.BishopPromotion
+	case 'b':
+		if afterEquals {
+			return board.BishopPromotion
+		}
+		return board.NormalMove
+	case 'N', 'n':
+		return board.KnightPromotion
+	default:
+		return board.NormalMove
+	}
+}
@@ -0,0 +1,183 @@
+package movegen
+
+import (
+	board "chessV2/internal/board"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSAN(t *testing.T) {
+	data := map[string]struct {
+		fen      string
+		move     Move
+		expected string
+	}{
+		"pawn push": {
+			fen:      FenStartPos,
+			move:     board.NewMove(Piece(White|Pawn), E2, E4, board.PawnDoubleMove),
+			expected: "e4",
+		},
+		"knight move": {
+			fen:      FenStartPos,
+			move:     board.NewMove(Piece(White|Knight), G1, F3, board.NormalMove),
+			expected: "Nf3",
+		},
+		"pawn capture": {
+			fen:      "rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 2",
+			move:     board.NewMove(Piece(White|Pawn), E4, D5, board.NormalMove),
+			expected: "exd5",
+		},
+		"en passant": {
+			fen:      "rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+			move:     board.NewMove(Piece(White|Pawn), E5, F6, board.EnPassant),
+			expected: "exf6",
+		},
+		"disambiguation by file": {
+			fen:      "4k3/8/8/8/8/8/6K1/R6R w - - 0 1",
+			move:     board.NewMove(Piece(White|Rook), A1, D1, board.NormalMove),
+			expected: "Rad1",
+		},
+		"disambiguation by rank": {
+			fen:      "4k3/8/8/R7/8/8/8/R3K3 w - - 0 1",
+			move:     board.NewMove(Piece(White|Rook), A1, A3, board.NormalMove),
+			expected: "R1a3",
+		},
+		"disambiguation by file and rank": {
+			fen:      "1k6/8/8/8/4Q2Q/8/8/K6Q w - - 0 1",
+			move:     board.NewMove(Piece(White|Queen), H4, E1, board.NormalMove),
+			expected: "Qh4e1",
+		},
+		"pinned piece does not need disambiguation": {
+			fen:      "4k3/4r3/8/8/8/2N5/4N3/4K3 w - - 0 1",
+			move:     board.NewMove(Piece(White|Knight), C3, D5, board.NormalMove),
+			expected: "Nd5",
+		},
+		"chess960 castle queen side": {
+			fen:      "4k3/8/8/8/8/8/8/RK6 w A - 0 1",
+			move:     board.NewMove(Piece(White|King), B1, A1, board.Castle),
+			expected: "O-O-O",
+		},
+		"promotion with check": {
+			fen:      "3k4/4P3/8/8/8/8/8/4K3 w - - 0 1",
+			move:     board.NewMove(Piece(White|Pawn), E7, E8, board.RookPromotion),
+			expected: "e8=R+",
+		},
+		"capture promotion": {
+			fen:      "3r1k2/4P3/8/8/8/8/8/4K3 w - - 0 1",
+			move:     board.NewMove(Piece(White|Pawn), E7, D8, board.KnightPromotion),
+			expected: "exd8=N",
+		},
+		"checkmate": {
+			fen:      "6k1/5ppp/8/8/8/8/8/R3K3 w - - 0 1",
+			move:     board.NewMove(Piece(White|Rook), A1, A8, board.NormalMove),
+			expected: "Ra8#",
+		},
+		"king side castle": {
+			fen:      "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			move:     board.NewMove(Piece(White|King), E1, G1, board.Castle),
+			expected: "O-O",
+		},
+		"queen side castle with check": {
+			fen:      "3k4/8/8/8/8/8/8/R3K3 w Q - 0 1",
+			move:     board.NewMove(Piece(White|King), E1, C1, board.Castle),
+			expected: "O-O-O+",
+		},
+	}
+
+	generator := NewPseudoLegalMoveGenerator()
+	updater := board.NewPositionUpdater()
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			pos, err := NewPositionFromFEN(d.fen)
+			assert.NoError(t, err)
+
+			assert.Equal(t, d.expected, generator.SAN(pos, updater, d.move))
+			assert.Equal(t, d.fen, pos.FEN())
+		})
+	}
+}
+
+func TestParseSAN(t *testing.T) {
+	data := map[string]struct {
+		fen      string
+		san      string
+		expected string
+		err      error
+	}{
+		"pawn push":                     {fen: FenStartPos, san: "e4", expected: "e2e4"},
+		"check suffix ignored":          {fen: "3k4/8/8/8/8/8/8/R3K3 w Q - 0 1", san: "Ra8+", expected: "a1a8"},
+		"annotations ignored":           {fen: FenStartPos, san: "Nf3!?", expected: "g1f3"},
+		"castle with zeros":             {fen: "r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", san: "0-0-0", expected: "e8c8"},
+		"promotion without equals":      {fen: "3k4/4P3/8/8/8/8/8/4K3 w - - 0 1", san: "e8Q", expected: "e7e8q"},
+		"lowercase bishop promotion":    {fen: "3k4/4P3/8/8/8/8/8/4K3 w - - 0 1", san: "e8=b", expected: "e7e8b"},
+		"en passant marker":             {fen: "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", san: "exd6 e.p.", expected: "e5d6"},
+		"en passant marker and check":   {fen: "8/2k5/8/3pP3/8/8/8/4K3 w - d6 0 1", san: "exd6 e.p.+", expected: "e5d6"},
+		"en passant marker before mate": {fen: "8/2k5/8/3pP3/8/8/8/4K3 w - d6 0 1", san: "exd6e.p.#", expected: "e5d6"},
+		"capture marker optional":       {fen: "rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 2", san: "ed5", expected: "e4d5"},
+		"bishop and b-file differ":      {fen: "4k3/8/8/2p5/8/B7/1P6/4K3 w - - 0 1", san: "Bxc5", expected: "a3c5"},
+		"disambiguated by rank":         {fen: "4k3/8/8/R7/8/8/8/R3K3 w - - 0 1", san: "R1a3", expected: "a1a3"},
+		"lowercase bishop suffix":       {fen: "3k4/4P3/8/8/8/8/8/4K3 w - - 0 1", san: "e8b", err: ErrInvalidSAN},
+		"missing promotion piece":       {fen: "3k4/4P3/8/8/8/8/8/4K3 w - - 0 1", san: "e8", err: ErrIllegalSAN},
+		"ambiguous":                     {fen: "4k3/8/8/8/8/8/6K1/R6R w - - 0 1", san: "Rd1", err: ErrAmbiguousSAN},
+		"illegal":                       {fen: FenStartPos, san: "e5", err: ErrIllegalSAN},
+		"malformed":                     {fen: FenStartPos, san: "Nz9", err: ErrInvalidSAN},
+		"castle without the rights":     {fen: "r3k2r/8/8/8/8/8/8/R3K2R w kq - 0 1", san: "O-O", err: ErrIllegalSAN},
+		"chess960 castle":               {fen: "4k3/8/8/8/8/8/8/1RK5 w B - 0 1", san: "O-O-O", expected: "c1b1"},
+	}
+
+	generator := NewPseudoLegalMoveGenerator()
+	updater := board.NewPositionUpdater()
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			pos, err := NewPositionFromFEN(d.fen)
+			assert.NoError(t, err)
+
+			move, err := generator.ParseSAN(pos, updater, d.san)
+			if d.err != nil {
+				assert.True(t, errors.Is(err, d.err), "unexpected error: %v", err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, d.expected, move.UCI())
+		})
+	}
+}
+
+func TestSANRoundTrip(t *testing.T) {
+	fens := []string{
+		FenStartPos,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+	}
+
+	generator := NewPseudoLegalMoveGenerator()
+	updater := board.NewPositionUpdater()
+
+	for _, fen := range fens {
+		t.Run(fen, func(t *testing.T) {
+			pos, err := NewPositionFromFEN(fen)
+			assert.NoError(t, err)
+
+			var moves [sanMaxMoves]Move
+			count := generator.LegalMovesInto(pos, updater, moves[:])
+			seen := make(map[string]struct{}, count)
+			for i := 0; i < count; i++ {
+				san := generator.SAN(pos, updater, moves[i])
+				_, duplicate := seen[san]
+				assert.False(t, duplicate, "duplicate SAN %s", san)
+				seen[san] = struct{}{}
+
+				parsed, err := generator.ParseSAN(pos, updater, san)
+				assert.NoError(t, err)
+				assert.Equal(t, moves[i], parsed, san)
+			}
+		})
+	}
+}
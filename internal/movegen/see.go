@@ -0,0 +1,244 @@
+package movegen
+
+import (
+	board "chessV2/internal/board"
+	"math/bits"
+)
+
+// seePieceValues is indexed by piece type. The king is worth more than any
+// exchange can win so that it only ever captures last.
+var seePieceValues = [7]int{
+	King:   20000,
+	Queen:  900,
+	Pawn:   100,
+	Knight: 320,
+	Bishop: 330,
+	Rook:   500,
+}
+
+// SEEValue returns the value static exchange evaluation uses for pieceType.
+func SEEValue(pieceType int8) int {
+	return seePieceValues[pieceType]
+}
+
+// attackersTo returns the pieces of both colors attacking sq, with sliders
+// looked up through occ so that removing a piece from occ reveals x-rays.
+func attackersTo(pos *Position, sq int8, occ uint64) uint64 {
+	whiteOcc := pos.WhiteOccupied()
+	blackOcc := pos.BlackOccupied()
+
+	attackers := pawnAttacksBy[0][sq] & pos.PawnBoard() & whiteOcc
+	attackers |= pawnAttacksBy[1][sq] & pos.PawnBoard() & blackOcc
+	attackers |= knightAttacksMask[sq] & pos.KnightBoard()
+	attackers |= kingAttacksMask[sq] & pos.KingBoard()
+	attackers |= rookAttacksMagic(sq, occ) & (pos.RookBoard() | pos.QueenBoard())
+	attackers |= bishopAttacksMagic(sq, occ) & (pos.BishopBoard() | pos.QueenBoard())
+	return attackers & occ
+}
+
+// leastValuableAttacker returns the square and type of the cheapest piece in
+// attackers.
+func leastValuableAttacker(pos *Position, attackers uint64) (int8, int8) {
+	for _, pieceType := range [6]int8{Pawn, Knight, Bishop, Rook, Queen, King} {
+		var pieces uint64
+		switch pieceType {
+		case Pawn:
+			pieces = pos.PawnBoard()
+		case Knight:
+			pieces = pos.KnightBoard()
+		case Bishop:
+			pieces = pos.BishopBoard()
+		case Rook:
+			pieces = pos.RookBoard()
+		case Queen:
+			pieces = pos.QueenBoard()
+		case King:
+			pieces = pos.KingBoard()
+		}
+		if pieces&attackers != 0 {
+			return int8(bits.TrailingZeros64(pieces & attackers)), pieceType
+		}
+	}
+	return -1, 0
+}
+
+// revealXRays adds the sliders that attack sq once the pieces missing from
+// occ have left their lines.
+func revealXRays(pos *Position, sq int8, occ uint64, pieceType int8) uint64 {
+	switch pieceType {
+	case Pawn, Bishop:
+		return bishopAttacksMagic(sq, occ) & (pos.BishopBoard() | pos.QueenBoard()) & occ
+	case Rook:
+		return rookAttacksMagic(sq, occ) & (pos.RookBoard() | pos.QueenBoard()) & occ
+	case Queen:
+		return (bishopAttacksMagic(sq, occ)&(pos.BishopBoard()|pos.QueenBoard()) |
+			rookAttacksMagic(sq, occ)&(pos.RookBoard()|pos.QueenBoard())) & occ
+	}
+	return 0
+}
+
+// seeSetup returns what the move wins on its own, the value of the piece left
+// standing on the target square, and the occupancy once the move is played.
+func seeSetup(pos *Position, move Move) (int, int, uint64) {
+	to := move.EndIdx()
+	occ := pos.Occupied() &^ (uint64(1) << move.StartIdx())
+
+	gain := seePieceValues[pos.PieceAt(to).Type()]
+	if move.Flag() == board.EnPassant {
+		capturedIdx := to - 8
+		if move.Piece().Color() == Black {
+			capturedIdx = to + 8
+		}
+		occ &^= uint64(1) << capturedIdx
+		gain = seePieceValues[Pawn]
+	}
+
+	standing := seePieceValues[move.Piece().Type()]
+	if promoted := promotionPieceType(move.Flag()); promoted != 0 {
+		gain += seePieceValues[promoted] - seePieceValues[Pawn]
+		standing = seePieceValues[promoted]
+	}
+	return gain, standing, occ
+}
+
+func promotionPieceType(flag int8) int8 {
+	switch flag {
+	case board.QueenPromotion:
+		return Queen
+	case board.RookPromotion:
+		return Rook
+	case board.BishopPromotion:
+		return Bishop
+	case board.KnightPromotion:
+		return Knight
+	}
+	return 0
+}
+
+// SEE returns the material balance of the capture sequence move starts on
+// its target square, each side recapturing with its least valuable attacker
+// and free to stop when continuing would lose. Sliders hidden behind the
+// capturing pieces join the exchange as the line opens. Pins are ignored and
+// castles are worth 0.
+func SEE(pos *Position, move Move) int {
+	if move.Flag() == board.Castle {
+		return 0
+	}
+	ensureAttackTables()
+
+	to := move.EndIdx()
+	gain, standing, occ := seeSetup(pos, move)
+
+	var swap [32]int
+	swap[0] = gain
+	side := move.Piece().Color() ^ (White | Black)
+	attackers := attackersTo(pos, to, occ)
+
+	depth := 0
+	for {
+		sideAttackers := attackers & pos.OccupancyMask(side)
+		if sideAttackers == 0 {
+			break
+		}
+		from, pieceType := leastValuableAttacker(pos, sideAttackers)
+		if pieceType == King && attackers&^pos.OccupancyMask(side) != 0 {
+			break
+		}
+
+		depth++
+		swap[depth] = standing - swap[depth-1]
+		standing = seePieceValues[pieceType]
+
+		occ &^= uint64(1) << from
+		attackers = attackers&occ | revealXRays(pos, to, occ, pieceType)
+		side ^= White | Black
+	}
+
+	for ; depth > 0; depth-- {
+		if -swap[depth] < swap[depth-1] {
+			swap[depth-1] = -swap[depth]
+		}
+	}
+	return swap[0]
+}
+
+// SEEGreaterOrEqual reports whether SEE(pos, move) >= threshold. It stops as
+// soon as the outcome is known, which makes it the cheaper call for pruning
+// and for splitting good captures from bad ones.
+func SEEGreaterOrEqual(pos *Position, move Move, threshold int) bool {
+	if move.Flag() == board.Castle {
+		return threshold <= 0
+	}
+	ensureAttackTables()
+
+	to := move.EndIdx()
+	gain, standing, occ := seeSetup(pos, move)
+
+	balance := gain - threshold
+	if balance < 0 {
+		return false
+	}
+	balance = standing - balance
+	if balance <= 0 {
+		return true
+	}
+
+	// result flips with every capture: true while the side that started the
+	// exchange is ahead of the threshold. A side that cannot recapture
+	// without dropping below it stops, and so does a king walking into a
+	// defended square.
+	side := move.Piece().Color()
+	attackers := attackersTo(pos, to, occ)
+	result := true
+	for {
+		side ^= White | Black
+		sideAttackers := attackers & pos.OccupancyMask(side)
+		if sideAttackers == 0 {
+			break
+		}
+		from, pieceType := leastValuableAttacker(pos, sideAttackers)
+		result = !result
+		if pieceType == King {
+			if attackers&^pos.OccupancyMask(side) != 0 {
+				return !result
+			}
+			return result
+		}
+
+		balance = seePieceValues[pieceType] - balance
+		if balance < 0 || (result && balance == 0) {
+			break
+		}
+
+		occ &^= uint64(1) << from
+		attackers = attackers&occ | revealXRays(pos, to, occ, pieceType)
+	}
+	return result
+}
+
+// SEEThreat returns what the opponent of the piece on sq wins by capturing it
+// with its least valuable attacker and playing out the exchange, or 0 when
+// the square is empty, unattacked or the capture would not pay.
+func SEEThreat(pos *Position, sq int8) int {
+	piece := pos.PieceAt(sq)
+	if piece == NoPiece {
+		return 0
+	}
+	ensureAttackTables()
+
+	attackerColor := piece.Color() ^ (White | Black)
+	attackers := attackersTo(pos, sq, pos.Occupied()) & pos.OccupancyMask(attackerColor)
+	if attackers == 0 {
+		return 0
+	}
+
+	from, pieceType := leastValuableAttacker(pos, attackers)
+	flag := int8(board.Capture)
+	if pieceType == Pawn && isPromotionSquare(attackerColor, sq) {
+		flag = board.QueenPromotion
+	}
+	if see := SEE(pos, board.NewMove(Piece(attackerColor|pieceType), from, sq, flag)); see > 0 {
+		return see
+	}
+	return 0
+}
@@ -0,0 +1,104 @@
+package movegen
+
+import (
+	board "chessV2/internal/board"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSEE(t *testing.T) {
+	data := map[string]struct {
+		fen      string
+		move     Move
+		expected int
+	}{
+		"undefended pawn": {
+			fen:      "4k3/8/8/3p4/8/8/8/3QK3 w - - 0 1",
+			move:     board.NewMove(Piece(White|Queen), D1, D5, board.Capture),
+			expected: 100,
+		},
+		"queen takes a pawn defended by a pawn": {
+			fen:      "4k3/8/2p5/3p4/8/8/8/3QK3 w - - 0 1",
+			move:     board.NewMove(Piece(White|Queen), D1, D5, board.Capture),
+			expected: -800,
+		},
+		"doubled rooks win through the x-ray": {
+			fen:      "3rk3/8/8/3p4/8/8/3R4/3RK3 w - - 0 1",
+			move:     board.NewMove(Piece(White|Rook), D2, D5, board.Capture),
+			expected: 100,
+		},
+		"king cannot recapture against an x-ray": {
+			fen:      "4k3/4p3/8/8/8/8/4R3/4R1K1 w - - 0 1",
+			move:     board.NewMove(Piece(White|Rook), E2, E7, board.Capture),
+			expected: 100,
+		},
+		"king recaptures a lone rook": {
+			fen:      "4k3/4p3/8/8/8/8/4R3/6K1 w - - 0 1",
+			move:     board.NewMove(Piece(White|Rook), E2, E7, board.Capture),
+			expected: -400,
+		},
+		"en passant": {
+			fen:      "4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1",
+			move:     board.NewMove(Piece(White|Pawn), E5, D6, board.EnPassant),
+			expected: 100,
+		},
+		"queen promotion": {
+			fen:      "4k3/P7/8/8/8/8/8/4K3 w - - 0 1",
+			move:     board.NewMove(Piece(White|Pawn), A7, A8, board.QueenPromotion),
+			expected: 800,
+		},
+		"promotion into a defended square": {
+			fen:      "1r2k3/P7/8/8/8/8/8/4K3 w - - 0 1",
+			move:     board.NewMove(Piece(White|Pawn), A7, A8, board.QueenPromotion),
+			expected: -100,
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			pos, err := NewPositionFromFEN(d.fen)
+			assert.NoError(t, err)
+
+			assert.Equal(t, d.expected, SEE(pos, d.move))
+			assert.True(t, SEEGreaterOrEqual(pos, d.move, d.expected))
+			assert.False(t, SEEGreaterOrEqual(pos, d.move, d.expected+1))
+		})
+	}
+}
+
+// TestSEEGreaterOrEqualMatchesSEE compares the early-exit threshold test
+// with the full swap list on every capture two plies from the perft
+// positions.
+func TestSEEGreaterOrEqualMatchesSEE(t *testing.T) {
+	generator := NewPseudoLegalMoveGenerator()
+	updater := board.NewPositionUpdater()
+
+	var check func(t *testing.T, pos *Position, depth int)
+	check = func(t *testing.T, pos *Position, depth int) {
+		var moves [256]Move
+		count := generator.LegalMovesInto(pos, updater, moves[:])
+		for _, move := range moves[:count] {
+			if isStagedTactical(pos, move) {
+				see := SEE(pos, move)
+				for _, threshold := range []int{see - 1, see, see + 1, 0} {
+					assert.Equal(t, see >= threshold, SEEGreaterOrEqual(pos, move, threshold), "%s in %s at %d", move.UCI(), pos.FEN(), threshold)
+				}
+			}
+
+			if depth > 0 {
+				history := updater.MakeMove(pos, move)
+				check(t, pos, depth-1)
+				updater.UnMakeMove(pos, history)
+			}
+		}
+	}
+
+	for _, fen := range stagedGenerationFENs {
+		t.Run(fen, func(t *testing.T) {
+			pos, err := NewPositionFromFEN(fen)
+			assert.NoError(t, err)
+			check(t, pos, 1)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package pgn
+
+import (
+	board "chessV2/internal/board"
+	"chessV2/internal/engine"
+	"fmt"
+)
+
+const (
+	ResultWhiteWins = "1-0"
+	ResultBlackWins = "0-1"
+	ResultDraw      = "1/2-1/2"
+	ResultUnknown   = "*"
+)
+
+// SevenTagRoster lists the mandatory tags in the order they are exported.
+var SevenTagRoster = [...]string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+type Tag struct {
+	Name  string
+	Value string
+}
+
+// Move is one movetext entry. Variations hold alternatives to this move and
+// start from the position before it.
+type Move struct {
+	SAN        string
+	Move       board.Move
+	NAGs       []int
+	Comments   []string
+	Variations [][]Move
+}
+
+// Game stores the Result tag in Result; Tags holds every other tag in order.
+type Game struct {
+	Tags     []Tag
+	Comments []string
+	Moves    []Move
+	Result   string
+}
+
+func NewGame() *Game {
+	return &Game{Result: ResultUnknown}
+}
+
+// NewGameFromUCI builds a game from a start FEN and a list of UCI moves,
+// converting each move to SAN with e.
+func NewGameFromUCI(e *engine.Engine, fen string, moves []string) (*Game, error) {
+	game := NewGame()
+	game.SetStartFEN(fen)
+
+	pos, err := board.NewValidPositionFromFEN(fen)
+	if err != nil {
+		return nil, err
+	}
+
+	game.Moves = make([]Move, 0, len(moves))
+	for _, uci := range moves {
+		move, err := e.FindMoveByUCI(pos, uci)
+		if err != nil {
+			return nil, err
+		}
+		game.Moves = append(game.Moves, Move{SAN: e.MoveSAN(pos, move), Move: move})
+		e.ApplyMove(pos, move)
+	}
+
+	return game, nil
+}
+
+func (g *Game) Tag(name string) (string, bool) {
+	if name == "Result" {
+		return g.Result, g.Result != ""
+	}
+
+	for _, tag := range g.Tags {
+		if tag.Name == name {
+			return tag.Value, true
+		}
+	}
+	return "", false
+}
+
+func (g *Game) SetTag(name, value string) {
+	if name == "Result" {
+		g.Result = value
+		return
+	}
+
+	for i := range g.Tags {
+		if g.Tags[i].Name == name {
+			g.Tags[i].Value = value
+			return
+		}
+	}
+	g.Tags = append(g.Tags, Tag{Name: name, Value: value})
+}
+
+// StartFEN returns the FEN tag, or the standard start position when the game
+// has none.
+func (g *Game) StartFEN() string {
+	if fen, ok := g.Tag("FEN"); ok && fen != "" {
+		return fen
+	}
+	return board.FenStartPos
+}
+
+// SetStartFEN records a non-standard start position with the SetUp and FEN tags.
+func (g *Game) SetStartFEN(fen string) {
+	if fen == board.FenStartPos {
+		return
+	}
+	g.SetTag("SetUp", "1")
+	g.SetTag("FEN", fen)
+}
+
+// UCIMoves returns the main line in UCI notation. Moves must have been
+// resolved by NewGameFromUCI or a Reader.
+func (g *Game) UCIMoves() []string {
+	moves := make([]string, 0, len(g.Moves))
+	for _, move := range g.Moves {
+		moves = append(moves, move.Move.UCI())
+	}
+	return moves
+}
+
+// Resolve checks every SAN move, including variations, against the legal
+// moves of its position and stores the matching board.Move.
+func (g *Game) Resolve(e *engine.Engine) error {
+	pos, err := board.NewValidPositionFromFEN(g.StartFEN())
+	if err != nil {
+		return err
+	}
+	return resolveMoves(e, pos, g.Moves)
+}
+
+func resolveMoves(e *engine.Engine, pos *board.Position, moves []Move) error {
+	for i := range moves {
+		for _, variation := range moves[i].Variations {
+			if err := resolveMoves(e, pos.Clone(), variation); err != nil {
+				return err
+			}
+		}
+
+		move, err := e.FindMoveBySAN(pos, moves[i].SAN)
+		if err != nil {
+			return fmt.Errorf("move %d%s %s: %w", pos.FullMoveNumber(), moveNumberDots(pos.ActiveColor()), moves[i].SAN, err)
+		}
+		moves[i].Move = move
+		e.ApplyMove(pos, move)
+	}
+	return nil
+}
+
+func moveNumberDots(activeColor int8) string {
+	if activeColor == board.Black {
+		return "..."
+	}
+	return "."
+}
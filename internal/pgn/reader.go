@@ -0,0 +1,409 @@
+package pgn
+
+import (
+	"bufio"
+	"chessV2/internal/engine"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenSymbol
+	tokenString
+	tokenComment
+	tokenNAG
+	tokenTagOpen
+	tokenTagClose
+	tokenVariationOpen
+	tokenVariationClose
+	tokenResult
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	line  int
+}
+
+var suffixNAGs = map[string]int{
+	"!":  1,
+	"?":  2,
+	"!!": 3,
+	"??": 4,
+	"!?": 5,
+	"?!": 6,
+}
+
+// Reader parses games one at a time from a PGN stream and validates their
+// moves, variations included, against an engine.
+type Reader struct {
+	r      *bufio.Reader
+	engine *engine.Engine
+	line   int
+	peeked *token
+	games  int
+	// atLineStart is set while the next byte starts a line, for % escapes.
+	atLineStart bool
+}
+
+func NewReader(r io.Reader, e *engine.Engine) *Reader {
+	return &Reader{
+		r:           bufio.NewReader(r),
+		engine:      e,
+		line:        1,
+		atLineStart: true,
+	}
+}
+
+// Read returns the next game, or io.EOF once the input holds no more games.
+func (r *Reader) Read() (*Game, error) {
+	game := NewGame()
+
+	tok, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	if tok.kind == tokenEOF {
+		return nil, io.EOF
+	}
+	r.unread(tok)
+	r.games++
+
+	if err := r.parseTags(game); err != nil {
+		return nil, err
+	}
+
+	moves, err := r.parseMoves(game, true)
+	if err != nil {
+		return nil, err
+	}
+	game.Moves = moves
+
+	if err := game.Resolve(r.engine); err != nil {
+		return nil, fmt.Errorf("invalid PGN game %d: %w", r.games, err)
+	}
+
+	return game, nil
+}
+
+// ReadAll reads every remaining game.
+func (r *Reader) ReadAll() ([]*Game, error) {
+	var games []*Game
+	for {
+		game, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return games, nil
+		}
+		if err != nil {
+			return games, err
+		}
+		games = append(games, game)
+	}
+}
+
+func (r *Reader) parseTags(game *Game) error {
+	for {
+		tok, err := r.next()
+		if err != nil {
+			return err
+		}
+		if tok.kind != tokenTagOpen {
+			r.unread(tok)
+			return nil
+		}
+
+		name, err := r.expect(tokenSymbol, "tag name")
+		if err != nil {
+			return err
+		}
+		value, err := r.expect(tokenString, "tag value")
+		if err != nil {
+			return err
+		}
+		if _, err := r.expect(tokenTagClose, "]"); err != nil {
+			return err
+		}
+		game.SetTag(name.value, value.value)
+	}
+}
+
+// parseMoves reads movetext until the game termination marker when top is
+// set, or until the closing parenthesis of a variation otherwise.
+func (r *Reader) parseMoves(game *Game, top bool) ([]Move, error) {
+	var (
+		moves   []Move
+		pending []string
+	)
+
+	for {
+		tok, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok.kind {
+		case tokenEOF:
+			if !top {
+				return nil, r.errorf(tok, "unterminated variation")
+			}
+			return moves, nil
+		case tokenTagOpen:
+			if !top {
+				return nil, r.errorf(tok, "unterminated variation")
+			}
+			r.unread(tok)
+			return moves, nil
+		case tokenResult:
+			if !top {
+				return nil, r.errorf(tok, "game termination inside a variation")
+			}
+			game.Result = tok.value
+			return moves, nil
+		case tokenSymbol:
+			if isMoveNumber(tok.value) {
+				continue
+			}
+			moves = append(moves, Move{SAN: tok.value, Comments: pending})
+			pending = nil
+		case tokenNAG:
+			nag, err := strconv.Atoi(tok.value)
+			if err != nil || len(moves) == 0 {
+				return nil, r.errorf(tok, "unexpected NAG $"+tok.value)
+			}
+			last := &moves[len(moves)-1]
+			last.NAGs = append(last.NAGs, nag)
+		case tokenComment:
+			switch {
+			case len(moves) > 0:
+				last := &moves[len(moves)-1]
+				last.Comments = append(last.Comments, tok.value)
+			case top:
+				game.Comments = append(game.Comments, tok.value)
+			default:
+				pending = append(pending, tok.value)
+			}
+		case tokenVariationOpen:
+			if len(moves) == 0 {
+				return nil, r.errorf(tok, "variation without a preceding move")
+			}
+			variation, err := r.parseMoves(game, false)
+			if err != nil {
+				return nil, err
+			}
+			last := &moves[len(moves)-1]
+			last.Variations = append(last.Variations, variation)
+		case tokenVariationClose:
+			if top {
+				return nil, r.errorf(tok, "unexpected )")
+			}
+			return moves, nil
+		default:
+			return nil, r.errorf(tok, "unexpected token "+tok.value)
+		}
+	}
+}
+
+func (r *Reader) expect(kind tokenKind, what string) (token, error) {
+	tok, err := r.next()
+	if err != nil {
+		return token{}, err
+	}
+	if tok.kind != kind {
+		return token{}, r.errorf(tok, "expected "+what)
+	}
+	return tok, nil
+}
+
+func (r *Reader) errorf(tok token, message string) error {
+	return fmt.Errorf("invalid PGN at line %d: %s", tok.line, message)
+}
+
+func (r *Reader) unread(tok token) {
+	r.peeked = &tok
+}
+
+func (r *Reader) next() (token, error) {
+	if r.peeked != nil {
+		tok := *r.peeked
+		r.peeked = nil
+		return tok, nil
+	}
+
+	for {
+		lineStart := r.atLineStart
+		c, err := r.readByte()
+		if errors.Is(err, io.EOF) {
+			return token{kind: tokenEOF, line: r.line}, nil
+		}
+		if err != nil {
+			return token{}, err
+		}
+
+		line := r.line
+		switch {
+		case c == '\n':
+			r.line++
+		case c == ' ' || c == '\t' || c == '\r' || c == '.':
+		case c == ';':
+			if err := r.skipLine(); err != nil {
+				return token{}, err
+			}
+		case c == '%' && lineStart:
+			if err := r.skipLine(); err != nil {
+				return token{}, err
+			}
+		case c == '[':
+			return token{kind: tokenTagOpen, value: "[", line: line}, nil
+		case c == ']':
+			return token{kind: tokenTagClose, value: "]", line: line}, nil
+		case c == '(':
+			return token{kind: tokenVariationOpen, value: "(", line: line}, nil
+		case c == ')':
+			return token{kind: tokenVariationClose, value: ")", line: line}, nil
+		case c == '*':
+			return token{kind: tokenResult, value: ResultUnknown, line: line}, nil
+		case c == '"':
+			value, err := r.readString()
+			return token{kind: tokenString, value: value, line: line}, err
+		case c == '{':
+			value, err := r.readComment()
+			return token{kind: tokenComment, value: value, line: line}, err
+		case c == '$':
+			value, err := r.readWhile(isDigit)
+			return token{kind: tokenNAG, value: value, line: line}, err
+		case c == '!' || c == '?':
+			rest, err := r.readWhile(func(c byte) bool { return c == '!' || c == '?' })
+			if err != nil {
+				return token{}, err
+			}
+			nag, ok := suffixNAGs[string(c)+rest]
+			if !ok {
+				return token{}, r.errorf(token{line: line}, "unknown annotation "+string(c)+rest)
+			}
+			return token{kind: tokenNAG, value: strconv.Itoa(nag), line: line}, nil
+		case isSymbolStart(c):
+			rest, err := r.readWhile(isSymbolContinuation)
+			if err != nil {
+				return token{}, err
+			}
+			value := string(c) + rest
+			switch value {
+			case ResultWhiteWins, ResultBlackWins, ResultDraw:
+				return token{kind: tokenResult, value: value, line: line}, nil
+			}
+			return token{kind: tokenSymbol, value: value, line: line}, nil
+		default:
+			return token{}, r.errorf(token{line: line}, "unexpected character "+strconv.QuoteRune(rune(c)))
+		}
+	}
+}
+
+func (r *Reader) readByte() (byte, error) {
+	c, err := r.r.ReadByte()
+	if err == nil {
+		r.atLineStart = c == '\n'
+	}
+	return c, err
+}
+
+func (r *Reader) skipLine() error {
+	for {
+		c, err := r.readByte()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if c == '\n' {
+			r.line++
+			return nil
+		}
+	}
+}
+
+func (r *Reader) readString() (string, error) {
+	var b strings.Builder
+	for {
+		c, err := r.readByte()
+		if err != nil {
+			return "", r.errorf(token{line: r.line}, "unterminated string")
+		}
+		switch c {
+		case '"':
+			return b.String(), nil
+		case '\\':
+			escaped, err := r.readByte()
+			if err != nil {
+				return "", r.errorf(token{line: r.line}, "unterminated string")
+			}
+			b.WriteByte(escaped)
+		case '\n':
+			return "", r.errorf(token{line: r.line}, "unterminated string")
+		default:
+			b.WriteByte(c)
+		}
+	}
+}
+
+func (r *Reader) readComment() (string, error) {
+	var b strings.Builder
+	for {
+		c, err := r.readByte()
+		if err != nil {
+			return "", r.errorf(token{line: r.line}, "unterminated comment")
+		}
+		if c == '}' {
+			return strings.Join(strings.Fields(b.String()), " "), nil
+		}
+		if c == '\n' {
+			r.line++
+		}
+		b.WriteByte(c)
+	}
+}
+
+func (r *Reader) readWhile(accept func(byte) bool) (string, error) {
+	var b strings.Builder
+	for {
+		next, err := r.r.Peek(1)
+		if errors.Is(err, io.EOF) {
+			return b.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if !accept(next[0]) {
+			return b.String(), nil
+		}
+		c, _ := r.readByte()
+		b.WriteByte(c)
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isSymbolStart(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isSymbolContinuation(c byte) bool {
+	return isSymbolStart(c) || strings.IndexByte("_+#=:-/", c) >= 0
+}
+
+func isMoveNumber(symbol string) bool {
+	for i := 0; i < len(symbol); i++ {
+		if !isDigit(symbol[i]) {
+			return false
+		}
+	}
+	return true
+}
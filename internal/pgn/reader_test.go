@@ -0,0 +1,115 @@
+package pgn
+
+import (
+	"chessV2/internal/engine"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const multiGamePGN = `% exported by a test
+[Event "Casual"]
+[Site "Paris"]
+[Date "2026.04.01"]
+[Round "1"]
+[White "Alice"]
+[Black "Bob"]
+[Result "1-0"]
+[ECO "C60"]
+
+{Opening} 1. e4 e5 2. Nf3 Nc6 $1 3. Bb5 {Ruy Lopez} a6 (3... Nf6 4. O-O
+(4. d3 Bc5) 4... Nxe4) 4. Ba4!? ; rest of line ignored
+Nf6 5. O-O 1-0
+
+[Event "Second"]
+[Result "*"]
+[SetUp "1"]
+[FEN "4k3/P7/8/8/8/8/8/4K3 w - - 0 40"]
+
+40. a8=Q+ Kd7 41. Qb7+ *
+`
+
+func TestReaderReadsMultipleGames(t *testing.T) {
+	reader := NewReader(strings.NewReader(multiGamePGN), engine.NewEngine())
+
+	games, err := reader.ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, games, 2)
+
+	first := games[0]
+	white, _ := first.Tag("White")
+	eco, _ := first.Tag("ECO")
+	assert.Equal(t, "Alice", white)
+	assert.Equal(t, "C60", eco)
+	assert.Equal(t, ResultWhiteWins, first.Result)
+	assert.Equal(t, []string{"Opening"}, first.Comments)
+	assert.Equal(t, []string{"e2e4", "e7e5", "g1f3", "b8c6", "f1b5", "a7a6", "b5a4", "g8f6", "e1g1"}, first.UCIMoves())
+	assert.Equal(t, []int{1}, first.Moves[3].NAGs)
+	assert.Equal(t, []int{5}, first.Moves[6].NAGs)
+	assert.Equal(t, []string{"Ruy Lopez"}, first.Moves[4].Comments)
+
+	variations := first.Moves[5].Variations
+	assert.Len(t, variations, 1)
+	assert.Equal(t, []string{"Nf6", "O-O", "Nxe4"}, sanList(variations[0]))
+	assert.Equal(t, "g8f6", variations[0][0].Move.UCI())
+	assert.Equal(t, "f6e4", variations[0][2].Move.UCI())
+	assert.Equal(t, []string{"d3", "Bc5"}, sanList(variations[0][1].Variations[0]))
+
+	second := games[1]
+	assert.Equal(t, ResultUnknown, second.Result)
+	assert.Equal(t, "4k3/P7/8/8/8/8/8/4K3 w - - 0 40", second.StartFEN())
+	assert.Equal(t, []string{"a7a8q", "e8d7", "a8b7"}, second.UCIMoves())
+}
+
+func TestReaderRoundTripsWrittenGames(t *testing.T) {
+	e := engine.NewEngine()
+	games, err := NewReader(strings.NewReader(multiGamePGN), e).ReadAll()
+	assert.NoError(t, err)
+
+	var b strings.Builder
+	for _, game := range games {
+		assert.NoError(t, Write(&b, game))
+	}
+
+	reread, err := NewReader(strings.NewReader(b.String()), e).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, reread, len(games))
+	for i := range games {
+		assert.Equal(t, games[i].Moves, reread[i].Moves)
+		assert.Equal(t, games[i].Comments, reread[i].Comments)
+		assert.Equal(t, games[i].String(), reread[i].String())
+	}
+}
+
+func TestReaderRejectsInvalidGames(t *testing.T) {
+	data := map[string]struct {
+		pgn     string
+		message string
+	}{
+		"illegal move":              {pgn: "1. e4 e5 2. Ke3 *", message: "move 2. Ke3"},
+		"illegal variation move":    {pgn: "1. e4 (1. e5) e5 *", message: "move 1. e5"},
+		"unterminated variation":    {pgn: "1. e4 (1. d4 *", message: "game termination inside a variation"},
+		"unclosed tag":              {pgn: "[Event \"x\"\n1. e4 *", message: "expected ]"},
+		"unterminated tag value":    {pgn: "[Event \"x]\n1. e4 *", message: "unterminated string"},
+		"unexpected closing paren":  {pgn: "1. e4 ) *", message: "unexpected )"},
+		"variation before any move": {pgn: "(1. e4) *", message: "variation without a preceding move"},
+		"illegal setup":             {pgn: "[SetUp \"1\"]\n[FEN \"4k3/8/8/8/8/8/8/4K3 w q - 0 1\"]\n\n1. Kd2 *", message: "castle rights without the rook"},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewReader(strings.NewReader(d.pgn), engine.NewEngine()).Read()
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), d.message)
+		})
+	}
+}
+
+func sanList(moves []Move) []string {
+	sans := make([]string, 0, len(moves))
+	for _, move := range moves {
+		sans = append(sans, move.SAN)
+	}
+	return sans
+}
@@ -0,0 +1,156 @@
+package pgn
+
+import (
+	board "chessV2/internal/board"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const maxLineLength = 79
+
+// Write exports one game in PGN export format: the seven-tag roster first,
+// then custom tags, then movetext wrapped at 79 columns.
+func Write(w io.Writer, game *Game) error {
+	pos, err := board.NewPositionFromFEN(game.StartFEN())
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	writeTags(&b, game)
+	b.WriteByte('\n')
+
+	tokens := make([]string, 0, len(game.Moves)*2+len(game.Comments)+1)
+	for _, comment := range game.Comments {
+		tokens = append(tokens, formatComment(comment))
+	}
+
+	startPly := (pos.FullMoveNumber() - 1) * 2
+	if pos.ActiveColor() == board.Black {
+		startPly++
+	}
+	tokens = appendMoveTokens(tokens, game.Moves, startPly, len(game.Comments) > 0)
+	tokens = append(tokens, resultOrUnknown(game.Result))
+
+	writeWrapped(&b, tokens)
+	b.WriteString("\n\n")
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// String returns the game in PGN export format.
+func (g *Game) String() string {
+	var b strings.Builder
+	if err := Write(&b, g); err != nil {
+		return ""
+	}
+	return b.String()
+}
+
+func writeTags(b *strings.Builder, game *Game) {
+	for _, name := range SevenTagRoster {
+		value, ok := game.Tag(name)
+		if !ok || value == "" {
+			value = rosterDefault(name)
+		}
+		writeTag(b, name, value)
+	}
+
+	for _, tag := range game.Tags {
+		if isRosterTag(tag.Name) {
+			continue
+		}
+		writeTag(b, tag.Name, tag.Value)
+	}
+}
+
+func writeTag(b *strings.Builder, name, value string) {
+	b.WriteByte('[')
+	b.WriteString(name)
+	b.WriteString(" \"")
+	b.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value))
+	b.WriteString("\"]\n")
+}
+
+func appendMoveTokens(tokens []string, moves []Move, ply int, needNumber bool) []string {
+	for i, move := range moves {
+		current := ply + i
+		moveNumber := current/2 + 1
+		if current%2 == 0 {
+			tokens = append(tokens, strconv.Itoa(moveNumber)+".")
+		} else if needNumber || i == 0 {
+			tokens = append(tokens, strconv.Itoa(moveNumber)+"...")
+		}
+		tokens = append(tokens, move.SAN)
+		needNumber = false
+
+		for _, nag := range move.NAGs {
+			tokens = append(tokens, "$"+strconv.Itoa(nag))
+		}
+		for _, comment := range move.Comments {
+			tokens = append(tokens, formatComment(comment))
+			needNumber = true
+		}
+		for _, variation := range move.Variations {
+			if len(variation) == 0 {
+				continue
+			}
+			start := len(tokens)
+			tokens = appendMoveTokens(tokens, variation, current, true)
+			tokens[start] = "(" + tokens[start]
+			tokens[len(tokens)-1] += ")"
+			needNumber = true
+		}
+	}
+	return tokens
+}
+
+func writeWrapped(b *strings.Builder, tokens []string) {
+	lineLength := 0
+	for _, token := range tokens {
+		if lineLength > 0 {
+			if lineLength+1+len(token) > maxLineLength {
+				b.WriteByte('\n')
+				lineLength = 0
+			} else {
+				b.WriteByte(' ')
+				lineLength++
+			}
+		}
+		b.WriteString(token)
+		lineLength += len(token)
+	}
+}
+
+func formatComment(comment string) string {
+	return "{" + strings.ReplaceAll(comment, "}", ")") + "}"
+}
+
+func rosterDefault(name string) string {
+	switch name {
+	case "Date":
+		return "????.??.??"
+	case "Result":
+		return ResultUnknown
+	default:
+		return "?"
+	}
+}
+
+func resultOrUnknown(result string) string {
+	if result == "" {
+		return ResultUnknown
+	}
+	return result
+}
+
+func isRosterTag(name string) bool {
+	for _, roster := range SevenTagRoster {
+		if roster == name {
+			return true
+		}
+	}
+	return false
+}
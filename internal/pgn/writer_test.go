@@ -0,0 +1,109 @@
+package pgn
+
+import (
+	board "chessV2/internal/board"
+	"chessV2/internal/engine"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteSevenTagRosterAndCustomTags(t *testing.T) {
+	game := NewGame()
+	game.SetTag("White", "gochess")
+	game.SetTag("Annotator", "referee")
+	game.SetTag("Event", `Test "quoted" \ event`)
+	game.Result = ResultDraw
+
+	expected := strings.Join([]string{
+		`[Event "Test \"quoted\" \\ event"]`,
+		`[Site "?"]`,
+		`[Date "????.??.??"]`,
+		`[Round "?"]`,
+		`[White "gochess"]`,
+		`[Black "?"]`,
+		`[Result "1/2-1/2"]`,
+		`[Annotator "referee"]`,
+		``,
+		`1/2-1/2`,
+		``,
+		``,
+	}, "\n")
+
+	assert.Equal(t, expected, game.String())
+}
+
+func TestWriteMovetext(t *testing.T) {
+	data := map[string]struct {
+		game     *Game
+		expected string
+	}{
+		"comments nags and variations": {
+			game: &Game{
+				Comments: []string{"Ruy Lopez"},
+				Moves: []Move{
+					{SAN: "e4"},
+					{SAN: "e5", NAGs: []int{1}},
+					{SAN: "Nf3", Comments: []string{"main line"}},
+					{SAN: "Nc6", Variations: [][]Move{{{SAN: "d6"}, {SAN: "d4"}}}},
+					{SAN: "Bb5"},
+				},
+				Result: ResultWhiteWins,
+			},
+			expected: "{Ruy Lopez} 1. e4 e5 $1 2. Nf3 {main line} 2... Nc6 (2... d6 3. d4) 3. Bb5 1-0",
+		},
+		"black to move start": {
+			game: &Game{
+				Tags:   []Tag{{Name: "SetUp", Value: "1"}, {Name: "FEN", Value: "4k3/8/8/8/8/8/8/R3K3 b Q - 0 12"}},
+				Moves:  []Move{{SAN: "Kd7"}, {SAN: "Ra7+"}},
+				Result: ResultUnknown,
+			},
+			expected: "12... Kd7 13. Ra7+ *",
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			text := d.game.String()
+			parts := strings.SplitN(text, "\n\n", 2)
+			assert.Len(t, parts, 2)
+			assert.Equal(t, d.expected+"\n\n", parts[1])
+		})
+	}
+}
+
+func TestWriteWrapsLongMovetext(t *testing.T) {
+	e := engine.NewEngine()
+	moves := []string{"g1f3", "g8f6", "f3g1", "f6g8"}
+	var line []string
+	for i := 0; i < 10; i++ {
+		line = append(line, moves...)
+	}
+
+	game, err := NewGameFromUCI(e, board.FenStartPos, line)
+	assert.NoError(t, err)
+
+	text := game.String()
+	for _, row := range strings.Split(text, "\n") {
+		assert.LessOrEqual(t, len(row), maxLineLength)
+	}
+	assert.Contains(t, text, "1. Nf3 Nf6 2. Ng1 Ng8")
+}
+
+func TestNewGameFromUCI(t *testing.T) {
+	e := engine.NewEngine()
+	fen := "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"
+
+	game, err := NewGameFromUCI(e, fen, []string{"e1g1", "e8c8", "a1a8"})
+	assert.NoError(t, err)
+
+	fenTag, ok := game.Tag("FEN")
+	assert.True(t, ok)
+	assert.Equal(t, fen, fenTag)
+	assert.Equal(t, []string{"O-O", "O-O-O", "Ra8+"}, []string{game.Moves[0].SAN, game.Moves[1].SAN, game.Moves[2].SAN})
+	assert.Equal(t, []string{"e1g1", "e8c8", "a1a8"}, game.UCIMoves())
+
+	_, err = NewGameFromUCI(e, fen, []string{"e1e3"})
+	assert.Error(t, err)
+}
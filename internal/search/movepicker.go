@@ -0,0 +1,212 @@
+package search
+
+import (
+	board "chessV2/internal/board"
+	"chessV2/internal/movegen"
+)
+
+type pickStage uint8
+
+const (
+	pickTTMove pickStage = iota
+	pickGoodCaptures
+	pickKillers
+	pickQuiets
+	pickBadCaptures
+	pickEvasions
+	pickDone
+)
+
+// movePicker hands out the legal moves of a node one at a time, generating
+// each stage only when the previous one failed to cut off: the TT move, the
+// captures and promotions SEE does not lose material on, the killer moves,
+// the remaining quiet moves by history score, and the losing captures last.
+// The TT move and the killers are checked with movegen.IsLegal and played
+// before anything is generated. A side in check gets every evasion in
+// scoreMove order instead, and a captures-only picker stops after the good
+// captures.
+type movePicker struct {
+	searcher     *AlphaBetaSearcher
+	pos          *board.Position
+	ply          int
+	ttMove       board.Move
+	stage        pickStage
+	capturesOnly bool
+
+	captures      [256]board.Move
+	captureScores [256]int
+	captureCount  int
+	goodCaptures  int
+	captureNext   int
+	capturesReady bool
+
+	quiets      [256]board.Move
+	quietScores [256]int
+	quietCount  int
+	quietNext   int
+	quietsReady bool
+
+	killers    [2]board.Move
+	killerNext int
+}
+
+// newMovePicker and newCapturePicker return the picker by value so it lives
+// in the caller's frame; a heap picker would cost an allocation per node.
+func (s *AlphaBetaSearcher) newMovePicker(pos *board.Position, ply int, ttMove board.Move) movePicker {
+	p := movePicker{
+		searcher: s,
+		pos:      pos,
+		ply:      ply,
+		ttMove:   ttMove,
+		stage:    pickTTMove,
+	}
+	if movegen.IsKingInCheck(pos, pos.ActiveColor()) {
+		p.generateEvasions(ttMove)
+	}
+	return p
+}
+
+func (s *AlphaBetaSearcher) newCapturePicker(pos *board.Position, ply int) movePicker {
+	return movePicker{
+		searcher:     s,
+		pos:          pos,
+		ply:          ply,
+		stage:        pickGoodCaptures,
+		capturesOnly: true,
+	}
+}
+
+func (p *movePicker) next() (board.Move, bool) {
+	for {
+		switch p.stage {
+		case pickTTMove:
+			p.stage = pickGoodCaptures
+			if p.ttMove == board.NoMove {
+				continue
+			}
+			if !movegen.IsLegal(p.pos, p.ttMove) {
+				p.ttMove = board.NoMove
+				continue
+			}
+			return p.ttMove, true
+		case pickGoodCaptures:
+			p.generateCaptures()
+			if move, ok := p.pickBest(p.captures[:], p.captureScores[:], &p.captureNext, p.goodCaptures); ok {
+				return move, true
+			}
+			if p.capturesOnly {
+				p.stage = pickDone
+				continue
+			}
+			p.stage = pickKillers
+		case pickKillers:
+			if move, ok := p.nextKiller(); ok {
+				return move, true
+			}
+			p.stage = pickQuiets
+		case pickQuiets:
+			p.generateQuiets()
+			if move, ok := p.pickBest(p.quiets[:], p.quietScores[:], &p.quietNext, p.quietCount); ok {
+				return move, true
+			}
+			p.stage = pickBadCaptures
+		case pickBadCaptures, pickEvasions:
+			if move, ok := p.pickBest(p.captures[:], p.captureScores[:], &p.captureNext, p.captureCount); ok {
+				return move, true
+			}
+			p.stage = pickDone
+		default:
+			return board.NoMove, false
+		}
+	}
+}
+
+// nextKiller returns the next killer of the ply that is a legal quiet move
+// here. Killers come from sibling nodes and need not be legal in this one.
+func (p *movePicker) nextKiller() (board.Move, bool) {
+	for p.killerNext < len(p.killers) {
+		slot := p.killerNext
+		p.killerNext++
+
+		move := p.searcher.killerMove(p.ply, slot)
+		if move == board.NoMove || move == p.ttMove || isTacticalMove(p.pos, move) || !movegen.IsLegal(p.pos, move) {
+			continue
+		}
+		p.killers[slot] = move
+		return move, true
+	}
+	return board.NoMove, false
+}
+
+// generateCaptures moves the captures SEE does not lose material on to the
+// front of the list; the losing ones stay behind goodCaptures.
+func (p *movePicker) generateCaptures() {
+	if p.capturesReady {
+		return
+	}
+	p.capturesReady = true
+
+	s := p.searcher
+	p.captureCount = s.moveGenerator.CapturesInto(p.pos, s.positionUpdater, p.captures[:])
+	for i := 0; i < p.captureCount; i++ {
+		move := p.captures[i]
+		score := tacticalScore(p.pos, move)
+		if !movegen.SEEGreaterOrEqual(p.pos, move, 0) {
+			p.captureScores[i] = score
+			continue
+		}
+
+		p.captures[i], p.captureScores[i] = p.captures[p.goodCaptures], p.captureScores[p.goodCaptures]
+		p.captures[p.goodCaptures], p.captureScores[p.goodCaptures] = move, score
+		p.goodCaptures++
+	}
+}
+
+func (p *movePicker) generateQuiets() {
+	if p.quietsReady {
+		return
+	}
+	p.quietsReady = true
+
+	s := p.searcher
+	p.quietCount = s.moveGenerator.QuietsInto(p.pos, s.positionUpdater, p.quiets[:])
+	for i := 0; i < p.quietCount; i++ {
+		p.quietScores[i] = s.quietScore(p.quiets[i], p.ply)
+	}
+}
+
+// generateEvasions puts every legal move in the capture list. The TT move is
+// ordered first by scoreMove rather than played ahead of generation.
+func (p *movePicker) generateEvasions(ttMove board.Move) {
+	s := p.searcher
+	p.stage = pickEvasions
+	p.ttMove = board.NoMove
+	p.capturesReady = true
+	p.captureCount = s.moveGenerator.EvasionsInto(p.pos, s.positionUpdater, p.captures[:])
+	for i := 0; i < p.captureCount; i++ {
+		p.captureScores[i] = s.scoreMove(p.pos, p.captures[i], p.ply, ttMove)
+	}
+}
+
+// pickBest selection-sorts one move at a time, which is cheaper than a full
+// sort when a cutoff comes early. The TT move and the killers are skipped as
+// they have already been searched.
+func (p *movePicker) pickBest(moves []board.Move, scores []int, next *int, end int) (board.Move, bool) {
+	for *next < end {
+		best := *next
+		for i := best + 1; i < end; i++ {
+			if scores[i] > scores[best] {
+				best = i
+			}
+		}
+		moves[*next], moves[best] = moves[best], moves[*next]
+		scores[*next], scores[best] = scores[best], scores[*next]
+
+		move := moves[*next]
+		*next++
+		if move != p.ttMove && move != p.killers[0] && move != p.killers[1] {
+			return move, true
+		}
+	}
+	return board.NoMove, false
+}
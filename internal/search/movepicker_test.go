@@ -0,0 +1,121 @@
+package search
+
+import (
+	board "chessV2/internal/board"
+	"chessV2/internal/eval"
+	"chessV2/internal/movegen"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMovePickerStages(t *testing.T) {
+	searcher := NewAlphaBetaSearcher(
+		movegen.NewPseudoLegalMoveGenerator(),
+		board.NewPositionUpdater(),
+		eval.NewStaticEvaluator(),
+	)
+	pos, err := board.NewPositionFromFEN("4k3/8/2p5/3p1n2/4P3/8/8/3QK3 w - - 0 1")
+	assert.NoError(t, err)
+
+	ttMove := board.NewMove(board.Piece(board.White|board.Queen), board.D1, board.D2, board.NormalMove)
+	picked := pickAll(searcher.newMovePicker(pos, 0, ttMove))
+
+	assert.ElementsMatch(t, legalMovesUCI(t, searcher, pos), picked)
+	assert.Equal(t, []string{"d1d2", "e4f5", "e4d5"}, picked[:3])
+	assert.Equal(t, "d1d5", picked[len(picked)-1])
+	assert.Equal(t, 1, countUCI(picked, "d1d2"))
+}
+
+func TestMovePickerPlaysLegalKillersBeforeQuiets(t *testing.T) {
+	searcher := NewAlphaBetaSearcher(
+		movegen.NewPseudoLegalMoveGenerator(),
+		board.NewPositionUpdater(),
+		eval.NewStaticEvaluator(),
+	)
+	pos, err := board.NewPositionFromFEN("4k3/8/2p5/3p1n2/4P3/8/8/3QK3 w - - 0 1")
+	assert.NoError(t, err)
+
+	searcher.killerMoves[0][0] = board.NewMove(board.Piece(board.White|board.Knight), board.G1, board.F3, board.NormalMove)
+	searcher.killerMoves[0][1] = board.NewMove(board.Piece(board.White|board.Queen), board.D1, board.H5, board.NormalMove)
+	picked := pickAll(searcher.newMovePicker(pos, 0, board.NoMove))
+
+	assert.ElementsMatch(t, legalMovesUCI(t, searcher, pos), picked)
+	assert.Equal(t, []string{"e4f5", "e4d5", "d1h5"}, picked[:3])
+	assert.Equal(t, 1, countUCI(picked, "d1h5"))
+	assert.Equal(t, 0, countUCI(picked, "g1f3"))
+}
+
+func TestMovePickerSkipsTTMoveFromAnotherPosition(t *testing.T) {
+	searcher := NewAlphaBetaSearcher(
+		movegen.NewPseudoLegalMoveGenerator(),
+		board.NewPositionUpdater(),
+		eval.NewStaticEvaluator(),
+	)
+	pos, err := board.NewPositionFromFEN(board.FenStartPos)
+	assert.NoError(t, err)
+
+	ttMove := board.NewMove(board.Piece(board.White|board.Knight), board.E4, board.F6, board.Capture)
+	picked := pickAll(searcher.newMovePicker(pos, 0, ttMove))
+
+	assert.ElementsMatch(t, legalMovesUCI(t, searcher, pos), picked)
+}
+
+func TestMovePickerReturnsEvasionsInCheck(t *testing.T) {
+	searcher := NewAlphaBetaSearcher(
+		movegen.NewPseudoLegalMoveGenerator(),
+		board.NewPositionUpdater(),
+		eval.NewStaticEvaluator(),
+	)
+	pos, err := board.NewPositionFromFEN("4k3/8/8/8/8/5n2/3P4/R3K3 w Q - 0 1")
+	assert.NoError(t, err)
+
+	picked := pickAll(searcher.newMovePicker(pos, 0, board.NoMove))
+
+	assert.ElementsMatch(t, legalMovesUCI(t, searcher, pos), picked)
+	assert.NotContains(t, picked, "e1c1")
+}
+
+func TestCapturePickerSkipsQuietsAndLosingCaptures(t *testing.T) {
+	searcher := NewAlphaBetaSearcher(
+		movegen.NewPseudoLegalMoveGenerator(),
+		board.NewPositionUpdater(),
+		eval.NewStaticEvaluator(),
+	)
+	pos, err := board.NewPositionFromFEN("4k3/8/2p5/3p1n2/4P3/8/8/3QK3 w - - 0 1")
+	assert.NoError(t, err)
+
+	picked := pickAll(searcher.newCapturePicker(pos, 0))
+
+	assert.Equal(t, []string{"e4f5", "e4d5"}, picked)
+}
+
+func pickAll(picker movePicker) []string {
+	picked := make([]string, 0)
+	for move, ok := picker.next(); ok; move, ok = picker.next() {
+		picked = append(picked, move.UCI())
+	}
+	return picked
+}
+
+func legalMovesUCI(t *testing.T, searcher *AlphaBetaSearcher, pos *board.Position) []string {
+	t.Helper()
+
+	var moves [256]board.Move
+	count := searcher.moveGenerator.LegalMovesInto(pos, searcher.positionUpdater, moves[:])
+	uciMoves := make([]string, 0, count)
+	for _, move := range moves[:count] {
+		uciMoves = append(uciMoves, move.UCI())
+	}
+	return uciMoves
+}
+
+func countUCI(moves []string, uci string) int {
+	count := 0
+	for _, move := range moves {
+		if move == uci {
+			count++
+		}
+	}
+	return count
+}
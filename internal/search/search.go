@@ -15,11 +15,17 @@ var errSearchStopped = errors.New("search stopped")
 const searchMaxPly = 128
 const repetitionContemptMax = 30
 
+// quiescenceCheckPlies is how many quiescence plies also try quiet checks.
+const quiescenceCheckPlies = 1
+
 type Limits struct {
 	Depth    int
 	MoveTime time.Duration
 	Stop     <-chan struct{}
 	History  []uint64
+	// OnIteration, when set, is called with the result of every completed
+	// iteration, before the search moves on to the next depth.
+	OnIteration func(Result)
 }
 
 type Stats struct {
@@ -34,6 +40,12 @@ type Result struct {
 	BestMove board.Move
 	Score    eval.Score
 	Stats    Stats
+	// PV is the principal variation, starting with BestMove. Every move is
+	// legal in the position the moves before it lead to.
+	PV []board.Move
+	// Book is set when BestMove comes from an opening book rather than a
+	// search; Score and Stats are then zero and carry no evaluation.
+	Book bool
 }
 
 type Searcher interface {
@@ -47,12 +59,14 @@ type AlphaBetaSearcher struct {
 	evaluator       eval.Evaluator
 	tt              *searchTT
 	killerMoves     [searchMaxPly][2]board.Move
-	historyScores   [2][64][64]int
+	historyScores   [2][4096]int
+	// pvTable[ply] holds the best line found from ply, pvLength[ply] moves long.
+	pvTable  [searchMaxPly][searchMaxPly]board.Move
+	pvLength [searchMaxPly]int
 }
 
 type repetitionTracker struct {
-	stack  []uint64
-	counts map[uint64]uint8
+	stack []uint64
 }
 
 func NewAlphaBetaSearcher(moveGenerator *movegen.PseudoLegalMoveGenerator, positionUpdater board.MoveApplier, evaluator eval.Evaluator) *AlphaBetaSearcher {
@@ -76,7 +90,11 @@ func (s *AlphaBetaSearcher) Search(pos *board.Position, limits Limits) (Result,
 	if limits.MoveTime > 0 {
 		result, err = s.searchIterative(pos, limits)
 	} else {
-		result, err = s.searchDepth(pos, limits.Depth, time.Time{}, limits.Stop, newRepetitionTracker(pos, limits.History))
+		var complete bool
+		result, complete, err = s.searchDepth(pos, limits.Depth, time.Time{}, limits.Stop, newRepetitionTracker(pos, limits.History))
+		if err == nil && complete && limits.OnIteration != nil {
+			limits.OnIteration(s.ensureBestMove(pos, result))
+		}
 	}
 	if err != nil {
 		return Result{}, err
@@ -101,7 +119,7 @@ func (s *AlphaBetaSearcher) searchIterative(pos *board.Position, limits Limits)
 			iterPos = refreshed
 		}
 
-		result, err := s.searchDepth(iterPos, depth, deadline, limits.Stop, newRepetitionTracker(iterPos, limits.History))
+		result, complete, err := s.searchDepth(iterPos, depth, deadline, limits.Stop, newRepetitionTracker(iterPos, limits.History))
 		if err != nil {
 			if errors.Is(err, errSearchTimeout) || errors.Is(err, errSearchStopped) {
 				if haveComplete {
@@ -128,6 +146,7 @@ func (s *AlphaBetaSearcher) searchIterative(pos *board.Position, limits Limits)
 						Depth: 0,
 						Time:  time.Since(start),
 					},
+					PV: []board.Move{fallbackMoves[0]},
 				}, nil
 			}
 			return Result{}, err
@@ -135,6 +154,11 @@ func (s *AlphaBetaSearcher) searchIterative(pos *board.Position, limits Limits)
 
 		lastComplete = result
 		haveComplete = true
+		if complete && limits.OnIteration != nil {
+			report := result
+			report.Stats.Time = time.Since(start)
+			limits.OnIteration(report)
+		}
 		if time.Now().After(deadline) {
 			break
 		}
@@ -144,9 +168,11 @@ func (s *AlphaBetaSearcher) searchIterative(pos *board.Position, limits Limits)
 	return lastComplete, nil
 }
 
-func (s *AlphaBetaSearcher) searchDepth(pos *board.Position, depth int, deadline time.Time, stop <-chan struct{}, repetitions *repetitionTracker) (Result, error) {
+// searchDepth searches the root moves to depth and reports whether it got
+// through all of them before the deadline or a stop.
+func (s *AlphaBetaSearcher) searchDepth(pos *board.Position, depth int, deadline time.Time, stop <-chan struct{}, repetitions *repetitionTracker) (Result, bool, error) {
 	if depth <= 0 {
-		return Result{}, ErrInvalidLimits
+		return Result{}, false, ErrInvalidLimits
 	}
 
 	start := time.Now()
@@ -162,13 +188,14 @@ func (s *AlphaBetaSearcher) searchDepth(pos *board.Position, depth int, deadline
 				Depth: depth,
 				Time:  time.Since(start),
 			},
-		}, nil
+		}, true, nil
 	}
 	var ttMove board.Move
 	if entry, ok := s.tt.probe(pos.ZobristKey(), depth, 0); ok {
 		ttMove = entry.bestMove
 	}
 	s.orderMoves(pos, moves[:moveCount], 0, ttMove)
+	s.pvLength[0] = 0
 
 	bestMove := moves[0]
 	bestScore := -eval.InfinityScore
@@ -185,7 +212,8 @@ func (s *AlphaBetaSearcher) searchDepth(pos *board.Position, depth int, deadline
 					BestMove: bestMove,
 					Score:    bestScore,
 					Stats:    stats,
-				}, nil
+					PV:       s.rootPV(pos),
+				}, false, nil
 			}
 			return Result{
 				BestMove: moves[0],
@@ -194,7 +222,8 @@ func (s *AlphaBetaSearcher) searchDepth(pos *board.Position, depth int, deadline
 					Depth: depth,
 					Time:  time.Since(start),
 				},
-			}, nil
+				PV: []board.Move{moves[0]},
+			}, false, nil
 		}
 
 		move := moves[i]
@@ -210,7 +239,8 @@ func (s *AlphaBetaSearcher) searchDepth(pos *board.Position, depth int, deadline
 					BestMove: bestMove,
 					Score:    bestScore,
 					Stats:    stats,
-				}, nil
+					PV:       s.rootPV(pos),
+				}, false, nil
 			}
 			if errors.Is(err, errSearchTimeout) || errors.Is(err, errSearchStopped) {
 				return Result{
@@ -220,15 +250,17 @@ func (s *AlphaBetaSearcher) searchDepth(pos *board.Position, depth int, deadline
 						Depth: depth,
 						Time:  time.Since(start),
 					},
-				}, nil
+					PV: []board.Move{moves[0]},
+				}, false, nil
 			}
-			return Result{}, err
+			return Result{}, false, err
 		}
 		score = -score
 
 		if score > bestScore {
 			bestScore = score
 			bestMove = move
+			s.updatePV(0, move)
 		}
 		haveComplete = true
 		if score > alpha {
@@ -247,7 +279,8 @@ func (s *AlphaBetaSearcher) searchDepth(pos *board.Position, depth int, deadline
 		BestMove: bestMove,
 		Score:    bestScore,
 		Stats:    stats,
-	}, nil
+		PV:       s.rootPV(pos),
+	}, true, nil
 }
 
 func (s *AlphaBetaSearcher) NewGame() {
@@ -257,13 +290,14 @@ func (s *AlphaBetaSearcher) NewGame() {
 }
 
 func (s *AlphaBetaSearcher) negamax(pos *board.Position, depth int, ply int, alpha eval.Score, beta eval.Score, stats *Stats, deadline time.Time, stop <-chan struct{}, repetitions *repetitionTracker) (eval.Score, error) {
+	s.pvLength[boundedPly(ply)] = 0
 	if err := shouldStop(deadline, stop); err != nil {
 		return 0, err
 	}
 
 	stats.Nodes++
-	if repetitions.isThreefold() {
-		return s.repetitionScore(pos), nil
+	if score, ok := s.drawScore(pos, repetitions); ok {
+		return score, nil
 	}
 
 	key := pos.ZobristKey()
@@ -290,22 +324,20 @@ func (s *AlphaBetaSearcher) negamax(pos *board.Position, depth int, ply int, alp
 		}
 	}
 
-	var moves [256]board.Move
-	moveCount := s.moveGenerator.LegalMovesInto(pos, s.positionUpdater, moves[:])
-	if moveCount == 0 {
-		return terminalScore(pos, ply), nil
+	if pos.IsFiftyMoveDraw() {
+		return s.fiftyMoveScore(pos, ply), nil
 	}
 
 	if depth == 0 {
-		return s.quiescence(pos, ply, alpha, beta, stats, deadline, stop, repetitions)
+		return s.quiescence(pos, ply, 0, alpha, beta, stats, deadline, stop, repetitions)
 	}
 
-	s.orderMoves(pos, moves[:moveCount], ply, ttMove)
-
+	picker := s.newMovePicker(pos, ply, ttMove)
 	bestScore := -eval.InfinityScore
-	bestMove := board.Move{}
-	for i := 0; i < moveCount; i++ {
-		move := moves[i]
+	bestMove := board.NoMove
+	searched := 0
+	for move, ok := picker.next(); ok; move, ok = picker.next() {
+		searched++
 		history := s.positionUpdater.MakeMove(pos, move)
 		repetitions.push(pos.ZobristKey())
 		score, err := s.negamax(pos, depth-1, ply+1, -beta, -alpha, stats, deadline, stop, repetitions)
@@ -322,6 +354,7 @@ func (s *AlphaBetaSearcher) negamax(pos *board.Position, depth int, ply int, alp
 		}
 		if score > alpha {
 			alpha = score
+			s.updatePV(ply, move)
 		}
 		if alpha >= beta {
 			if !isTacticalMove(pos, move) {
@@ -332,6 +365,9 @@ func (s *AlphaBetaSearcher) negamax(pos *board.Position, depth int, ply int, alp
 			break
 		}
 	}
+	if searched == 0 {
+		return terminalScore(pos, ply), nil
+	}
 
 	bound := ttBoundExact
 	if bestScore <= alphaStart {
@@ -344,51 +380,46 @@ func (s *AlphaBetaSearcher) negamax(pos *board.Position, depth int, ply int, alp
 	return bestScore, nil
 }
 
-func (s *AlphaBetaSearcher) quiescence(pos *board.Position, ply int, alpha eval.Score, beta eval.Score, stats *Stats, deadline time.Time, stop <-chan struct{}, repetitions *repetitionTracker) (eval.Score, error) {
+// quiescence resolves captures and promotions until the position is quiet.
+// A side in check may not stand pat: every evasion is searched, so mates and
+// quiet-only defences are scored correctly. The first quiescenceCheckPlies
+// plies also try quiet checks.
+func (s *AlphaBetaSearcher) quiescence(pos *board.Position, ply int, qsPly int, alpha eval.Score, beta eval.Score, stats *Stats, deadline time.Time, stop <-chan struct{}, repetitions *repetitionTracker) (eval.Score, error) {
 	if err := shouldStop(deadline, stop); err != nil {
 		return 0, err
 	}
 
 	stats.QuiescenceNodes++
-	if repetitions.isThreefold() {
-		return s.repetitionScore(pos), nil
-	}
-
-	standPat := s.evaluator.Evaluate(pos)
-	if standPat >= beta {
-		stats.Cutoffs++
-		return beta, nil
+	if score, ok := s.drawScore(pos, repetitions); ok {
+		return score, nil
 	}
-	if standPat > alpha {
-		alpha = standPat
-	}
-
-	var moves [256]board.Move
-	moveCount := s.moveGenerator.LegalMovesInto(pos, s.positionUpdater, moves[:])
-	if moveCount == 0 {
-		return terminalScore(pos, ply), nil
+	if pos.IsFiftyMoveDraw() {
+		return s.fiftyMoveScore(pos, ply), nil
 	}
 
-	s.orderMoves(pos, moves[:moveCount], ply, board.Move{})
-	for i := 0; i < moveCount; i++ {
-		move := moves[i]
-		if !isTacticalMove(pos, move) {
-			continue
+	inCheck := movegen.IsKingInCheck(pos, pos.ActiveColor())
+	var picker movePicker
+	if inCheck {
+		picker = s.newMovePicker(pos, ply, board.NoMove)
+	} else {
+		standPat := s.evaluator.Evaluate(pos)
+		if standPat >= beta {
+			stats.Cutoffs++
+			return beta, nil
 		}
-		if isCaptureMove(pos, move) && s.seeLite(pos, move) < 0 {
-			continue
+		if standPat > alpha {
+			alpha = standPat
 		}
+		picker = s.newCapturePicker(pos, ply)
+	}
 
-		history := s.positionUpdater.MakeMove(pos, move)
-		repetitions.push(pos.ZobristKey())
-		score, err := s.quiescence(pos, ply+1, -beta, -alpha, stats, deadline, stop, repetitions)
-		repetitions.pop()
-		s.positionUpdater.UnMakeMove(pos, history)
+	moveCount := 0
+	for move, ok := picker.next(); ok; move, ok = picker.next() {
+		moveCount++
+		score, err := s.quiescenceMove(pos, move, ply, qsPly, alpha, beta, stats, deadline, stop, repetitions)
 		if err != nil {
 			return 0, err
 		}
-		score = -score
-
 		if score >= beta {
 			stats.Cutoffs++
 			return beta, nil
@@ -397,10 +428,43 @@ func (s *AlphaBetaSearcher) quiescence(pos *board.Position, ply int, alpha eval.
 			alpha = score
 		}
 	}
+	if inCheck {
+		if moveCount == 0 {
+			return terminalScore(pos, ply), nil
+		}
+		return alpha, nil
+	}
+
+	if qsPly < quiescenceCheckPlies {
+		var checks [256]board.Move
+		checkCount := s.moveGenerator.QuietChecksInto(pos, s.positionUpdater, checks[:])
+		for i := 0; i < checkCount; i++ {
+			score, err := s.quiescenceMove(pos, checks[i], ply, qsPly, alpha, beta, stats, deadline, stop, repetitions)
+			if err != nil {
+				return 0, err
+			}
+			if score >= beta {
+				stats.Cutoffs++
+				return beta, nil
+			}
+			if score > alpha {
+				alpha = score
+			}
+		}
+	}
 
 	return alpha, nil
 }
 
+func (s *AlphaBetaSearcher) quiescenceMove(pos *board.Position, move board.Move, ply int, qsPly int, alpha eval.Score, beta eval.Score, stats *Stats, deadline time.Time, stop <-chan struct{}, repetitions *repetitionTracker) (eval.Score, error) {
+	history := s.positionUpdater.MakeMove(pos, move)
+	repetitions.push(pos.ZobristKey())
+	score, err := s.quiescence(pos, ply+1, qsPly+1, -beta, -alpha, stats, deadline, stop, repetitions)
+	repetitions.pop()
+	s.positionUpdater.UnMakeMove(pos, history)
+	return -score, err
+}
+
 func terminalScore(pos *board.Position, ply int) eval.Score {
 	if movegen.IsKingInCheck(pos, pos.ActiveColor()) {
 		return eval.MatedIn(ply)
@@ -408,6 +472,16 @@ func terminalScore(pos *board.Position, ply int) eval.Score {
 	return eval.DrawScore
 }
 
+// fiftyMoveScore only generates moves once the clock has run out, since a
+// mate delivered on the last allowed move still wins.
+func (s *AlphaBetaSearcher) fiftyMoveScore(pos *board.Position, ply int) eval.Score {
+	var moves [256]board.Move
+	if s.moveGenerator.LegalMovesInto(pos, s.positionUpdater, moves[:]) == 0 {
+		return terminalScore(pos, ply)
+	}
+	return s.repetitionScore(pos)
+}
+
 func shouldStop(deadline time.Time, stop <-chan struct{}) error {
 	select {
 	case <-stop:
@@ -434,15 +508,24 @@ func (s *AlphaBetaSearcher) repetitionScore(pos *board.Position) eval.Score {
 	return -bias
 }
 
-func newRepetitionTracker(pos *board.Position, history []uint64) *repetitionTracker {
-	tracker := &repetitionTracker{
-		stack:  make([]uint64, 0, len(history)+1),
-		counts: make(map[uint64]uint8, len(history)+1),
+// drawScore scores repetitions and dead positions before any move is
+// generated. The fifty-move rule waits for the move count since mate on the
+// last allowed move still wins.
+func (s *AlphaBetaSearcher) drawScore(pos *board.Position, repetitions *repetitionTracker) (eval.Score, bool) {
+	switch pos.DrawReason(repetitions.stack) {
+	case board.DrawByRepetition:
+		return s.repetitionScore(pos), true
+	case board.DrawByInsufficientMaterial:
+		return eval.DrawScore, true
 	}
+	return 0, false
+}
 
-	for _, key := range history {
-		tracker.push(key)
+func newRepetitionTracker(pos *board.Position, history []uint64) *repetitionTracker {
+	tracker := &repetitionTracker{
+		stack: make([]uint64, 0, len(history)+1),
 	}
+	tracker.stack = append(tracker.stack, history...)
 
 	if len(tracker.stack) == 0 || tracker.stack[len(tracker.stack)-1] != pos.ZobristKey() {
 		tracker.push(pos.ZobristKey())
@@ -453,25 +536,10 @@ func newRepetitionTracker(pos *board.Position, history []uint64) *repetitionTrac
 
 func (t *repetitionTracker) push(key uint64) {
 	t.stack = append(t.stack, key)
-	t.counts[key]++
 }
 
 func (t *repetitionTracker) pop() {
-	lastIdx := len(t.stack) - 1
-	key := t.stack[lastIdx]
-	t.stack = t.stack[:lastIdx]
-	if t.counts[key] <= 1 {
-		delete(t.counts, key)
-		return
-	}
-	t.counts[key]--
-}
-
-func (t *repetitionTracker) isThreefold() bool {
-	if len(t.stack) == 0 {
-		return false
-	}
-	return t.counts[t.stack[len(t.stack)-1]] >= 3
+	t.stack = t.stack[:len(t.stack)-1]
 }
 
 func (s *AlphaBetaSearcher) orderMoves(pos *board.Position, moves []board.Move, ply int, ttMove board.Move) {
@@ -487,19 +555,28 @@ func (s *AlphaBetaSearcher) orderMoves(pos *board.Position, moves []board.Move,
 }
 
 func (s *AlphaBetaSearcher) scoreMove(pos *board.Position, move board.Move, ply int, ttMove board.Move) int {
-	if ttMove != (board.Move{}) && move == ttMove {
+	if ttMove != board.NoMove && move == ttMove {
 		return 1_000_000
 	}
 
+	score := tacticalScore(pos, move)
+	if isTacticalMove(pos, move) {
+		if see := movegen.SEE(pos, move); see < 0 {
+			score += see
+		}
+	}
+
+	return score + s.quietScore(move, ply)
+}
+
+// tacticalScore orders captures by MVV-LVA and promotions by the promoted
+// piece; it is zero for every other move.
+func tacticalScore(pos *board.Position, move board.Move) int {
 	score := 0
 	if isCaptureMove(pos, move) {
 		captured := capturedPiece(pos, move)
 		attacker := move.Piece().Type()
 		score += 100000 + 10*pieceOrderValue(captured.Type()) - pieceOrderValue(attacker)
-		see := s.seeLite(pos, move)
-		if see < 0 {
-			score += see
-		}
 	}
 
 	switch move.Flag() {
@@ -513,47 +590,18 @@ func (s *AlphaBetaSearcher) scoreMove(pos *board.Position, move board.Move, ply
 		score += 50000 + pieceOrderValue(board.Knight)
 	}
 
+	return score
+}
+
+func (s *AlphaBetaSearcher) quietScore(move board.Move, ply int) int {
+	score := 0
 	if move == s.killerMove(ply, 0) {
 		score += 40_000
 	} else if move == s.killerMove(ply, 1) {
 		score += 35_000
 	}
 
-	score += s.historyScore(move)
-
-	return score
-}
-
-func (s *AlphaBetaSearcher) seeLite(pos *board.Position, move board.Move) int {
-	if !isCaptureMove(pos, move) {
-		return 0
-	}
-
-	captured := capturedPiece(pos, move)
-	if captured == board.NoPiece {
-		return 0
-	}
-
-	history := s.positionUpdater.MakeMove(pos, move)
-	defer s.positionUpdater.UnMakeMove(pos, history)
-
-	enemyColor := board.White
-	if move.Piece().IsWhite() {
-		enemyColor = board.Black
-	}
-
-	recaptureValue := leastAttackerValueOnSquare(pos, enemyColor, move.EndIdx())
-	if recaptureValue == 0 {
-		return pieceOrderValue(captured.Type())
-	}
-
-	followupDefender := leastAttackerValueOnSquare(pos, move.Piece().Color(), move.EndIdx())
-	net := pieceOrderValue(captured.Type()) - pieceOrderValue(move.Piece().Type())
-	if followupDefender > 0 && followupDefender <= recaptureValue {
-		net += pieceOrderValue(move.Piece().Type()) / 4
-	}
-
-	return net
+	return score + s.historyScore(move)
 }
 
 func (s *AlphaBetaSearcher) recordKiller(ply int, move board.Move) {
@@ -574,7 +622,7 @@ func (s *AlphaBetaSearcher) recordHistory(move board.Move, depth int) {
 	if !move.Piece().IsWhite() {
 		colorIdx = 1
 	}
-	s.historyScores[colorIdx][move.StartIdx()][move.EndIdx()] += depth * depth
+	s.historyScores[colorIdx][move.StartEndIdx()] += depth * depth
 }
 
 func (s *AlphaBetaSearcher) historyScore(move board.Move) int {
@@ -582,7 +630,7 @@ func (s *AlphaBetaSearcher) historyScore(move board.Move) int {
 	if !move.Piece().IsWhite() {
 		colorIdx = 1
 	}
-	return s.historyScores[colorIdx][move.StartIdx()][move.EndIdx()]
+	return s.historyScores[colorIdx][move.StartEndIdx()]
 }
 
 func boundedPly(ply int) int {
@@ -612,6 +660,9 @@ func isCaptureMove(pos *board.Position, move board.Move) bool {
 	if move.Flag() == board.Capture || move.Flag() == board.EnPassant {
 		return true
 	}
+	if move.Flag() == board.Castle {
+		return false
+	}
 	return pos.PieceAt(move.EndIdx()) != board.NoPiece
 }
 
@@ -623,6 +674,9 @@ func capturedPiece(pos *board.Position, move board.Move) board.Piece {
 		}
 		return pos.PieceAt(end + 8)
 	}
+	if move.Flag() == board.Castle {
+		return board.NoPiece
+	}
 	return pos.PieceAt(move.EndIdx())
 }
 
@@ -645,33 +699,8 @@ func pieceOrderValue(pieceType int8) int {
 	}
 }
 
-func leastAttackerValueOnSquare(pos *board.Position, color, square int8) int {
-	squareMask := uint64(1) << square
-	best := 0
-
-	for idx := int8(0); idx < 64; idx++ {
-		piece := pos.PieceAt(idx)
-		if piece == board.NoPiece || piece.Color() != color {
-			continue
-		}
-		if idx == square {
-			continue
-		}
-		if movegen.PieceAttackMask(pos, piece, idx)&squareMask == 0 {
-			continue
-		}
-
-		value := pieceOrderValue(piece.Type())
-		if best == 0 || value < best {
-			best = value
-		}
-	}
-
-	return best
-}
-
 func (s *AlphaBetaSearcher) ensureBestMove(pos *board.Position, result Result) Result {
-	if result.BestMove != (board.Move{}) {
+	if result.BestMove != board.NoMove {
 		return result
 	}
 
@@ -684,6 +713,38 @@ func (s *AlphaBetaSearcher) ensureBestMove(pos *board.Position, result Result) R
 	moveCount := s.moveGenerator.LegalMovesInto(root, s.positionUpdater, moves[:])
 	if moveCount > 0 {
 		result.BestMove = moves[0]
+		result.PV = []board.Move{moves[0]}
 	}
 	return result
 }
+
+// updatePV sets the principal variation at ply to move followed by the line
+// found from the next ply. Lines are cut at searchMaxPly.
+func (s *AlphaBetaSearcher) updatePV(ply int, move board.Move) {
+	if ply >= searchMaxPly-1 {
+		return
+	}
+	s.pvTable[ply][0] = move
+	s.pvLength[ply] = 1 + copy(s.pvTable[ply][1:], s.pvTable[ply+1][:s.pvLength[ply+1]])
+}
+
+// rootPV returns a copy of the root principal variation, cut before the
+// first move that is not legal where it is played. The triangular table only
+// holds moves the search made, so the check is a safety net: a stale child
+// line must never reach a GUI.
+func (s *AlphaBetaSearcher) rootPV(pos *board.Position) []board.Move {
+	line := s.pvTable[0][:s.pvLength[0]]
+	pv := make([]board.Move, 0, len(line))
+	histories := make([]board.MoveHistory, 0, len(line))
+	for _, move := range line {
+		if !movegen.IsLegal(pos, move) {
+			break
+		}
+		pv = append(pv, move)
+		histories = append(histories, s.positionUpdater.MakeMove(pos, move))
+	}
+	for i := len(histories) - 1; i >= 0; i-- {
+		s.positionUpdater.UnMakeMove(pos, histories[i])
+	}
+	return pv
+}
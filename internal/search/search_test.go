@@ -4,6 +4,7 @@ import (
 	board "chessV2/internal/board"
 	"chessV2/internal/eval"
 	"chessV2/internal/movegen"
+	"fmt"
 	"testing"
 	"time"
 
@@ -87,7 +88,7 @@ func TestAlphaBetaSearcherSearchWithMoveTime(t *testing.T) {
 
 	result, err := searcher.Search(pos, Limits{MoveTime: 20 * time.Millisecond})
 	assert.NoError(t, err)
-	assert.NotEqual(t, board.Move{}, result.BestMove)
+	assert.NotEqual(t, board.NoMove, result.BestMove)
 	assert.GreaterOrEqual(t, result.Stats.Depth, 0)
 	assert.GreaterOrEqual(t, result.Stats.Time, time.Duration(0))
 }
@@ -106,7 +107,7 @@ func TestAlphaBetaSearcherSearchReturnsFallbackMoveWhenStoppedImmediately(t *tes
 
 	result, err := searcher.Search(pos, Limits{Depth: 3, Stop: stop})
 	assert.NoError(t, err)
-	assert.NotEqual(t, board.Move{}, result.BestMove)
+	assert.NotEqual(t, board.NoMove, result.BestMove)
 }
 
 func TestAlphaBetaSearcherSearchWithDepthAndMoveTime(t *testing.T) {
@@ -120,7 +121,7 @@ func TestAlphaBetaSearcherSearchWithDepthAndMoveTime(t *testing.T) {
 
 	result, err := searcher.Search(pos, Limits{Depth: 2, MoveTime: 50 * time.Millisecond})
 	assert.NoError(t, err)
-	assert.NotEqual(t, board.Move{}, result.BestMove)
+	assert.NotEqual(t, board.NoMove, result.BestMove)
 	assert.GreaterOrEqual(t, result.Stats.Depth, 0)
 	assert.LessOrEqual(t, result.Stats.Depth, 2)
 }
@@ -155,30 +156,90 @@ func TestAlphaBetaSearcherQuiescenceAvoidsPoisonedQueenCapture(t *testing.T) {
 	assert.Greater(t, result.Stats.QuiescenceNodes, uint64(0))
 }
 
-func TestSEELiteRejectsPoisonedQueenCapture(t *testing.T) {
+func TestAlphaBetaSearcherQuiescenceScoresMateInCheck(t *testing.T) {
 	searcher := NewAlphaBetaSearcher(
 		movegen.NewPseudoLegalMoveGenerator(),
 		board.NewPositionUpdater(),
 		eval.NewStaticEvaluator(),
 	)
-	pos, err := board.NewPositionFromFEN("3rk3/3p4/8/8/8/8/3Q4/4K3 w - - 0 1")
+	// White is up material, so standing pat would hide the back-rank mate.
+	pos, err := board.NewPositionFromFEN("6k1/5ppp/8/7N/7N/8/5PPP/r5K1 w - - 0 1")
 	assert.NoError(t, err)
 
-	move := board.NewMove(board.Piece(board.White|board.Queen), board.D2, board.D7, board.Capture)
-	assert.Less(t, searcher.seeLite(pos, move), 0)
+	var stats Stats
+	score, err := searcher.quiescence(pos, 0, 0, -eval.InfinityScore, eval.InfinityScore, &stats, time.Time{}, nil, newRepetitionTracker(pos, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, eval.MatedIn(0), score)
+}
+
+func TestAlphaBetaSearcherQuiescenceSearchesQuietEvasions(t *testing.T) {
+	searcher := NewAlphaBetaSearcher(
+		movegen.NewPseudoLegalMoveGenerator(),
+		board.NewPositionUpdater(),
+		eval.NewStaticEvaluator(),
+	)
+	// The knight forks king and queen; every evasion is a king move and the
+	// queen falls next, leaving a dead draw with king and knight against king.
+	// Without the quiet evasions the position would score as mate.
+	pos, err := board.NewPositionFromFEN("k7/8/8/8/8/5n2/3Q4/6K1 w - - 0 1")
+	assert.NoError(t, err)
+
+	var stats Stats
+	score, err := searcher.quiescence(pos, 0, 0, -eval.InfinityScore, eval.InfinityScore, &stats, time.Time{}, nil, newRepetitionTracker(pos, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, eval.DrawScore, score)
 }
 
-func TestSEELiteKeepsWinningQueenCapturePositive(t *testing.T) {
+func TestAlphaBetaSearcherQuiescenceFindsQuietCheckmate(t *testing.T) {
 	searcher := NewAlphaBetaSearcher(
 		movegen.NewPseudoLegalMoveGenerator(),
 		board.NewPositionUpdater(),
 		eval.NewStaticEvaluator(),
 	)
+	pos, err := board.NewPositionFromFEN("6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1")
+	assert.NoError(t, err)
+
+	var stats Stats
+	score, err := searcher.quiescence(pos, 0, 0, -eval.InfinityScore, eval.InfinityScore, &stats, time.Time{}, nil, newRepetitionTracker(pos, nil))
+	assert.NoError(t, err)
+	assert.Equal(t, eval.MateIn(1), score)
+
+	score, err = searcher.quiescence(pos, 0, quiescenceCheckPlies, -eval.InfinityScore, eval.InfinityScore, &stats, time.Time{}, nil, newRepetitionTracker(pos, nil))
+	assert.NoError(t, err)
+	assert.Less(t, score, eval.Score(29000))
+}
+
+func TestAlphaBetaSearcherFindsMateBehindCheckingCapture(t *testing.T) {
+	searcher := NewAlphaBetaSearcher(
+		movegen.NewPseudoLegalMoveGenerator(),
+		board.NewPositionUpdater(),
+		eval.NewStaticEvaluator(),
+	)
+	// Either capture on e8 checks, Black's only evasion is the recapture and
+	// the second white piece then mates, all inside quiescence.
+	pos, err := board.NewPositionFromFEN("3qr1k1/5ppp/8/8/8/8/4Q1PP/4R1K1 w - - 0 1")
+	assert.NoError(t, err)
+
+	result, err := searcher.Search(pos, Limits{Depth: 1})
+	assert.NoError(t, err)
+	assert.Contains(t, []string{"e1e8", "e2e8"}, result.BestMove.UCI())
+	assert.Equal(t, eval.MateIn(3), result.Score)
+}
+
+func TestSEERejectsPoisonedQueenCapture(t *testing.T) {
+	pos, err := board.NewPositionFromFEN("3rk3/3p4/8/8/8/8/3Q4/4K3 w - - 0 1")
+	assert.NoError(t, err)
+
+	move := board.NewMove(board.Piece(board.White|board.Queen), board.D2, board.D7, board.Capture)
+	assert.Less(t, movegen.SEE(pos, move), 0)
+}
+
+func TestSEEKeepsWinningQueenCapturePositive(t *testing.T) {
 	pos, err := board.NewPositionFromFEN("4k3/8/8/8/8/8/3r4/3QK3 w - - 0 1")
 	assert.NoError(t, err)
 
 	move := board.NewMove(board.Piece(board.White|board.Queen), board.D1, board.D2, board.Capture)
-	assert.Greater(t, searcher.seeLite(pos, move), 0)
+	assert.Greater(t, movegen.SEE(pos, move), 0)
 }
 
 func TestRepetitionTrackerDetectsThreefold(t *testing.T) {
@@ -200,13 +261,56 @@ func TestRepetitionTrackerDetectsThreefold(t *testing.T) {
 			}
 		}
 
-		assert.NotEqual(t, board.Move{}, selected)
+		assert.NotEqual(t, board.NoMove, selected)
 		updater.MakeMove(pos, selected)
 		history = append(history, pos.ZobristKey())
 	}
 
 	tracker := newRepetitionTracker(pos, history)
-	assert.True(t, tracker.isThreefold())
+	assert.Equal(t, board.DrawByRepetition, pos.DrawReason(tracker.stack))
+}
+
+func TestAlphaBetaSearcherScoresFiftyMoveRuleAsDraw(t *testing.T) {
+	searcher := NewAlphaBetaSearcher(
+		movegen.NewPseudoLegalMoveGenerator(),
+		board.NewPositionUpdater(),
+		eval.NewStaticEvaluator(),
+	)
+	pos, err := board.NewPositionFromFEN("4k3/8/8/8/8/8/4Q3/4K3 w - - 99 80")
+	assert.NoError(t, err)
+
+	result, err := searcher.Search(pos, Limits{Depth: 2})
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, result.Score, eval.Score(repetitionContemptMax))
+	assert.GreaterOrEqual(t, result.Score, eval.Score(-repetitionContemptMax))
+}
+
+func TestAlphaBetaSearcherScoresInsufficientMaterialAsDraw(t *testing.T) {
+	searcher := NewAlphaBetaSearcher(
+		movegen.NewPseudoLegalMoveGenerator(),
+		board.NewPositionUpdater(),
+		eval.NewStaticEvaluator(),
+	)
+	pos, err := board.NewPositionFromFEN("4k3/8/8/8/8/8/4B3/4K3 w - - 0 1")
+	assert.NoError(t, err)
+
+	result, err := searcher.Search(pos, Limits{Depth: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, eval.DrawScore, result.Score)
+}
+
+func TestAlphaBetaSearcherPrefersMateOverFiftyMoveRule(t *testing.T) {
+	searcher := NewAlphaBetaSearcher(
+		movegen.NewPseudoLegalMoveGenerator(),
+		board.NewPositionUpdater(),
+		eval.NewStaticEvaluator(),
+	)
+	pos, err := board.NewPositionFromFEN("7k/5KQ1/8/8/8/8/8/8 w - - 99 80")
+	assert.NoError(t, err)
+
+	result, err := searcher.Search(pos, Limits{Depth: 1})
+	assert.NoError(t, err)
+	assert.Greater(t, result.Score, eval.Score(29000))
 }
 
 func TestRepetitionScoreDiscouragesDrawWhenAhead(t *testing.T) {
@@ -239,7 +343,7 @@ func TestOrderMovesPrefersCaptures(t *testing.T) {
 		board.NewMove(board.Piece(board.White|board.Pawn), board.E4, board.D5, board.Capture),
 	}
 
-	searcher.orderMoves(pos, moves, 0, board.Move{})
+	searcher.orderMoves(pos, moves, 0, board.NoMove)
 	assert.Equal(t, "e4d5", moves[0].UCI())
 }
 
@@ -278,7 +382,7 @@ func TestOrderMovesPrefersKillerMove(t *testing.T) {
 		killer,
 	}
 
-	searcher.orderMoves(pos, moves, 3, board.Move{})
+	searcher.orderMoves(pos, moves, 3, board.NoMove)
 	assert.Equal(t, "g1f3", moves[0].UCI())
 }
 
@@ -299,7 +403,7 @@ func TestOrderMovesPrefersHistoryMove(t *testing.T) {
 		historyMove,
 	}
 
-	searcher.orderMoves(pos, moves, 2, board.Move{})
+	searcher.orderMoves(pos, moves, 2, board.NoMove)
 	assert.Equal(t, "g1f3", moves[0].UCI())
 }
 
@@ -323,7 +427,7 @@ func TestAlphaBetaSearcherSearchWithMoveTimeRegressionGame45NeverReturnsZeroMove
 		result, err := searcher.Search(searchPos, Limits{MoveTime: 250 * time.Millisecond})
 		assert.NoError(t, err)
 		assert.Equal(t, beforeFEN, searchPos.FEN(), "iteration %d mutated root position", i)
-		assert.NotEqual(t, board.Move{}, result.BestMove)
+		assert.NotEqual(t, board.NoMove, result.BestMove)
 		assert.NotEqual(t, "0000", result.BestMove.UCI())
 		_, ok := legal[result.BestMove.UCI()]
 		assert.True(t, ok, "iteration %d returned illegal move %s", i, result.BestMove.UCI())
@@ -339,3 +443,128 @@ func engineLegalMovesForTest(pos *board.Position) []board.Move {
 	copy(moves, buf[:count])
 	return moves
 }
+
+// assertLegalPV replays pv from a copy of pos and checks that it starts with
+// bestMove and that every move is legal where it is played.
+func assertLegalPV(t *testing.T, pos *board.Position, bestMove board.Move, pv []board.Move) {
+	t.Helper()
+	if assert.NotEmpty(t, pv) {
+		assert.Equal(t, bestMove, pv[0])
+	}
+
+	replay := pos.Clone()
+	updater := board.NewPositionUpdater()
+	for i, move := range pv {
+		if !assert.True(t, movegen.IsLegal(replay, move), "pv move %d %s is illegal in %s", i, move.UCI(), replay.FEN()) {
+			return
+		}
+		updater.MakeMove(replay, move)
+	}
+}
+
+func TestAlphaBetaSearcherPVIsLegal(t *testing.T) {
+	fens := map[string]string{
+		"start position": board.FenStartPos,
+		"kiwipete":       "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"promotions":     "n1n5/PPPk4/8/8/8/8/4Kppp/5N1N b - - 0 1",
+		"en passant":     "rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+		"regression":     "r1bqkbnr/1ppppppp/8/n7/8/P1N1PN2/P1PP1PPP/R1BQKBR1 w Qkq - 0 1",
+	}
+
+	for name, fen := range fens {
+		for depth := 1; depth <= 4; depth++ {
+			t.Run(fmt.Sprintf("%s depth %d", name, depth), func(t *testing.T) {
+				searcher := NewAlphaBetaSearcher(
+					movegen.NewPseudoLegalMoveGenerator(),
+					board.NewPositionUpdater(),
+					eval.NewStaticEvaluator(),
+				)
+				pos, err := board.NewPositionFromFEN(fen)
+				assert.NoError(t, err)
+				beforeFEN := pos.FEN()
+
+				result, err := searcher.Search(pos, Limits{Depth: depth})
+				assert.NoError(t, err)
+				assert.Equal(t, beforeFEN, pos.FEN())
+				assert.LessOrEqual(t, len(result.PV), searchMaxPly)
+				assertLegalPV(t, pos, result.BestMove, result.PV)
+			})
+		}
+	}
+}
+
+func TestAlphaBetaSearcherPVEndsWithMate(t *testing.T) {
+	searcher := NewAlphaBetaSearcher(
+		movegen.NewPseudoLegalMoveGenerator(),
+		board.NewPositionUpdater(),
+		eval.NewStaticEvaluator(),
+	)
+	pos, err := board.NewPositionFromFEN("k7/8/1K6/8/8/8/8/7R w - - 0 1")
+	assert.NoError(t, err)
+
+	result, err := searcher.Search(pos, Limits{Depth: 3})
+	assert.NoError(t, err)
+	assert.Greater(t, result.Score, eval.Score(29000))
+	assert.Equal(t, []string{"h1h8"}, movesUCI(result.PV))
+}
+
+func TestAlphaBetaSearcherReportsEveryIteration(t *testing.T) {
+	searcher := NewAlphaBetaSearcher(
+		movegen.NewPseudoLegalMoveGenerator(),
+		board.NewPositionUpdater(),
+		eval.NewStaticEvaluator(),
+	)
+	pos, err := board.NewPositionFromFEN(board.FenStartPos)
+	assert.NoError(t, err)
+
+	iterations := make([]Result, 0)
+	result, err := searcher.Search(pos, Limits{
+		Depth:    4,
+		MoveTime: time.Minute,
+		OnIteration: func(iteration Result) {
+			iterations = append(iterations, iteration)
+		},
+	})
+	assert.NoError(t, err)
+
+	if assert.Len(t, iterations, 4) {
+		for i, iteration := range iterations {
+			assert.Equal(t, i+1, iteration.Stats.Depth)
+			assertLegalPV(t, pos, iteration.BestMove, iteration.PV)
+		}
+		assert.Equal(t, iterations[3].PV, result.PV)
+	}
+}
+
+func TestAlphaBetaSearcherFallbackPVIsBestMove(t *testing.T) {
+	searcher := NewAlphaBetaSearcher(
+		movegen.NewPseudoLegalMoveGenerator(),
+		board.NewPositionUpdater(),
+		eval.NewStaticEvaluator(),
+	)
+	pos, err := board.NewPositionFromFEN(board.FenStartPos)
+	assert.NoError(t, err)
+
+	stop := make(chan struct{})
+	close(stop)
+
+	reported := 0
+	result, err := searcher.Search(pos, Limits{
+		MoveTime: time.Second,
+		Stop:     stop,
+		OnIteration: func(Result) {
+			reported++
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []board.Move{result.BestMove}, result.PV)
+	assert.Zero(t, reported, "interrupted iterations are not reported")
+}
+
+func movesUCI(moves []board.Move) []string {
+	ucis := make([]string, 0, len(moves))
+	for _, move := range moves {
+		ucis = append(ucis, move.UCI())
+	}
+	return ucis
+}
@@ -18,10 +18,10 @@ const (
 
 type ttEntry struct {
 	key      uint64
-	depth    int16
+	bestMove board.Move
 	score    eval.Score
+	depth    int16
 	bound    ttBound
-	bestMove board.Move
 }
 
 type searchTT struct {
@@ -52,7 +52,7 @@ func (tt *searchTT) probe(key uint64, depth int, ply int) (ttEntry, bool) {
 func (tt *searchTT) store(key uint64, depth int, ply int, score eval.Score, bound ttBound, bestMove board.Move) {
 	index := key & tt.mask
 	current := tt.entries[index]
-	if current.key == key && int(current.depth) > depth && bestMove == (board.Move{}) {
+	if current.key == key && int(current.depth) > depth && bestMove == board.NoMove {
 		return
 	}
 
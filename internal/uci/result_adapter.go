@@ -3,6 +3,7 @@ package uci
 import (
 	board "chessV2/internal/board"
 	"chessV2/internal/search"
+	"strings"
 	"time"
 )
 
@@ -12,6 +13,7 @@ type searchResultLike interface {
 	searchTime() time.Duration
 	searchScore() int32
 	bestMoveUCI() string
+	principalVariationUCI() string
 }
 
 type resultAdapter struct {
@@ -39,8 +41,21 @@ func (r resultAdapter) searchScore() int32 {
 }
 
 func (r resultAdapter) bestMoveUCI() string {
-	if r.result.BestMove == (board.Move{}) {
+	if r.result.BestMove == board.NoMove {
 		return ""
 	}
 	return r.result.BestMove.UCI()
 }
+
+// principalVariationUCI joins the moves of the principal variation, falling
+// back to the best move for results that carry no line.
+func (r resultAdapter) principalVariationUCI() string {
+	if len(r.result.PV) == 0 {
+		return r.bestMoveUCI()
+	}
+	moves := make([]string, 0, len(r.result.PV))
+	for _, move := range r.result.PV {
+		moves = append(moves, move.UCI())
+	}
+	return strings.Join(moves, " ")
+}
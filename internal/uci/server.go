@@ -3,10 +3,12 @@ package uci
 import (
 	"bufio"
 	board "chessV2/internal/board"
+	"chessV2/internal/book"
 	"chessV2/internal/engine"
 	"chessV2/internal/search"
 	"fmt"
 	"io"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -25,6 +27,9 @@ type Server struct {
 	position     *board.Position
 	positionKeys []uint64
 	activeSearch *activeSearch
+	chess960     bool
+	ownBook      bool
+	book         *book.Book
 }
 
 type activeSearch struct {
@@ -76,10 +81,18 @@ func (s *Server) handleCommand(line string, out io.Writer) (bool, error) {
 	case "uci":
 		fmt.Fprintf(out, "id name %s\n", engineName)
 		fmt.Fprintf(out, "id author %s\n", engineAuthor)
+		fmt.Fprintln(out, "option name UCI_Chess960 type check default false")
+		fmt.Fprintln(out, "option name OwnBook type check default false")
+		fmt.Fprintln(out, "option name BookFile type string default <empty>")
+		fmt.Fprintf(out, "option name BookDepth type spin default %d min 0 max 1000\n", engine.DefaultBookDepth)
+		fmt.Fprintln(out, "option name BookSelection type combo default weighted var weighted var best var uniform")
 		fmt.Fprintln(out, "uciok")
 	case "isready":
 		s.stopSearch(true)
 		fmt.Fprintln(out, "readyok")
+	case "setoption":
+		s.stopSearch(true)
+		return false, s.handleSetOption(fields[1:])
 	case "ucinewgame":
 		s.stopSearch(true)
 		s.engine.StartGame()
@@ -121,7 +134,7 @@ func (s *Server) handlePosition(args []string) error {
 			return fmt.Errorf("invalid fen position command")
 		}
 		fen := strings.Join(args[1:7], " ")
-		pos, err = board.NewPositionFromFEN(fen)
+		pos, err = board.NewValidPositionFromFEN(fen)
 		if err != nil {
 			return err
 		}
@@ -129,6 +142,7 @@ func (s *Server) handlePosition(args []string) error {
 	default:
 		return fmt.Errorf("unsupported position command")
 	}
+	s.applyVariant(pos)
 
 	if i < len(args) {
 		if args[i] != "moves" {
@@ -148,7 +162,97 @@ func (s *Server) handlePosition(args []string) error {
 	return nil
 }
 
+func (s *Server) handleSetOption(args []string) error {
+	if len(args) < 2 || args[0] != "name" {
+		return fmt.Errorf("invalid setoption command")
+	}
+
+	nameEnd := len(args)
+	for i := 1; i < len(args); i++ {
+		if args[i] == "value" {
+			nameEnd = i
+			break
+		}
+	}
+	name := strings.Join(args[1:nameEnd], " ")
+	value := ""
+	if nameEnd < len(args) {
+		value = strings.Join(args[nameEnd+1:], " ")
+	}
+
+	switch strings.ToLower(name) {
+	case "uci_chess960":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid UCI_Chess960 value: %s", value)
+		}
+		s.chess960 = enabled
+		s.applyVariant(s.position)
+		return nil
+	case "ownbook":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid OwnBook value: %s", value)
+		}
+		s.ownBook = enabled
+		s.applyBook()
+		return nil
+	case "bookfile":
+		s.book = nil
+		if value != "" && value != "<empty>" {
+			b, err := book.Load(value)
+			if err != nil {
+				s.applyBook()
+				return fmt.Errorf("cannot load book: %w", err)
+			}
+			s.book = b
+		}
+		s.applyBook()
+		return nil
+	case "bookdepth":
+		depth, err := strconv.Atoi(value)
+		if err != nil || depth < 0 {
+			return fmt.Errorf("invalid BookDepth value: %s", value)
+		}
+		s.engine.SetBookDepth(depth)
+		return nil
+	case "bookselection":
+		selection, err := book.ParseSelection(strings.ToLower(value))
+		if err != nil {
+			return err
+		}
+		s.engine.SetBookSelection(selection)
+		return nil
+	}
+
+	// GUIs send options such as Hash and Threads to every engine; the ones
+	// the server does not advertise are ignored.
+	return nil
+}
+
+// applyBook hands the loaded book to the engine while OwnBook is on.
+func (s *Server) applyBook() {
+	if s.ownBook && s.book != nil {
+		s.engine.SetBook(s.book)
+		return
+	}
+	s.engine.SetBook(nil)
+}
+
+// applyVariant switches pos to Chess960 castle encoding when UCI_Chess960 is
+// on. Positions whose castling rooks are off the standard squares are already
+// parsed as Chess960 and stay that way.
+func (s *Server) applyVariant(pos *board.Position) {
+	if s.chess960 {
+		pos.SetChess960(true)
+	}
+}
+
 func (s *Server) handleGo(args []string, out io.Writer) error {
+	if len(args) > 0 && args[0] == "perft" {
+		return s.handlePerft(args[1:], out)
+	}
+
 	snapshot, history := s.searchSnapshot()
 	limits, err := parseGoLimits(args, snapshot.ActiveColor())
 	if err != nil {
@@ -169,11 +273,43 @@ func (s *Server) handleGo(args []string, out io.Writer) error {
 	return nil
 }
 
+// handlePerft answers "go perft <depth>" with a divide in the layout
+// Stockfish uses, so perft tooling can drive either engine. It runs to
+// completion before the next command is read.
+func (s *Server) handlePerft(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing go perft depth")
+	}
+	depth, err := strconv.Atoi(args[0])
+	if err != nil || depth <= 0 {
+		return fmt.Errorf("invalid go perft depth")
+	}
+
+	s.stopSearch(true)
+	snapshot, _ := s.searchSnapshot()
+	divide, nodes := s.engine.PerftDivide(snapshot, depth)
+
+	moves := make([]string, 0, len(divide))
+	for move := range divide {
+		moves = append(moves, move)
+	}
+	sort.Strings(moves)
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	for _, move := range moves {
+		fmt.Fprintf(out, "%s: %d\n", move, divide[move])
+	}
+	fmt.Fprintf(out, "\nNodes searched: %d\n", nodes)
+	return nil
+}
+
 func (s *Server) resetToStartPos() error {
 	pos, err := board.NewPositionFromFEN(board.FenStartPos)
 	if err != nil {
 		return err
 	}
+	s.applyVariant(pos)
 	s.position = pos
 	s.positionKeys = []uint64{pos.ZobristKey()}
 	return nil
@@ -194,11 +330,18 @@ func (s *Server) runSearch(active *activeSearch, pos *board.Position, history []
 }, out io.Writer) {
 	defer close(active.done)
 
+	reported := false
 	result, err := s.engine.Search(pos, search.Limits{
 		Depth:    parsedLimits.Depth,
 		MoveTime: parsedLimits.MoveTime,
 		Stop:     active.stop,
 		History:  history,
+		OnIteration: func(iteration search.Result) {
+			reported = true
+			s.writeMu.Lock()
+			defer s.writeMu.Unlock()
+			writeInfo(out, adaptResult(iteration))
+		},
 	})
 
 	s.mu.Lock()
@@ -213,7 +356,7 @@ func (s *Server) runSearch(active *activeSearch, pos *board.Position, history []
 	}
 
 	result = s.ensureBestMove(pos, result)
-	s.writeResult(out, result)
+	s.writeResult(out, result, !reported)
 }
 
 func (s *Server) stopSearch(wait bool) {
@@ -234,13 +377,21 @@ func (s *Server) stopSearch(wait bool) {
 	}
 }
 
-func (s *Server) writeResult(out io.Writer, result search.Result) {
+// writeResult prints the bestmove line, preceded by an info line when no
+// completed iteration has been reported yet. Book moves were not searched,
+// so they get an info string instead of a depth 0 score.
+func (s *Server) writeResult(out io.Writer, result search.Result, withInfo bool) {
 	s.writeMu.Lock()
 	defer s.writeMu.Unlock()
 
-	writeInfo(out, adaptResult(result))
+	switch {
+	case result.Book:
+		fmt.Fprintln(out, "info string book move")
+	case withInfo:
+		writeInfo(out, adaptResult(result))
+	}
 	bestMove := "0000"
-	if result.BestMove != (board.Move{}) {
+	if result.BestMove != board.NoMove {
 		bestMove = result.BestMove.UCI()
 	}
 	if bestMove == "" {
@@ -404,11 +555,11 @@ func writeInfo(out io.Writer, result searchResultLike) {
 		if score < 0 {
 			matePly = -matePly
 		}
-		fmt.Fprintf(out, "info depth %d nodes %d time %d score mate %d pv %s\n", result.searchDepth(), result.searchNodes(), timeMs, matePly, result.bestMoveUCI())
+		fmt.Fprintf(out, "info depth %d nodes %d time %d score mate %d pv %s\n", result.searchDepth(), result.searchNodes(), timeMs, matePly, result.principalVariationUCI())
 		return
 	}
 
-	fmt.Fprintf(out, "info depth %d nodes %d time %d score cp %d pv %s\n", result.searchDepth(), result.searchNodes(), timeMs, score, result.bestMoveUCI())
+	fmt.Fprintf(out, "info depth %d nodes %d time %d score cp %d pv %s\n", result.searchDepth(), result.searchNodes(), timeMs, score, result.principalVariationUCI())
 }
 
 func sanitizeInfo(s string) string {
@@ -423,7 +574,7 @@ func absScore(v int32) int32 {
 }
 
 func (s *Server) ensureBestMove(pos *board.Position, result search.Result) search.Result {
-	if result.BestMove != (board.Move{}) {
+	if result.BestMove != board.NoMove {
 		return result
 	}
 
@@ -435,6 +586,7 @@ func (s *Server) ensureBestMove(pos *board.Position, result search.Result) searc
 	moves := s.engine.LegalMoves(root)
 	if len(moves) > 0 {
 		result.BestMove = moves[0]
+		result.PV = []board.Move{moves[0]}
 	}
 	return result
 }
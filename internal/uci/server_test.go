@@ -3,9 +3,14 @@ package uci
 import (
 	"bytes"
 	board "chessV2/internal/board"
+	"chessV2/internal/book"
 	"chessV2/internal/engine"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -54,6 +59,19 @@ func TestServerPositionFenAndMoveTime(t *testing.T) {
 	assert.Contains(t, out.String(), "bestmove ")
 }
 
+func TestServerRejectsInvalidFEN(t *testing.T) {
+	e := engine.NewEngine()
+	server, err := NewServer(e)
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	input := "position fen 4k3/8/8/8/8/8/8/3KR3 w - - 0 1\nquit\n"
+	err = server.Run(strings.NewReader(input), &out)
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "info string error invalid FEN: active color: side not to move is in check on e8")
+	assert.Equal(t, board.FenStartPos, server.position.FEN())
+}
+
 func TestServerStopCancelsRunningSearch(t *testing.T) {
 	e := engine.NewEngine()
 	server, err := NewServer(e)
@@ -98,6 +116,45 @@ func TestParseGoLimitsRejectsInvalidClockValue(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestServerChess960Option(t *testing.T) {
+	e := engine.NewEngine()
+	server, err := NewServer(e)
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	input := "uci\nsetoption name UCI_Chess960 value true\nposition fen 4k3/8/8/8/8/8/8/RK6 w A - 0 1 moves b1a1\nquit\n"
+	err = server.Run(strings.NewReader(input), &out)
+	assert.NoError(t, err)
+
+	assert.Contains(t, out.String(), "option name UCI_Chess960 type check default false")
+	assert.NotContains(t, out.String(), "error")
+	assert.Equal(t, "4k3/8/8/8/8/8/8/2KR4 b - - 1 1", server.position.FEN())
+}
+
+func TestServerIgnoresUnknownOptions(t *testing.T) {
+	server, err := NewServer(engine.NewEngine())
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	input := "setoption name Hash value 16\nsetoption name Threads value 4\nsetoption name Clear Hash\nsetoption name UCI_Chess960 value maybe\nquit\n"
+	assert.NoError(t, server.Run(strings.NewReader(input), &out))
+	assert.Equal(t, "info string error invalid UCI_Chess960 value: maybe\n", out.String())
+}
+
+func TestServerChess960OptionOnStandardSetup(t *testing.T) {
+	e := engine.NewEngine()
+	server, err := NewServer(e)
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	input := "setoption name UCI_Chess960 value true\nposition fen r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1 moves e1h1\nquit\n"
+	err = server.Run(strings.NewReader(input), &out)
+	assert.NoError(t, err)
+
+	assert.NotContains(t, out.String(), "error")
+	assert.Equal(t, "r3k2r/8/8/8/8/8/8/R4RK1 b ha - 1 1", server.position.FEN())
+}
+
 func TestEngineApplyUCIMoves(t *testing.T) {
 	e := engine.NewEngine()
 	pos, err := board.NewPositionFromFEN(board.FenStartPos)
@@ -181,3 +238,153 @@ func movesToSet(moves []board.Move) map[string]struct{} {
 	}
 	return set
 }
+
+func TestServerGoPerft(t *testing.T) {
+	e := engine.NewEngine()
+	server, err := NewServer(e)
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	input := "position startpos moves e2e4\ngo perft 2\nquit\n"
+	err = server.Run(strings.NewReader(input), &out)
+	assert.NoError(t, err)
+
+	output := out.String()
+	assert.Contains(t, output, "e7e5: 29\n")
+	assert.Contains(t, output, "d7d5: 31\n")
+	assert.True(t, strings.HasSuffix(output, "\nNodes searched: 600\n"), output)
+	assert.NotContains(t, output, "bestmove")
+}
+
+func TestServerOwnBookOptions(t *testing.T) {
+	pos, err := board.NewPositionFromFEN(board.FenStartPos)
+	assert.NoError(t, err)
+	e := engine.NewEngine()
+	move, err := e.FindMoveByUCI(pos, "a2a3")
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, book.Write(&buf, []book.Entry{{Key: pos.PolyglotKey(), Move: book.EncodeMove(pos, move), Weight: 1}}))
+	path := filepath.Join(t.TempDir(), "test.bin")
+	assert.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+
+	data := map[string]struct {
+		options  string
+		expected string
+	}{
+		"book on": {
+			options:  "setoption name OwnBook value true\n",
+			expected: "a2a3",
+		},
+		"book off": {
+			options: "setoption name OwnBook value false\n",
+		},
+		"book depth zero": {
+			options: "setoption name OwnBook value true\nsetoption name BookDepth value 0\n",
+		},
+	}
+
+	for name, d := range data {
+		t.Run(name, func(t *testing.T) {
+			server, err := NewServer(engine.NewEngine())
+			assert.NoError(t, err)
+
+			var out bytes.Buffer
+			input := fmt.Sprintf("uci\nsetoption name BookFile value %s\nsetoption name BookSelection value best\n%sposition startpos\ngo depth 1\nquit\n", path, d.options)
+			assert.NoError(t, server.Run(strings.NewReader(input), &out))
+
+			output := out.String()
+			assert.Contains(t, output, "option name OwnBook type check default false")
+			assert.NotContains(t, output, "error")
+			if d.expected != "" {
+				assert.Equal(t, d.expected, parseBestMove(output))
+				assert.Contains(t, output, "info string book move\n")
+				assert.NotContains(t, output, "info depth")
+				return
+			}
+			assert.NotContains(t, output, "book move")
+			assert.NotEqual(t, "a2a3", parseBestMove(output))
+		})
+	}
+}
+
+func TestServerRejectsMissingBookFile(t *testing.T) {
+	server, err := NewServer(engine.NewEngine())
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	input := "setoption name BookFile value " + filepath.Join(t.TempDir(), "missing.bin") + "\nquit\n"
+	assert.NoError(t, server.Run(strings.NewReader(input), &out))
+	assert.Contains(t, out.String(), "info string error cannot load book")
+}
+
+// lockedBuffer lets a test read what a running search has written so far.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestServerReportsLegalPVAfterEveryIteration(t *testing.T) {
+	const fen = "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"
+
+	e := engine.NewEngine()
+	server, err := NewServer(e)
+	assert.NoError(t, err)
+
+	in, input := io.Pipe()
+	out := &lockedBuffer{}
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Run(in, out)
+	}()
+
+	// quit stops a running search, so it is only sent once bestmove is out.
+	_, err = io.WriteString(input, "position fen "+fen+"\ngo depth 4 movetime 60000\n")
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return strings.Contains(out.String(), "bestmove ")
+	}, 30*time.Second, 10*time.Millisecond)
+	_, err = io.WriteString(input, "quit\n")
+	assert.NoError(t, err)
+	assert.NoError(t, input.Close())
+	assert.NoError(t, <-done)
+
+	depth := 0
+	for _, line := range strings.Split(out.String(), "\n") {
+		if !strings.HasPrefix(line, "info depth ") {
+			continue
+		}
+		depth++
+		assert.True(t, strings.HasPrefix(line, fmt.Sprintf("info depth %d ", depth)), line)
+
+		_, pv, found := strings.Cut(line, " pv ")
+		if !assert.True(t, found, line) {
+			continue
+		}
+		pos, err := board.NewPositionFromFEN(fen)
+		assert.NoError(t, err)
+		for _, uci := range strings.Fields(pv) {
+			move, err := e.FindMoveByUCI(pos, uci)
+			if !assert.NoError(t, err, line) {
+				break
+			}
+			e.ApplyMove(pos, move)
+		}
+		if depth == 4 {
+			assert.Equal(t, strings.Fields(pv)[0], parseBestMove(out.String()))
+		}
+	}
+	assert.Equal(t, 4, depth)
+}
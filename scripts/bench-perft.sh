@@ -12,6 +12,7 @@ depth="${BENCH_DEPTH:-6}"
 profile="${BENCH_PROFILE:-.codex-tmp/bench-perft.cpu.prof}"
 mode="${BENCH_MODE:-hot}"
 warmup="${BENCH_WARMUP:-1}"
+threads="${BENCH_THREADS:-1}"
 extra_args=()
 
 if [[ "${BENCH_NO_PERFT_TRICKS:-0}" == "1" ]]; then
@@ -26,5 +27,6 @@ GOCACHE="${GOCACHE:-$repo_root/.codex-tmp/go-build-cache}" \
   -depth "$depth" \
   -mode "$mode" \
   -warmup "$warmup" \
+  -threads "$threads" \
   -cpuprofile "$profile" \
   "${extra_args[@]}"